@@ -23,8 +23,61 @@ type EnvConfig interface {
 	GetSupabaseURL() string
 	GetSupabaseKey() string
 	GetSupabaseBucket() string
+	GetStorageDriver() string
+	GetStorageLocalRoot() string
+	GetStoragePublicURL() string
+	GetStorageSigningSecret() string
+	GetStorageSignedURLTTL() int
+	GetDirectUploadTTL() int
+	GetArchiveMaxMegabytes() int
+	GetArchiveMaxFiles() int
+	GetS3Endpoint() string
+	GetS3Region() string
+	GetS3Bucket() string
+	GetS3AccessKey() string
+	GetS3SecretKey() string
+	GetS3PublicURL() string
+	GetS3PathStyle() bool
 	GetXenditAPIKey() string
 	GetXenditCallbackToken() string
+	GetXenditInvoiceDuration() int
+	GetPaymentGateway() string
+	GetPaymentPublicURL() string
+	GetPaymentSimulatorDelay() int
+	GetPaymentSimulatorOutcome() string
+	GetPaymentReconcileInterval() int
+	GetPaymentReconcileAfter() int
+	GetSubscriptionRenewalInterval() int
+	GetSubscriptionRenewalLead() int
+	GetSubscriptionGracePeriod() int
+	GetSubscriptionRetryInterval() int
+	GetSubscriptionMaxAttempts() int
+	GetReceiptIssuer() string
+	GetReceiptEmail() bool
+	GetReceiptRenderInterval() int
+	GetUploadRulesFile() string
+	GetTusStagingDir() string
+	GetTusExpiration() int
+	GetTusCleanupInterval() int
+	GetImageMaxDimension() int
+	GetImageVariantSizes() string
+	GetImageJPEGQuality() int
+	GetScannerDriver() string
+	GetClamdAddress() string
+	GetClamdTimeout() int
+	GetScanInterval() int
+	GetIntegrityInterval() int
+	GetQuotaMaxMegabytes() int
+	GetQuotaMaxFiles() int
+	GetLedgerFeePercent() float64
+	GetLedgerFeeFixed() float64
+	GetOTPSecret() string
+	GetOTPMaxAttempts() int
+	GetSMTPHost() string
+	GetSMTPPort() string
+	GetSMTPUsername() string
+	GetSMTPPassword() string
+	GetSMTPSender() string
 }
 
 type envConfig struct {
@@ -103,6 +156,90 @@ func (e *envConfig) GetSupabaseBucket() string {
 	return strings.TrimSpace(utils.GetEnv("SUPABASE_BUCKET_NAME"))
 }
 
+func (e *envConfig) GetStorageDriver() string {
+	return strings.TrimSpace(utils.GetEnv("STORAGE_DRIVER"))
+}
+
+func (e *envConfig) GetStorageLocalRoot() string {
+	return strings.TrimSpace(utils.GetEnv("STORAGE_LOCAL_ROOT"))
+}
+
+func (e *envConfig) GetStoragePublicURL() string {
+	return strings.TrimSpace(utils.GetEnv("STORAGE_PUBLIC_URL"))
+}
+
+func (e *envConfig) GetStorageSigningSecret() string {
+	secret := strings.TrimSpace(utils.GetEnv("STORAGE_SIGNING_SECRET"))
+	if secret == "" {
+		return e.GetSalt()
+	}
+	return secret
+}
+
+func (e *envConfig) GetStorageSignedURLTTL() int {
+	ttl, err := strconv.Atoi(utils.GetEnv("STORAGE_SIGNED_URL_TTL"))
+	if err != nil || ttl <= 0 {
+		return 900 // Default value if parsing fails
+	}
+	return ttl
+}
+
+func (e *envConfig) GetDirectUploadTTL() int {
+	ttl, err := strconv.Atoi(utils.GetEnv("DIRECT_UPLOAD_TTL"))
+	if err != nil || ttl <= 0 {
+		return 60 // Default value if parsing fails
+	}
+	return ttl
+}
+
+func (e *envConfig) GetArchiveMaxMegabytes() int {
+	megabytes, err := strconv.Atoi(utils.GetEnv("ARCHIVE_MAX_MB"))
+	if err != nil || megabytes <= 0 {
+		return 1024 // Default value if parsing fails
+	}
+	return megabytes
+}
+
+func (e *envConfig) GetArchiveMaxFiles() int {
+	files, err := strconv.Atoi(utils.GetEnv("ARCHIVE_MAX_FILES"))
+	if err != nil || files <= 0 {
+		return 1000 // Default value if parsing fails
+	}
+	return files
+}
+
+func (e *envConfig) GetS3Endpoint() string {
+	return strings.TrimSpace(utils.GetEnv("S3_ENDPOINT"))
+}
+
+func (e *envConfig) GetS3Region() string {
+	return strings.TrimSpace(utils.GetEnv("S3_REGION"))
+}
+
+func (e *envConfig) GetS3Bucket() string {
+	return strings.TrimSpace(utils.GetEnv("S3_BUCKET"))
+}
+
+func (e *envConfig) GetS3AccessKey() string {
+	return strings.TrimSpace(utils.GetEnv("S3_ACCESS_KEY"))
+}
+
+func (e *envConfig) GetS3SecretKey() string {
+	return utils.GetEnv("S3_SECRET_KEY")
+}
+
+func (e *envConfig) GetS3PublicURL() string {
+	return strings.TrimSpace(utils.GetEnv("S3_PUBLIC_URL"))
+}
+
+func (e *envConfig) GetS3PathStyle() bool {
+	pathStyle, err := strconv.ParseBool(utils.GetEnv("S3_PATH_STYLE"))
+	if err != nil {
+		return true // Default value if parsing fails
+	}
+	return pathStyle
+}
+
 func (e *envConfig) GetXenditAPIKey() string {
 	return strings.TrimSpace(utils.GetEnv("XENDIT_API_KEY"))
 }
@@ -110,3 +247,255 @@ func (e *envConfig) GetXenditAPIKey() string {
 func (e *envConfig) GetXenditCallbackToken() string {
 	return strings.TrimSpace(utils.GetEnv("XENDIT_CALLBACK_TOKEN"))
 }
+
+func (e *envConfig) GetXenditInvoiceDuration() int {
+	duration, err := strconv.Atoi(utils.GetEnv("XENDIT_INVOICE_DURATION"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return duration
+}
+
+func (e *envConfig) GetPaymentGateway() string {
+	return strings.TrimSpace(utils.GetEnv("PAYMENT_GATEWAY"))
+}
+
+func (e *envConfig) GetPaymentPublicURL() string {
+	publicURL := strings.TrimSpace(utils.GetEnv("PAYMENT_PUBLIC_URL"))
+	if publicURL == "" {
+		return "http://localhost:" + e.GetHostPort()
+	}
+	return publicURL
+}
+
+func (e *envConfig) GetPaymentSimulatorDelay() int {
+	delay, err := strconv.Atoi(utils.GetEnv("PAYMENT_SIMULATOR_DELAY"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return delay
+}
+
+func (e *envConfig) GetPaymentSimulatorOutcome() string {
+	return strings.TrimSpace(utils.GetEnv("PAYMENT_SIMULATOR_OUTCOME"))
+}
+
+func (e *envConfig) GetPaymentReconcileInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("PAYMENT_RECONCILE_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetPaymentReconcileAfter() int {
+	after, err := strconv.Atoi(utils.GetEnv("PAYMENT_RECONCILE_AFTER"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return after
+}
+
+func (e *envConfig) GetSubscriptionRenewalInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("SUBSCRIPTION_RENEWAL_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetSubscriptionRenewalLead() int {
+	lead, err := strconv.Atoi(utils.GetEnv("SUBSCRIPTION_RENEWAL_LEAD"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return lead
+}
+
+func (e *envConfig) GetSubscriptionGracePeriod() int {
+	grace, err := strconv.Atoi(utils.GetEnv("SUBSCRIPTION_GRACE_PERIOD"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return grace
+}
+
+func (e *envConfig) GetSubscriptionRetryInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("SUBSCRIPTION_RETRY_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetSubscriptionMaxAttempts() int {
+	attempts, err := strconv.Atoi(utils.GetEnv("SUBSCRIPTION_MAX_ATTEMPTS"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return attempts
+}
+
+func (e *envConfig) GetReceiptIssuer() string {
+	return strings.TrimSpace(utils.GetEnv("RECEIPT_ISSUER"))
+}
+
+func (e *envConfig) GetReceiptEmail() bool {
+	enabled, err := strconv.ParseBool(utils.GetEnv("RECEIPT_EMAIL"))
+	if err != nil {
+		return false // Default value if parsing fails
+	}
+	return enabled
+}
+
+func (e *envConfig) GetReceiptRenderInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("RECEIPT_RENDER_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetUploadRulesFile() string {
+	return strings.TrimSpace(utils.GetEnv("UPLOAD_RULES_FILE"))
+}
+
+func (e *envConfig) GetTusStagingDir() string {
+	return strings.TrimSpace(utils.GetEnv("TUS_STAGING_DIR"))
+}
+
+func (e *envConfig) GetTusExpiration() int {
+	hours, err := strconv.Atoi(utils.GetEnv("TUS_EXPIRATION"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return hours
+}
+
+func (e *envConfig) GetTusCleanupInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("TUS_CLEANUP_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetImageMaxDimension() int {
+	dimension, err := strconv.Atoi(utils.GetEnv("IMAGE_MAX_DIMENSION"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return dimension
+}
+
+func (e *envConfig) GetImageVariantSizes() string {
+	return strings.TrimSpace(utils.GetEnv("IMAGE_VARIANT_SIZES"))
+}
+
+func (e *envConfig) GetImageJPEGQuality() int {
+	quality, err := strconv.Atoi(utils.GetEnv("IMAGE_JPEG_QUALITY"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return quality
+}
+
+func (e *envConfig) GetScannerDriver() string {
+	return strings.TrimSpace(utils.GetEnv("SCANNER_DRIVER"))
+}
+
+func (e *envConfig) GetClamdAddress() string {
+	return strings.TrimSpace(utils.GetEnv("CLAMD_ADDRESS"))
+}
+
+func (e *envConfig) GetClamdTimeout() int {
+	timeout, err := strconv.Atoi(utils.GetEnv("CLAMD_TIMEOUT"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return timeout
+}
+
+func (e *envConfig) GetScanInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("SCAN_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetIntegrityInterval() int {
+	interval, err := strconv.Atoi(utils.GetEnv("INTEGRITY_INTERVAL"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return interval
+}
+
+func (e *envConfig) GetQuotaMaxMegabytes() int {
+	megabytes, err := strconv.Atoi(utils.GetEnv("QUOTA_MAX_MB"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return megabytes
+}
+
+func (e *envConfig) GetQuotaMaxFiles() int {
+	files, err := strconv.Atoi(utils.GetEnv("QUOTA_MAX_FILES"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return files
+}
+
+func (e *envConfig) GetLedgerFeePercent() float64 {
+	percent, err := strconv.ParseFloat(utils.GetEnv("LEDGER_FEE_PERCENT"), 64)
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return percent
+}
+
+func (e *envConfig) GetLedgerFeeFixed() float64 {
+	fixed, err := strconv.ParseFloat(utils.GetEnv("LEDGER_FEE_FIXED"), 64)
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return fixed
+}
+
+func (e *envConfig) GetOTPSecret() string {
+	secret := strings.TrimSpace(utils.GetEnv("OTP_SECRET"))
+	if secret == "" {
+		return e.GetSalt()
+	}
+	return secret
+}
+
+func (e *envConfig) GetOTPMaxAttempts() int {
+	attempts, err := strconv.Atoi(utils.GetEnv("OTP_MAX_ATTEMPTS"))
+	if err != nil {
+		return 0 // Default value if parsing fails
+	}
+	return attempts
+}
+
+func (e *envConfig) GetSMTPHost() string {
+	return strings.TrimSpace(utils.GetEnv("SMTP_HOST"))
+}
+
+func (e *envConfig) GetSMTPPort() string {
+	return strings.TrimSpace(utils.GetEnv("SMTP_PORT"))
+}
+
+func (e *envConfig) GetSMTPUsername() string {
+	return strings.TrimSpace(utils.GetEnv("SMTP_USERNAME"))
+}
+
+func (e *envConfig) GetSMTPPassword() string {
+	return utils.GetEnv("SMTP_PASSWORD")
+}
+
+func (e *envConfig) GetSMTPSender() string {
+	return strings.TrimSpace(utils.GetEnv("SMTP_SENDER"))
+}
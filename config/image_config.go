@@ -0,0 +1,66 @@
+package config
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImageConfig shapes what the image pipeline stores: the largest side an image keeps,
+// the boxes its thumbnail variants fit in and the JPEG quality of both.
+type ImageConfig interface {
+	GetMaxDimension() int
+	GetVariantSizes() []int
+	GetJPEGQuality() int
+}
+
+type imageConfig struct {
+	maxDimension int
+	variantSizes []int
+	jpegQuality  int
+}
+
+// NewImageConfig parses the comma-separated variant sizes, stopping the boot on one that
+// is not a positive number.
+func NewImageConfig(maxDimension int, variantSizes string, jpegQuality int) ImageConfig {
+	if maxDimension <= 0 {
+		maxDimension = 2048
+	}
+	if jpegQuality < 1 || jpegQuality > 100 {
+		jpegQuality = 85
+	}
+	if strings.TrimSpace(variantSizes) == "" {
+		variantSizes = "64,256,1024"
+	}
+	var sizes []int
+	seen := map[int]bool{}
+	for _, raw := range strings.Split(variantSizes, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || size <= 0 {
+			log.Fatalf("IMAGE_VARIANT_SIZES has an invalid size %q", raw)
+		}
+		if !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Ints(sizes)
+	return &imageConfig{
+		maxDimension: maxDimension,
+		variantSizes: sizes,
+		jpegQuality:  jpegQuality,
+	}
+}
+
+func (c *imageConfig) GetMaxDimension() int {
+	return c.maxDimension
+}
+
+func (c *imageConfig) GetVariantSizes() []int {
+	return c.variantSizes
+}
+
+func (c *imageConfig) GetJPEGQuality() int {
+	return c.jpegQuality
+}
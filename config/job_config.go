@@ -0,0 +1,91 @@
+package config
+
+import "time"
+
+// JobConfig holds the schedules of the background jobs started with the application.
+type JobConfig interface {
+	GetReconcileInterval() time.Duration
+	GetReconcileAfter() time.Duration
+	GetRenewalInterval() time.Duration
+	GetReceiptInterval() time.Duration
+	GetTusCleanupInterval() time.Duration
+	GetScanInterval() time.Duration
+	GetIntegrityInterval() time.Duration
+}
+
+type jobConfig struct {
+	reconcileInterval time.Duration
+	reconcileAfter    time.Duration
+	renewalInterval   time.Duration
+	receiptInterval   time.Duration
+	tusCleanup        time.Duration
+	scanInterval      time.Duration
+	integrity         time.Duration
+}
+
+func NewJobConfig(reconcileIntervalMinutes int, reconcileAfterMinutes int, renewalIntervalMinutes int, receiptIntervalMinutes int, tusCleanupMinutes int, scanIntervalMinutes int, integrityIntervalMinutes int) JobConfig {
+	reconcileInterval := time.Duration(reconcileIntervalMinutes) * time.Minute
+	if reconcileInterval <= 0 {
+		reconcileInterval = 15 * time.Minute
+	}
+	reconcileAfter := time.Duration(reconcileAfterMinutes) * time.Minute
+	if reconcileAfter <= 0 {
+		reconcileAfter = 30 * time.Minute
+	}
+	renewalInterval := time.Duration(renewalIntervalMinutes) * time.Minute
+	if renewalInterval <= 0 {
+		renewalInterval = 15 * time.Minute
+	}
+	receiptInterval := time.Duration(receiptIntervalMinutes) * time.Minute
+	if receiptInterval <= 0 {
+		receiptInterval = time.Minute
+	}
+	tusCleanup := time.Duration(tusCleanupMinutes) * time.Minute
+	if tusCleanup <= 0 {
+		tusCleanup = time.Hour
+	}
+	scanInterval := time.Duration(scanIntervalMinutes) * time.Minute
+	if scanInterval <= 0 {
+		scanInterval = time.Minute
+	}
+	integrity := time.Duration(integrityIntervalMinutes) * time.Minute
+	if integrity <= 0 {
+		integrity = 24 * time.Hour
+	}
+	return &jobConfig{
+		reconcileInterval: reconcileInterval,
+		reconcileAfter:    reconcileAfter,
+		renewalInterval:   renewalInterval,
+		receiptInterval:   receiptInterval,
+		tusCleanup:        tusCleanup,
+		scanInterval:      scanInterval,
+		integrity:         integrity,
+	}
+}
+
+// GetReconcileInterval is how often pending payments are reconciled, every 15 minutes by default.
+func (c *jobConfig) GetReconcileInterval() time.Duration { return c.reconcileInterval }
+
+// GetReconcileAfter is how long a payment must have been pending before it is
+// reconciled, 30 minutes by default.
+func (c *jobConfig) GetReconcileAfter() time.Duration { return c.reconcileAfter }
+
+// GetRenewalInterval is how often subscriptions are checked for renewal, every 15 minutes
+// by default.
+func (c *jobConfig) GetRenewalInterval() time.Duration { return c.renewalInterval }
+
+// GetReceiptInterval is how often receipts still waiting for their PDF are rendered,
+// every minute by default.
+func (c *jobConfig) GetReceiptInterval() time.Duration { return c.receiptInterval }
+
+// GetTusCleanupInterval is how often expired resumable uploads and direct upload slots are
+// removed, every hour by default.
+func (c *jobConfig) GetTusCleanupInterval() time.Duration { return c.tusCleanup }
+
+// GetScanInterval is how often the scan worker looks for files still pending when no
+// upload woke it, every minute by default.
+func (c *jobConfig) GetScanInterval() time.Duration { return c.scanInterval }
+
+// GetIntegrityInterval is how often stored objects are hashed again to catch corruption,
+// every day by default; each object is checked at most once per interval.
+func (c *jobConfig) GetIntegrityInterval() time.Duration { return c.integrity }
@@ -0,0 +1,28 @@
+package config
+
+// LedgerConfig describes the gateway fee booked against every collected payment. The
+// gateway does not report its fee in callbacks, so it is estimated from the contracted
+// rate: percent of the amount plus a fixed part.
+type LedgerConfig interface {
+	GetFeePercent() float64
+	GetFeeFixed() float64
+}
+
+type ledgerConfig struct {
+	feePercent float64
+	feeFixed   float64
+}
+
+func NewLedgerConfig(feePercent float64, feeFixed float64) LedgerConfig {
+	if feePercent < 0 {
+		feePercent = 0
+	}
+	if feeFixed < 0 {
+		feeFixed = 0
+	}
+	return &ledgerConfig{feePercent: feePercent, feeFixed: feeFixed}
+}
+
+func (c *ledgerConfig) GetFeePercent() float64 { return c.feePercent }
+
+func (c *ledgerConfig) GetFeeFixed() float64 { return c.feeFixed }
@@ -0,0 +1,45 @@
+package config
+
+type MailConfig interface {
+	GetHost() string
+	GetPort() string
+	GetUsername() string
+	GetPassword() string
+	GetSender() string
+	IsConfigured() bool
+}
+
+type mailConfig struct {
+	host     string
+	port     string
+	username string
+	password string
+	sender   string
+}
+
+func NewMailConfig(host string, port string, username string, password string, sender string) MailConfig {
+	if port == "" {
+		port = "587"
+	}
+	if sender == "" {
+		sender = username
+	}
+	return &mailConfig{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		sender:   sender,
+	}
+}
+
+func (c *mailConfig) GetHost() string     { return c.host }
+func (c *mailConfig) GetPort() string     { return c.port }
+func (c *mailConfig) GetUsername() string { return c.username }
+func (c *mailConfig) GetPassword() string { return c.password }
+func (c *mailConfig) GetSender() string   { return c.sender }
+
+// IsConfigured reports whether enough SMTP settings are present to send real mail.
+func (c *mailConfig) IsConfigured() bool {
+	return c.host != "" && c.sender != ""
+}
@@ -0,0 +1,37 @@
+package config
+
+import "time"
+
+type OTPConfig interface {
+	GetSecretKey() string
+	GetDigits() int
+	GetTTL() time.Duration
+	GetMaxAttempts() int
+}
+
+type otpConfig struct {
+	secretKey   string
+	digits      int
+	ttl         time.Duration
+	maxAttempts int
+}
+
+func NewOTPConfig(secretKey string, ttlMinutes int, maxAttempts int) OTPConfig {
+	if ttlMinutes <= 0 {
+		ttlMinutes = 15
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	return &otpConfig{
+		secretKey:   secretKey,
+		digits:      6,
+		ttl:         time.Duration(ttlMinutes) * time.Minute,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (c *otpConfig) GetSecretKey() string  { return c.secretKey }
+func (c *otpConfig) GetDigits() int        { return c.digits }
+func (c *otpConfig) GetTTL() time.Duration { return c.ttl }
+func (c *otpConfig) GetMaxAttempts() int   { return c.maxAttempts }
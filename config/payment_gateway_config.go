@@ -0,0 +1,88 @@
+package config
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	PaymentGatewayXendit    = "xendit"
+	PaymentGatewaySimulator = "simulator"
+)
+
+type PaymentGatewayConfig interface {
+	GetProvider() string
+	GetPublicURL() string
+	GetCallbackURL() string
+	GetCallbackToken() string
+	GetInvoiceDuration() time.Duration
+	GetSimulatorDelay() time.Duration
+	GetSimulatorOutcome() string
+}
+
+type paymentGatewayConfig struct {
+	provider         string
+	publicURL        string
+	callbackToken    string
+	invoiceDuration  time.Duration
+	simulatorDelay   time.Duration
+	simulatorOutcome string
+}
+
+// NewPaymentGatewayConfig selects the payment provider. An unknown provider stops the
+// boot instead of silently falling back to a real gateway.
+func NewPaymentGatewayConfig(provider string, publicURL string, callbackToken string, invoiceDurationSeconds int, simulatorDelaySeconds int, simulatorOutcome string) PaymentGatewayConfig {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		provider = PaymentGatewayXendit
+	}
+	if provider != PaymentGatewayXendit && provider != PaymentGatewaySimulator {
+		log.Fatalf("Unknown PAYMENT_GATEWAY %q, expected %q or %q", provider, PaymentGatewayXendit, PaymentGatewaySimulator)
+	}
+
+	simulatorOutcome = strings.ToUpper(strings.TrimSpace(simulatorOutcome))
+	if simulatorOutcome != "" && simulatorOutcome != "PAID" && simulatorOutcome != "EXPIRED" {
+		log.Fatalf("Unknown PAYMENT_SIMULATOR_OUTCOME %q, expected PAID, EXPIRED or empty", simulatorOutcome)
+	}
+
+	invoiceDuration := time.Duration(invoiceDurationSeconds) * time.Second
+	if invoiceDuration <= 0 {
+		invoiceDuration = 24 * time.Hour
+	}
+	simulatorDelay := time.Duration(simulatorDelaySeconds) * time.Second
+	if simulatorDelay < 0 {
+		simulatorDelay = 0
+	}
+
+	return &paymentGatewayConfig{
+		provider:         provider,
+		publicURL:        strings.TrimRight(publicURL, "/"),
+		callbackToken:    callbackToken,
+		invoiceDuration:  invoiceDuration,
+		simulatorDelay:   simulatorDelay,
+		simulatorOutcome: simulatorOutcome,
+	}
+}
+
+func (c *paymentGatewayConfig) GetProvider() string { return c.provider }
+
+// GetPublicURL is the base URL this API is reachable on, used for simulator checkout links.
+func (c *paymentGatewayConfig) GetPublicURL() string { return c.publicURL }
+
+// GetCallbackURL is where the simulator delivers its callbacks.
+func (c *paymentGatewayConfig) GetCallbackURL() string {
+	return c.publicURL + "/api/v1/payment/callback"
+}
+
+func (c *paymentGatewayConfig) GetCallbackToken() string { return c.callbackToken }
+
+// GetInvoiceDuration is how long a created invoice stays payable, 24 hours by default.
+func (c *paymentGatewayConfig) GetInvoiceDuration() time.Duration { return c.invoiceDuration }
+
+// GetSimulatorDelay is how long the simulator waits before firing its automatic outcome.
+func (c *paymentGatewayConfig) GetSimulatorDelay() time.Duration { return c.simulatorDelay }
+
+// GetSimulatorOutcome is the status the simulator settles invoices with on its own
+// (PAID or EXPIRED). When empty, invoices wait for the checkout page.
+func (c *paymentGatewayConfig) GetSimulatorOutcome() string { return c.simulatorOutcome }
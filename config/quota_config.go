@@ -0,0 +1,31 @@
+package config
+
+import entity "abdanhafidz.com/go-boilerplate/models/entity"
+
+// QuotaConfig holds the storage limits of an account without an override for it or its
+// role: total bytes and number of files.
+type QuotaConfig interface {
+	GetMaxBytes() int64
+	GetMaxFiles() int64
+}
+
+type quotaConfig struct {
+	maxBytes int64
+	maxFiles int64
+}
+
+func NewQuotaConfig(maxMegabytes int, maxFiles int) QuotaConfig {
+	if maxMegabytes <= 0 {
+		maxMegabytes = 1024
+	}
+	if maxFiles <= 0 {
+		maxFiles = 1000
+	}
+	return &quotaConfig{maxBytes: int64(maxMegabytes) * entity.MB, maxFiles: int64(maxFiles)}
+}
+
+// GetMaxBytes is 1 GB by default.
+func (c *quotaConfig) GetMaxBytes() int64 { return c.maxBytes }
+
+// GetMaxFiles is 1000 by default.
+func (c *quotaConfig) GetMaxFiles() int64 { return c.maxFiles }
@@ -0,0 +1,25 @@
+package config
+
+// ReceiptConfig describes how payment receipts are issued.
+type ReceiptConfig interface {
+	GetIssuer() string
+	IsEmailEnabled() bool
+}
+
+type receiptConfig struct {
+	issuer       string
+	emailEnabled bool
+}
+
+func NewReceiptConfig(issuer string, emailEnabled bool) ReceiptConfig {
+	if issuer == "" {
+		issuer = "Go Boilerplate"
+	}
+	return &receiptConfig{issuer: issuer, emailEnabled: emailEnabled}
+}
+
+// GetIssuer is the name printed at the top of every receipt.
+func (c *receiptConfig) GetIssuer() string { return c.issuer }
+
+// IsEmailEnabled reports whether rendered receipts are emailed to the payer.
+func (c *receiptConfig) IsEmailEnabled() bool { return c.emailEnabled }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	ScannerDriverNone  = "none"
+	ScannerDriverClamd = "clamd"
+)
+
+// ScannerConfig selects the malware scanner uploads go through and how to reach clamd.
+type ScannerConfig interface {
+	GetDriver() string
+	GetClamdNetwork() string
+	GetClamdAddress() string
+	GetClamdTimeout() time.Duration
+}
+
+type scannerConfig struct {
+	driver       string
+	clamdNetwork string
+	clamdAddress string
+	clamdTimeout time.Duration
+}
+
+// NewScannerConfig picks the scanner. Without SCANNER_DRIVER it scans with clamd when
+// CLAMD_ADDRESS is set and not at all otherwise; an unknown driver stops the boot. The
+// address is host:port, tcp://host:port or unix:///path/to/clamd.sock.
+func NewScannerConfig(driver string, clamdAddress string, clamdTimeoutSeconds int) ScannerConfig {
+	driver = strings.ToLower(strings.TrimSpace(driver))
+	if driver == "" {
+		driver = ScannerDriverNone
+		if clamdAddress != "" {
+			driver = ScannerDriverClamd
+		}
+	}
+	if driver != ScannerDriverNone && driver != ScannerDriverClamd {
+		log.Fatalf("SCANNER_DRIVER %q is not one of none, clamd", driver)
+	}
+
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(clamdAddress, "unix://"):
+		network, clamdAddress = "unix", strings.TrimPrefix(clamdAddress, "unix://")
+	case strings.HasPrefix(clamdAddress, "tcp://"):
+		clamdAddress = strings.TrimPrefix(clamdAddress, "tcp://")
+	}
+	if driver == ScannerDriverClamd && clamdAddress == "" {
+		clamdAddress = "127.0.0.1:3310"
+	}
+
+	timeout := time.Duration(clamdTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	return &scannerConfig{
+		driver:       driver,
+		clamdNetwork: network,
+		clamdAddress: clamdAddress,
+		clamdTimeout: timeout,
+	}
+}
+
+func (c *scannerConfig) GetDriver() string {
+	return c.driver
+}
+
+func (c *scannerConfig) GetClamdNetwork() string {
+	return c.clamdNetwork
+}
+
+func (c *scannerConfig) GetClamdAddress() string {
+	return c.clamdAddress
+}
+
+func (c *scannerConfig) GetClamdTimeout() time.Duration {
+	return c.clamdTimeout
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+)
+
+const (
+	StorageDriverLocal    = "local"
+	StorageDriverS3       = "s3"
+	StorageDriverMemory   = "memory"
+	StorageDriverSupabase = "supabase"
+
+	// LocalStorageRoute is where the app serves files of the local backend.
+	LocalStorageRoute = "/storage"
+
+	// PrivateStoragePrefix starts the key of every private object, so a backend or a
+	// bucket policy can tell them apart.
+	PrivateStoragePrefix = "private/"
+)
+
+// StorageConfig selects the backend files are kept in and carries its settings. The
+// Supabase backend keeps using SupabaseConfig.
+type StorageConfig interface {
+	GetDriver() string
+	GetLocalRoot() string
+	GetPublicURL() string
+	GetSigningSecret() string
+	GetSignedURLTTL() time.Duration
+	GetDirectUploadTTL() time.Duration
+	GetArchiveMaxBytes() int64
+	GetArchiveMaxFiles() int
+	GetS3Endpoint() string
+	GetS3Region() string
+	GetS3Bucket() string
+	GetS3AccessKey() string
+	GetS3SecretKey() string
+	GetS3PublicURL() string
+	IsS3PathStyle() bool
+}
+
+type storageConfig struct {
+	envConfig EnvConfig
+	driver    string
+	localRoot string
+	publicURL string
+	s3Region  string
+}
+
+// NewStorageConfig picks the storage driver. Without STORAGE_DRIVER it keeps Supabase
+// when SUPABASE_URL is set and uses the local disk otherwise; an unknown driver stops the
+// boot.
+func NewStorageConfig(envConfig EnvConfig) StorageConfig {
+	driver := strings.ToLower(strings.TrimSpace(envConfig.GetStorageDriver()))
+	if driver == "" {
+		driver = StorageDriverLocal
+		if envConfig.GetSupabaseURL() != "" {
+			driver = StorageDriverSupabase
+		}
+	}
+	switch driver {
+	case StorageDriverLocal, StorageDriverS3, StorageDriverMemory, StorageDriverSupabase:
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER %q, expected %q, %q, %q or %q", driver, StorageDriverLocal, StorageDriverS3, StorageDriverMemory, StorageDriverSupabase)
+	}
+
+	localRoot := envConfig.GetStorageLocalRoot()
+	if localRoot == "" {
+		localRoot = "storage"
+	}
+	publicURL := envConfig.GetStoragePublicURL()
+	if publicURL == "" {
+		publicURL = envConfig.GetPaymentPublicURL()
+	}
+	s3Region := envConfig.GetS3Region()
+	if s3Region == "" {
+		s3Region = "us-east-1"
+	}
+
+	return &storageConfig{
+		envConfig: envConfig,
+		driver:    driver,
+		localRoot: localRoot,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		s3Region:  s3Region,
+	}
+}
+
+func (c *storageConfig) GetDriver() string { return c.driver }
+
+// GetLocalRoot is the directory the local backend writes to, "storage" by default.
+func (c *storageConfig) GetLocalRoot() string { return c.localRoot }
+
+// GetPublicURL is the base URL of this API, used to link files of the local backend.
+func (c *storageConfig) GetPublicURL() string { return c.publicURL }
+
+// GetSigningSecret is the HMAC key of the download links the local backend signs.
+func (c *storageConfig) GetSigningSecret() string { return c.envConfig.GetStorageSigningSecret() }
+
+// GetSignedURLTTL is how long a signed link to a private file stays valid.
+func (c *storageConfig) GetSignedURLTTL() time.Duration {
+	return time.Duration(c.envConfig.GetStorageSignedURLTTL()) * time.Second
+}
+
+// GetDirectUploadTTL is how long a client has to upload to storage directly and complete
+// the upload, 60 minutes by default.
+func (c *storageConfig) GetDirectUploadTTL() time.Duration {
+	return time.Duration(c.envConfig.GetDirectUploadTTL()) * time.Minute
+}
+
+// GetArchiveMaxBytes caps the total size of the files of one ZIP download, 1024 MB by
+// default; GetArchiveMaxFiles caps their number, 1000 by default.
+func (c *storageConfig) GetArchiveMaxBytes() int64 {
+	return int64(c.envConfig.GetArchiveMaxMegabytes()) * entity.MB
+}
+
+func (c *storageConfig) GetArchiveMaxFiles() int { return c.envConfig.GetArchiveMaxFiles() }
+
+func (c *storageConfig) GetS3Endpoint() string { return strings.TrimRight(c.envConfig.GetS3Endpoint(), "/") }
+
+func (c *storageConfig) GetS3Region() string { return c.s3Region }
+
+func (c *storageConfig) GetS3Bucket() string { return c.envConfig.GetS3Bucket() }
+
+func (c *storageConfig) GetS3AccessKey() string { return c.envConfig.GetS3AccessKey() }
+
+func (c *storageConfig) GetS3SecretKey() string { return c.envConfig.GetS3SecretKey() }
+
+// GetS3PublicURL is the base URL objects are linked with, such as a CDN. When empty,
+// objects are linked on the endpoint itself.
+func (c *storageConfig) GetS3PublicURL() string { return strings.TrimRight(c.envConfig.GetS3PublicURL(), "/") }
+
+// IsS3PathStyle addresses the bucket in the path instead of the host name, as MinIO
+// expects. It is the default.
+func (c *storageConfig) IsS3PathStyle() bool { return c.envConfig.GetS3PathStyle() }
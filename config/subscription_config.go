@@ -0,0 +1,57 @@
+package config
+
+import "time"
+
+// SubscriptionConfig holds the renewal policy of subscriptions.
+type SubscriptionConfig interface {
+	GetRenewalLead() time.Duration
+	GetGracePeriod() time.Duration
+	GetRetryInterval() time.Duration
+	GetMaxAttempts() int
+}
+
+type subscriptionConfig struct {
+	renewalLead   time.Duration
+	gracePeriod   time.Duration
+	retryInterval time.Duration
+	maxAttempts   int
+}
+
+func NewSubscriptionConfig(renewalLeadMinutes int, gracePeriodMinutes int, retryIntervalMinutes int, maxAttempts int) SubscriptionConfig {
+	renewalLead := time.Duration(renewalLeadMinutes) * time.Minute
+	if renewalLead <= 0 {
+		renewalLead = 3 * 24 * time.Hour
+	}
+	gracePeriod := time.Duration(gracePeriodMinutes) * time.Minute
+	if gracePeriod <= 0 {
+		gracePeriod = 3 * 24 * time.Hour
+	}
+	retryInterval := time.Duration(retryIntervalMinutes) * time.Minute
+	if retryInterval <= 0 {
+		retryInterval = 24 * time.Hour
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	return &subscriptionConfig{
+		renewalLead:   renewalLead,
+		gracePeriod:   gracePeriod,
+		retryInterval: retryInterval,
+		maxAttempts:   maxAttempts,
+	}
+}
+
+// GetRenewalLead is how long before the end of a period its renewal invoice is issued,
+// 3 days by default.
+func (c *subscriptionConfig) GetRenewalLead() time.Duration { return c.renewalLead }
+
+// GetGracePeriod is how long access continues after an unpaid period has ended before the
+// subscription is cancelled, 3 days by default.
+func (c *subscriptionConfig) GetGracePeriod() time.Duration { return c.gracePeriod }
+
+// GetRetryInterval is how long to wait before issuing a new renewal invoice after one
+// failed or expired, 1 day by default.
+func (c *subscriptionConfig) GetRetryInterval() time.Duration { return c.retryInterval }
+
+// GetMaxAttempts is how many renewal invoices are issued for one period, 3 by default.
+func (c *subscriptionConfig) GetMaxAttempts() int { return c.maxAttempts }
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TusConfig holds where resumable uploads are staged and how long they may take.
+type TusConfig interface {
+	GetStagingDir() string
+	GetExpiration() time.Duration
+}
+
+type tusConfig struct {
+	stagingDir string
+	expiration time.Duration
+}
+
+// NewTusConfig creates the staging directory, stopping the boot when it cannot. It
+// defaults to a directory under the system temp dir, outside what the app serves.
+func NewTusConfig(stagingDir string, expirationHours int) TusConfig {
+	if stagingDir == "" {
+		stagingDir = filepath.Join(os.TempDir(), "tus-uploads")
+	}
+	if err := os.MkdirAll(stagingDir, 0o700); err != nil {
+		log.Fatalf("TUS_STAGING_DIR %s cannot be created: %v", stagingDir, err)
+	}
+	expiration := time.Duration(expirationHours) * time.Hour
+	if expiration <= 0 {
+		expiration = 24 * time.Hour
+	}
+	return &tusConfig{stagingDir: stagingDir, expiration: expiration}
+}
+
+// GetStagingDir is the local directory chunks are written to until an upload completes.
+func (c *tusConfig) GetStagingDir() string { return c.stagingDir }
+
+// GetExpiration is how long an upload may stay unfinished, 24 hours by default.
+func (c *tusConfig) GetExpiration() time.Duration { return c.expiration }
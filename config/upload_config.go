@@ -1,46 +1,121 @@
 package config
 
 import (
-	models "abdanhafidz.com/go-boilerplate/models/entity"
-	http_error "abdanhafidz.com/go-boilerplate/models/error"
-)
+    "log"
+    "os"
+    "strings"
 
-type UploadRule struct {
-    MaxBytes    int64
-    AllowedExts map[string]bool
-    PathPrefix  string
-    MaxCount    int
-}
+    dto "abdanhafidz.com/go-boilerplate/models/dto"
+    models "abdanhafidz.com/go-boilerplate/models/entity"
+    "abdanhafidz.com/go-boilerplate/utils"
+    "github.com/goccy/go-yaml"
+)
 
+// UploadConfig holds the upload rules seeded at boot: those of UPLOAD_RULES_FILE when it
+// is set, the built-in ones otherwise. Seeding only creates contexts that do not exist
+// yet; from then on rules are managed through the admin API.
+//
+// It also holds the file types table: the types, as utils.DetectFileType names them,
+// the content of each extension may have. An extension missing from it is refused.
 type UploadConfig interface {
-    Get(contextType string) (UploadRule, error)
+    GetSeedRules() []dto.UploadRuleRequest
+    GetFileTypes() map[string][]string
 }
 
-type uploadConfig struct{}
+type uploadConfig struct {
+    seedRules []dto.UploadRuleRequest
+    fileTypes map[string][]string
+}
 
-func NewUploadConfig() UploadConfig { return &uploadConfig{} }
+// NewUploadConfig reads the YAML rules file: a list of rules under "rules" and extensions
+// mapped to their types under "file_types", each replacing the built-in entry of its
+// extension. A file with no rules keeps the built-in ones. The boot stops on a file that
+// cannot be read or holds neither.
+func NewUploadConfig(rulesFile string) UploadConfig {
+    c := &uploadConfig{seedRules: defaultUploadRules(), fileTypes: defaultFileTypes()}
+    if rulesFile == "" {
+        return c
+    }
+    content, err := os.ReadFile(rulesFile)
+    if err != nil {
+        log.Fatalf("UPLOAD_RULES_FILE cannot be read: %v", err)
+    }
+    var file struct {
+        Rules     []dto.UploadRuleRequest `yaml:"rules"`
+        FileTypes map[string][]string     `yaml:"file_types"`
+    }
+    if err := yaml.Unmarshal(content, &file); err != nil {
+        log.Fatalf("UPLOAD_RULES_FILE is not valid YAML: %v", err)
+    }
+    if len(file.Rules) == 0 && len(file.FileTypes) == 0 {
+        log.Fatalf("UPLOAD_RULES_FILE %s has neither rules nor file_types", rulesFile)
+    }
+    if len(file.Rules) > 0 {
+        c.seedRules = file.Rules
+    }
+    for ext, mimeTypes := range file.FileTypes {
+        ext = strings.ToLower(strings.TrimSpace(ext))
+        if !strings.HasPrefix(ext, ".") {
+            ext = "." + ext
+        }
+        var normalised []string
+        for _, mimeType := range mimeTypes {
+            normalised = append(normalised, strings.ToLower(strings.TrimSpace(mimeType)))
+        }
+        c.fileTypes[ext] = normalised
+    }
+    return c
+}
 
-func (c *uploadConfig) Get(contextType string) (UploadRule, error) {
-    codeExts := map[string]bool{".cpp": true, ".c": true, ".py": true, ".java": true, ".go": true, ".js": true, ".txt": true}
-    imgExts := map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true, ".gif": true}
-    docExts := map[string]bool{".pdf": true, ".doc": true, ".docx": true}
+func (c *uploadConfig) GetSeedRules() []dto.UploadRuleRequest {
+    return c.seedRules
+}
 
-    allExts := make(map[string]bool)
-    for k, v := range codeExts { allExts[k] = v }
-    for k, v := range imgExts { allExts[k] = v }
-    for k, v := range docExts { allExts[k] = v }
+func (c *uploadConfig) GetFileTypes() map[string][]string {
+    return c.fileTypes
+}
 
-    switch contextType {
-    case "image":
-        return UploadRule{ MaxBytes: 10 * models.MB, AllowedExts: imgExts, PathPrefix: "images", MaxCount: 5 }, nil
-    case "material":
-        return UploadRule{ MaxBytes: 10 * models.MB, AllowedExts: docExts, PathPrefix: "materials", MaxCount: 1 }, nil
-    case "submission":
-        return UploadRule{ MaxBytes: 1 * models.MB, AllowedExts: codeExts, PathPrefix: "submissions", MaxCount: 1 }, nil
-    case "general":
-        return UploadRule{ MaxBytes: 5 * models.MB, AllowedExts: allExts, PathPrefix: "temp", MaxCount: 5 }, nil
-    default:
-        return UploadRule{}, http_error.INVALID_UPLOAD_CONTEXT_ERROR
+// defaultFileTypes covers the extensions of the built-in rules. Source code is any UTF-8
+// text; a .doc is the OLE2 container every legacy Office file uses.
+func defaultFileTypes() map[string][]string {
+    text := []string{"text/plain"}
+    return map[string][]string{
+        ".jpg":  {"image/jpeg"},
+        ".jpeg": {"image/jpeg"},
+        ".png":  {"image/png"},
+        ".gif":  {"image/gif"},
+        ".webp": {"image/webp"},
+        ".pdf":  {"application/pdf"},
+        ".doc":  {utils.MimeOLE},
+        ".docx": {utils.MimeDocx},
+        ".txt":  text,
+        ".c":    text,
+        ".cpp":  text,
+        ".cs":   text,
+        ".go":   text,
+        ".java": text,
+        ".js":   text,
+        ".py":   text,
     }
 }
 
+// defaultUploadRules are the contexts the application started with. Receipts are only
+// written by the application itself, so only admins may upload to them.
+func defaultUploadRules() []dto.UploadRuleRequest {
+    codeExts := []string{".cpp", ".c", ".py", ".java", ".go", ".js", ".txt"}
+    imgExts := []string{".jpg", ".jpeg", ".png", ".webp", ".gif"}
+    docExts := []string{".pdf", ".doc", ".docx"}
+
+    var allExts []string
+    allExts = append(allExts, codeExts...)
+    allExts = append(allExts, imgExts...)
+    allExts = append(allExts, docExts...)
+
+    return []dto.UploadRuleRequest{
+        { Context: "image", MaxBytes: 10 * models.MB, AllowedExts: imgExts, PathTemplate: "images", MaxCount: 5, ProcessImages: true, Priority: 1 },
+        { Context: "submission", MaxBytes: 1 * models.MB, AllowedExts: codeExts, PathTemplate: "submissions/{year}/{month}", MaxCount: 1, Private: true, Priority: 2 },
+        { Context: "material", MaxBytes: 10 * models.MB, AllowedExts: docExts, PathTemplate: "materials", MaxCount: 1, Private: true, Priority: 3 },
+        { Context: "receipt", MaxBytes: 5 * models.MB, AllowedExts: []string{".pdf"}, PathTemplate: "receipts", MaxCount: 1, Private: true, Roles: []string{models.RoleAdmin} },
+        { Context: "general", MaxBytes: 5 * models.MB, AllowedExts: allExts, PathTemplate: "temp", MaxCount: 5 },
+    }
+}
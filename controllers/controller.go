@@ -1,6 +1,11 @@
 package controllers
 
 import (
+	"strconv"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 	"abdanhafidz.com/go-boilerplate/utils"
 	"github.com/gin-gonic/gin"
@@ -27,6 +32,73 @@ func ParseUUID(ctx *gin.Context, attrName string) uuid.UUID {
 	}
 	return uuidParsed
 }
+
+// ParseParamUUID parses a UUID path parameter, answering 400 when it is malformed.
+func ParseParamUUID(ctx *gin.Context, paramName string) (uuid.UUID, bool) {
+	raw := ctx.Param(paramName)
+	parsed, err := uuid.Parse(raw)
+	if err != nil {
+		ResponseJSON(ctx, gin.H{paramName: raw}, uuid.UUID{}, http_error.BAD_REQUEST_ERROR)
+		return uuid.UUID{}, false
+	}
+	return parsed, true
+}
+
+// ParsePagination reads limit, offset, search, sort_by and order from the query string.
+// Limit defaults to 20 and is capped at 100.
+func ParsePagination(ctx *gin.Context) entity.Pagination {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	pagination := entity.Pagination{
+		Limit:  limit,
+		Offset: offset,
+		Search: ctx.Query("search"),
+		SortBy: ctx.Query("sort_by"),
+		Order:  ctx.Query("order"),
+	}
+	if status := ctx.Query("status"); status != "" {
+		pagination.Status = &status
+	}
+	return pagination
+}
+
+// ParsePeriod reads the from and to query parameters as RFC 3339 times or plain dates,
+// answering 400 when either is malformed. A plain to date includes that whole day.
+func ParsePeriod(ctx *gin.Context) (dto.Period, bool) {
+	var period dto.Period
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &period.From}, {"to", &period.To}} {
+		raw := ctx.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			parsed, err = time.ParseInLocation(time.DateOnly, raw, time.Local)
+			if err != nil {
+				ResponseJSON(ctx, gin.H{bound.name: raw}, period, http_error.BAD_REQUEST_ERROR)
+				return dto.Period{}, false
+			}
+			if bound.name == "to" {
+				parsed = parsed.AddDate(0, 0, 1)
+			}
+		}
+		*bound.target = &parsed
+	}
+	return period, true
+}
+
 func RequestJSON[TRequest any](ctx *gin.Context) TRequest {
 	var request TRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DirectUploadController interface {
+	CreateSlot(ctx *gin.Context)
+	Complete(ctx *gin.Context)
+}
+
+type directUploadController struct {
+	directUploadService services.DirectUploadService
+}
+
+func NewDirectUploadController(directUploadService services.DirectUploadService) DirectUploadController {
+	return &directUploadController{directUploadService: directUploadService}
+}
+
+// Create Upload Slot godoc
+// @Summary      Create Upload Slot
+// @Description  Check a file against the rule of its context and the storage quota, then get a presigned URL to PUT exactly size bytes of it straight to storage. Only the s3 and supabase storage drivers support it
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.UploadSlotRequest  true  "Upload Slot Request"
+// @Success      200      {object}  dto.SuccessResponse[dto.UploadSlotResponse]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      403      {object}  dto.ErrorResponse
+// @Failure      413      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/direct [post]
+func (c *directUploadController) CreateSlot(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	req := RequestJSON[dto.UploadSlotRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.directUploadService.CreateSlot(ctx.Request.Context(), accountId, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Complete Upload Slot godoc
+// @Summary      Complete Upload Slot
+// @Description  Store the file uploaded to a slot once its size and content are checked. A file the rule refuses ends the slot; completing a slot again returns the same file
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Upload Slot ID"
+// @Success      200  {object}  dto.SuccessResponse[dto.FileResponse]
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      410  {object}  dto.ErrorResponse
+// @Failure      413  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/direct/{id}/complete [post]
+func (c *directUploadController) Complete(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	slotId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	file, err := c.directUploadService.Complete(ctx.Request.Context(), accountId, slotId)
+	var res dto.FileResponse
+	if err == nil {
+		res = toFileResponse(*file)
+	}
+	ResponseJSON(ctx, gin.H{"id": slotId}, res, err)
+}
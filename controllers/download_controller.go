@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"abdanhafidz.com/go-boilerplate/services"
+	"abdanhafidz.com/go-boilerplate/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DownloadController interface {
+	Content(ctx *gin.Context)
+	AdminContent(ctx *gin.Context)
+	AdminList(ctx *gin.Context)
+	Archive(ctx *gin.Context)
+	AdminArchive(ctx *gin.Context)
+}
+
+type downloadController struct {
+	downloadService services.DownloadService
+}
+
+func NewDownloadController(downloadService services.DownloadService) DownloadController {
+	return &downloadController{downloadService: downloadService}
+}
+
+// Download File godoc
+// @Summary      Download File
+// @Description  Stream the content of a file of the authenticated account from any storage driver. Supports Range and If-None-Match; the ETag is the SHA-256 of the content. Files not scanned clean cannot be downloaded
+// @Tags         Upload
+// @Produce      octet-stream
+// @Param        id      path      string  true   "File ID"
+// @Param        inline  query     bool    false  "Show the file in the browser instead of saving it"
+// @Param        Range   header    string  false  "Byte range, e.g. bytes=0-1023"
+// @Success      200     {file}    file
+// @Success      206     {file}    file
+// @Success      304     "Not Modified"
+// @Failure      403     {object}  dto.ErrorResponse
+// @Failure      404     {object}  dto.ErrorResponse
+// @Failure      416     "Range Not Satisfiable"
+// @Security     BearerAuth
+// @Router       /api/v1/files/{id}/content [get]
+func (c *downloadController) Content(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.content(ctx, &accountId)
+}
+
+// Admin Download File godoc
+// @Summary      Admin Download File
+// @Description  Stream the content of any file, like Download File (admin only)
+// @Tags         Admin
+// @Produce      octet-stream
+// @Param        id      path      string  true   "File ID"
+// @Param        inline  query     bool    false  "Show the file in the browser instead of saving it"
+// @Param        Range   header    string  false  "Byte range, e.g. bytes=0-1023"
+// @Success      200     {file}    file
+// @Success      206     {file}    file
+// @Success      304     "Not Modified"
+// @Failure      403     {object}  dto.ErrorResponse
+// @Failure      404     {object}  dto.ErrorResponse
+// @Failure      416     "Range Not Satisfiable"
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id}/content [get]
+func (c *downloadController) AdminContent(ctx *gin.Context) {
+	c.content(ctx, nil)
+}
+
+// List File Downloads godoc
+// @Summary      List File Downloads
+// @Description  Who downloaded a file through the API and when, newest first (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true   "File ID"
+// @Param        limit   query     int     false  "Page size (default 20)"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.FileDownload]
+// @Failure      404     {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id}/downloads [get]
+func (c *downloadController) AdminList(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	pagination := ParsePagination(ctx)
+	res, total, err := c.downloadService.List(ctx.Request.Context(), id, pagination)
+	ResponseJSON(ctx, gin.H{"id": id, "limit": pagination.Limit, "offset": pagination.Offset, "total": total}, res, err)
+}
+
+// Download Files As ZIP godoc
+// @Summary      Download Files As ZIP
+// @Description  Stream a ZIP of files of the authenticated account, selected either by file_ids or by context and upload time (from included, to excluded). Entries keep the file names, numbered when they collide. Listed files must all be found and scanned clean; a filter leaves out files not scanned clean. The files may hold at most ARCHIVE_MAX_FILES files and ARCHIVE_MAX_MB together
+// @Tags         Upload
+// @Accept       json
+// @Produce      application/zip
+// @Param        request  body      dto.FileArchiveRequest  true  "File Archive Request"
+// @Success      200      {file}    file
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      403      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      413      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/archive [post]
+func (c *downloadController) Archive(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.archive(ctx, &accountId)
+}
+
+// Admin Download Files As ZIP godoc
+// @Summary      Admin Download Files As ZIP
+// @Description  Stream a ZIP of files of any account, like Download Files As ZIP; account_id filters by owner (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      application/zip
+// @Param        request  body      dto.FileArchiveRequest  true  "File Archive Request"
+// @Success      200      {file}    file
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      403      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      413      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/archive [post]
+func (c *downloadController) AdminArchive(ctx *gin.Context) {
+	c.archive(ctx, nil)
+}
+
+func (c *downloadController) archive(ctx *gin.Context, accountId *uuid.UUID) {
+	downloader := ParseAccountId(ctx)
+	req := RequestJSON[dto.FileArchiveRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	name, files, err := c.downloadService.PrepareArchive(ctx.Request.Context(), accountId, req)
+	if err != nil {
+		ResponseJSON(ctx, req, "", err)
+		return
+	}
+
+	ctx.Header("Content-Type", utils.MimeZip)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Status(http.StatusOK)
+	download := entity.FileDownload{
+		AccountId: downloader,
+		Status:    http.StatusOK,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+	// The archive is streamed, so a failure halfway can only end the download early.
+	if err := c.downloadService.WriteArchive(ctx.Request.Context(), ctx.Writer, files, download); err != nil {
+		log.Printf("[DOWNLOAD] archive of %d files failed: %v", len(files), err)
+	}
+}
+
+// content serves a file with http.ServeContent, which answers Range, If-Range and the
+// conditional headers against the ETag set here. Only downloads that sent content are
+// recorded; a failed record is logged and does not fail the download.
+func (c *downloadController) content(ctx *gin.Context, accountId *uuid.UUID) {
+	downloader := ParseAccountId(ctx)
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	file, reader, err := c.downloadService.Open(ctx.Request.Context(), accountId, id)
+	if err != nil {
+		ResponseJSON(ctx, gin.H{"id": id}, "", err)
+		return
+	}
+	defer reader.Close()
+
+	name := file.DisplayName
+	if name == "" {
+		name = file.OriginalName
+	}
+	disposition := "attachment"
+	if inline, _ := strconv.ParseBool(ctx.Query("inline")); inline {
+		disposition = "inline"
+	}
+	etag := file.Sha256
+	if etag == "" {
+		etag = file.Id.String()
+	}
+	contentType := file.MimeType
+	if contentType == "" {
+		contentType = utils.MimeOctetStream
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Header("ETag", strconv.Quote(etag))
+	http.ServeContent(ctx.Writer, ctx.Request, name, file.CreatedAt, reader)
+
+	status := ctx.Writer.Status()
+	if status != http.StatusOK && status != http.StatusPartialContent {
+		return
+	}
+	download := entity.FileDownload{
+		FileId:    file.Id,
+		AccountId: downloader,
+		Status:    status,
+		Range:     ctx.GetHeader("Range"),
+		Bytes:     int64(ctx.Writer.Size()),
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+	if err := c.downloadService.Record(ctx.Request.Context(), download); err != nil {
+		log.Printf("[DOWNLOAD] recording download of %s failed: %v", file.Id, err)
+	}
+}
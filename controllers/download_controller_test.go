@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeFileRepo finds files from memory. Methods the tests do not need are left to the
+// embedded interface and panic when called.
+type fakeFileRepo struct {
+	repositories.FileRepository
+	files map[uuid.UUID]entity.File
+}
+
+func (r *fakeFileRepo) FindByID(ctx context.Context, id uuid.UUID) (*entity.File, error) {
+	file, ok := r.files[id]
+	if !ok {
+		return nil, http_error.NOT_FOUND_ERROR
+	}
+	return &file, nil
+}
+
+type fakeDownloadRepo struct {
+	repositories.FileDownloadRepository
+	mu        sync.Mutex
+	downloads []entity.FileDownload
+}
+
+func (r *fakeDownloadRepo) Create(ctx context.Context, download entity.FileDownload) (entity.FileDownload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downloads = append(r.downloads, download)
+	return download, nil
+}
+
+func TestDownloadContent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	owner := uuid.New()
+	storage := services.NewMemoryStorageService()
+	storage.UploadFile(ctx, strings.NewReader("0123456789abcdefghij"), "objects/clean.txt", "text/plain")
+	storage.UploadFile(ctx, strings.NewReader("pending"), "objects/pending.txt", "text/plain")
+
+	clean := entity.File{Id: uuid.New(), AccountId: owner, OriginalName: "clean.txt", MimeType: "text/plain", StorageKey: "objects/clean.txt", Size: 20, Sha256: "cafe", ScanStatus: entity.ScanStatusClean, CreatedAt: time.Now()}
+	pending := entity.File{Id: uuid.New(), AccountId: owner, OriginalName: "pending.txt", MimeType: "text/plain", StorageKey: "objects/pending.txt", Size: 7, ScanStatus: entity.ScanStatusPending, CreatedAt: time.Now()}
+	fileRepo := &fakeFileRepo{files: map[uuid.UUID]entity.File{clean.Id: clean, pending.Id: pending}}
+	downloadRepo := &fakeDownloadRepo{}
+	controller := NewDownloadController(services.NewDownloadService(nil, storage, fileRepo, downloadRepo))
+
+	router := gin.New()
+	router.GET("/files/:id/content", func(ctx *gin.Context) {
+		ctx.Set("account_id", owner.String())
+		controller.Content(ctx)
+	})
+	get := func(id uuid.UUID, header string, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/files/"+id.String()+"/content", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("range", func(t *testing.T) {
+		res := get(clean.Id, "Range", "bytes=0-9")
+		if res.Code != http.StatusPartialContent {
+			t.Fatalf("status = %d, want 206", res.Code)
+		}
+		if got := res.Header().Get("Content-Range"); got != "bytes 0-9/20" {
+			t.Errorf("Content-Range = %q, want bytes 0-9/20", got)
+		}
+		if res.Body.String() != "0123456789" {
+			t.Errorf("body = %q, want 0123456789", res.Body.String())
+		}
+		if got := res.Header().Get("ETag"); got != `"cafe"` {
+			t.Errorf("ETag = %q, want the quoted digest", got)
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		res := get(clean.Id, "If-None-Match", `"cafe"`)
+		if res.Code != http.StatusNotModified {
+			t.Fatalf("status = %d, want 304", res.Code)
+		}
+		if res.Body.Len() != 0 {
+			t.Errorf("body = %q, want none", res.Body.String())
+		}
+	})
+
+	t.Run("not scanned clean", func(t *testing.T) {
+		res := get(pending.Id, "", "")
+		if res.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403", res.Code)
+		}
+		if !strings.Contains(res.Body.String(), http_error.FILE_NOT_CLEAN.Error()) {
+			t.Errorf("body = %q, want the FILE_NOT_CLEAN error", res.Body.String())
+		}
+	})
+
+	downloadRepo.mu.Lock()
+	defer downloadRepo.mu.Unlock()
+	if len(downloadRepo.downloads) != 1 || downloadRepo.downloads[0].Status != http.StatusPartialContent || downloadRepo.downloads[0].Range != "bytes=0-9" {
+		t.Errorf("recorded downloads = %+v, want only the ranged one", downloadRepo.downloads)
+	}
+}
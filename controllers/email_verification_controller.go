@@ -1,9 +1,6 @@
 package controllers
 
 import (
-	"math/rand"
-	"time"
-
 	"abdanhafidz.com/go-boilerplate/models/dto"
 	"abdanhafidz.com/go-boilerplate/services"
 	"github.com/gin-gonic/gin"
@@ -25,19 +22,20 @@ func NewEmailVerificationController(emailVerificationService services.EmailVerif
 
 // Create Email Verification godoc
 // @Summary      Create Email Verification Token
-// @Description  Generate a verification token and send it to the specified email address
+// @Description  Generate a one-time verification code and send it to the specified email address
 // @Tags         Email Verification
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.CreateEmailVerificationRequest  true  "Create Email Verification Request"
-// @Success      200      {object}  dto.SuccessResponse[models.EmailVerification]
+// @Success      200      {object}  dto.SuccessResponse[dto.OTPIssuedResponse]
 // @Failure      400      {object}  dto.ErrorResponse
 // @Router       /api/v1/email/create-verification [post]
 func (c *emailVerificationController) Create(ctx *gin.Context) {
 	req := RequestJSON[dto.CreateEmailVerificationRequest](ctx)
-	token := uint(rand.Intn(900000) + 100000)
-	due := time.Now().Add(15 * time.Minute)
-	res, err := c.emailVerificationService.CreateToken(ctx.Request.Context(), req.Email, token, due)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.emailVerificationService.CreateToken(ctx.Request.Context(), req.Email)
 	ResponseJSON(ctx, req, res, err)
 }
 
@@ -53,13 +51,16 @@ func (c *emailVerificationController) Create(ctx *gin.Context) {
 // @Router       /api/v1/email/verify [post]
 func (c *emailVerificationController) Validate(ctx *gin.Context) {
 	req := RequestJSON[dto.ValidateVerifyEmailRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
 	err := c.emailVerificationService.VerifyToken(ctx.Request.Context(), req.Email, req.Token)
 	ResponseJSON[any](ctx, req, gin.H{"status": "ok"}, err)
 }
 
 // Delete Email Verification godoc
 // @Summary      Delete Email Verification Token
-// @Description  Delete the verification token after successful validation
+// @Description  Invalidate the active verification code of the specified email address
 // @Tags         Email Verification
 // @Accept       json
 // @Produce      json
@@ -69,6 +70,9 @@ func (c *emailVerificationController) Validate(ctx *gin.Context) {
 func (c *emailVerificationController) Delete(ctx *gin.Context) {
 
 	req := RequestJSON[dto.DeleteEmailVerificationRequest](ctx)
-	err := c.emailVerificationService.DeleteByToken(ctx.Request.Context(), req.Token)
+	if ctx.IsAborted() {
+		return
+	}
+	err := c.emailVerificationService.RevokeToken(ctx.Request.Context(), req.Email)
 	ResponseJSON[any](ctx, req, gin.H{"status": "ok"}, err)
 }
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type EntitlementController interface {
+	List(ctx *gin.Context)
+	Check(ctx *gin.Context)
+}
+
+type entitlementController struct {
+	entitlementService services.EntitlementService
+}
+
+func NewEntitlementController(entitlementService services.EntitlementService) EntitlementController {
+	return &entitlementController{entitlementService: entitlementService}
+}
+
+// List Entitlements godoc
+// @Summary      List Entitlements
+// @Description  List the entitlements of the authenticated account
+// @Tags         Product
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.Entitlement]
+// @Security     BearerAuth
+// @Router       /api/v1/entitlements [get]
+func (c *entitlementController) List(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	res, err := c.entitlementService.List(ctx.Request.Context(), accountId)
+	ResponseJSON(ctx, gin.H{"account_id": accountId}, res, err)
+}
+
+// Check Entitlement godoc
+// @Summary      Check Entitlement
+// @Description  Return the active entitlement to a product, or 402 with a ready checkout in meta_data
+// @Tags         Product
+// @Accept       json
+// @Produce      json
+// @Param        code  path      string  true  "Product Code"
+// @Success      200   {object}  dto.SuccessResponse[entity.Entitlement]
+// @Failure      402   {object}  dto.SuccessResponse[dto.CheckoutResponse]
+// @Failure      404   {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/entitlements/{code} [get]
+func (c *entitlementController) Check(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	res, checkout, err := c.entitlementService.Require(ctx.Request.Context(), accountId, ctx.Param("code"))
+	ResponseJSON(ctx, checkout, res, err)
+}
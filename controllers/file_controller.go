@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"strings"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FileController interface {
+	List(ctx *gin.Context)
+	ListTrash(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Trash(ctx *gin.Context)
+	Restore(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+	AdminList(ctx *gin.Context)
+	AdminGet(ctx *gin.Context)
+	AdminUpdate(ctx *gin.Context)
+	AdminTrash(ctx *gin.Context)
+	AdminRestore(ctx *gin.Context)
+	AdminDelete(ctx *gin.Context)
+}
+
+type fileController struct {
+	fileService services.FileService
+}
+
+func NewFileController(fileService services.FileService) FileController {
+	return &fileController{fileService: fileService}
+}
+
+// List My Files godoc
+// @Summary      List My Files
+// @Description  List the files of the authenticated account. Private files get a short-lived signed URL
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        context    query     string  false  "Upload context, e.g. image or submission"
+// @Param        mime_type  query     string  false  "MIME type such as image/png, or a type such as image"
+// @Param        tag        query     string  false  "Tag the file carries"
+// @Param        scan_status query     string  false  "PENDING, CLEAN or INFECTED"
+// @Param        search     query     string  false  "Original or display name contains"
+// @Param        sort_by    query     string  false  "created_at (default), size or original_name"
+// @Param        order      query     string  false  "asc or desc (default)"
+// @Param        limit      query     int     false  "Page size (default 20)"
+// @Param        offset     query     int     false  "Offset"
+// @Success      200        {object}  dto.SuccessResponse[[]entity.File]
+// @Security     BearerAuth
+// @Router       /api/v1/files [get]
+func (c *fileController) List(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.list(ctx, dto.FileFilter{AccountId: &accountId})
+}
+
+// List My Trash godoc
+// @Summary      List My Trash
+// @Description  List the trashed files of the authenticated account
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        context    query     string  false  "Upload context"
+// @Param        mime_type  query     string  false  "MIME type or type"
+// @Param        tag        query     string  false  "Tag the file carries"
+// @Param        scan_status query     string  false  "PENDING, CLEAN or INFECTED"
+// @Param        search     query     string  false  "Original or display name contains"
+// @Param        sort_by    query     string  false  "created_at (default), deleted_at, size or original_name"
+// @Param        order      query     string  false  "asc or desc (default)"
+// @Param        limit      query     int     false  "Page size (default 20)"
+// @Param        offset     query     int     false  "Offset"
+// @Success      200        {object}  dto.SuccessResponse[[]entity.File]
+// @Security     BearerAuth
+// @Router       /api/v1/files/trash [get]
+func (c *fileController) ListTrash(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.list(ctx, dto.FileFilter{AccountId: &accountId, Trashed: true})
+}
+
+// Update File godoc
+// @Summary      Update File
+// @Description  Change the display name or the tags of a file of the authenticated account
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                 true  "File ID"
+// @Param        request  body      dto.UpdateFileRequest  true  "Update File Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.File]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/{id} [patch]
+func (c *fileController) Update(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.update(ctx, &accountId)
+}
+
+// Trash File godoc
+// @Summary      Trash File
+// @Description  Move a file of the authenticated account to the trash
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.File]
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/{id} [delete]
+func (c *fileController) Trash(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.trash(ctx, &accountId)
+}
+
+// Restore File godoc
+// @Summary      Restore File
+// @Description  Bring a trashed file of the authenticated account back
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.File]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/{id}/restore [post]
+func (c *fileController) Restore(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.restore(ctx, &accountId)
+}
+
+// Delete File godoc
+// @Summary      Delete File
+// @Description  Delete a file of the authenticated account for good, removing it from storage
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[string]
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/files/{id}/permanent [delete]
+func (c *fileController) Delete(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	c.delete(ctx, &accountId)
+}
+
+// List Files godoc
+// @Summary      List Files
+// @Description  List the files of every account, or the trash with trashed=true (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        account_id  query     string  false  "Owner account ID"
+// @Param        trashed     query     bool    false  "List the trash instead"
+// @Param        corrupted   query     bool    false  "Only files whose stored content failed the integrity check"
+// @Param        context     query     string  false  "Upload context"
+// @Param        mime_type   query     string  false  "MIME type or type"
+// @Param        tag         query     string  false  "Tag the file carries"
+// @Param        scan_status query     string  false  "PENDING, CLEAN or INFECTED"
+// @Param        search      query     string  false  "Original or display name contains"
+// @Param        sort_by     query     string  false  "created_at (default), deleted_at, size or original_name"
+// @Param        order       query     string  false  "asc or desc (default)"
+// @Param        limit       query     int     false  "Page size (default 20)"
+// @Param        offset      query     int     false  "Offset"
+// @Success      200         {object}  dto.SuccessResponse[[]entity.File]
+// @Failure      400         {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files [get]
+func (c *fileController) AdminList(ctx *gin.Context) {
+	var accountId *uuid.UUID
+	if raw := ctx.Query("account_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			ResponseJSON(ctx, gin.H{"account_id": raw}, "", http_error.BAD_REQUEST_ERROR)
+			return
+		}
+		accountId = &id
+	}
+	c.list(ctx, dto.FileFilter{
+		AccountId: accountId,
+		Trashed:   ctx.Query("trashed") == "true",
+		Corrupted: ctx.Query("corrupted") == "true",
+	})
+}
+
+// Get File godoc
+// @Summary      Get File
+// @Description  Get any file, trashed or not (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.File]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id} [get]
+func (c *fileController) AdminGet(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.fileService.Get(ctx.Request.Context(), nil, id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+// Update Any File godoc
+// @Summary      Update Any File
+// @Description  Change the display name or the tags of any file (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                 true  "File ID"
+// @Param        request  body      dto.UpdateFileRequest  true  "Update File Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.File]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id} [patch]
+func (c *fileController) AdminUpdate(ctx *gin.Context) {
+	c.update(ctx, nil)
+}
+
+// Trash Any File godoc
+// @Summary      Trash Any File
+// @Description  Move any file to the trash (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.File]
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id} [delete]
+func (c *fileController) AdminTrash(ctx *gin.Context) {
+	c.trash(ctx, nil)
+}
+
+// Restore Any File godoc
+// @Summary      Restore Any File
+// @Description  Bring any trashed file back (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.File]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id}/restore [post]
+func (c *fileController) AdminRestore(ctx *gin.Context) {
+	c.restore(ctx, nil)
+}
+
+// Delete Any File godoc
+// @Summary      Delete Any File
+// @Description  Delete any file for good, removing it from storage (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  dto.SuccessResponse[string]
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/files/{id}/permanent [delete]
+func (c *fileController) AdminDelete(ctx *gin.Context) {
+	c.delete(ctx, nil)
+}
+
+// list completes the filter with the query parameters every listing accepts.
+func (c *fileController) list(ctx *gin.Context, filter dto.FileFilter) {
+	filter.Context = ctx.Query("context")
+	filter.MimeType = strings.ToLower(ctx.Query("mime_type"))
+	filter.Tag = strings.ToLower(strings.TrimSpace(ctx.Query("tag")))
+	filter.ScanStatus = strings.ToUpper(ctx.Query("scan_status"))
+	pagination := ParsePagination(ctx)
+	res, total, err := c.fileService.List(ctx.Request.Context(), filter, pagination)
+	ResponseJSON(ctx, gin.H{"filter": filter, "limit": pagination.Limit, "offset": pagination.Offset, "total": total}, res, err)
+}
+
+func (c *fileController) update(ctx *gin.Context, accountId *uuid.UUID) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	req := RequestJSON[dto.UpdateFileRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.fileService.UpdateMetadata(ctx.Request.Context(), accountId, id, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+func (c *fileController) trash(ctx *gin.Context, accountId *uuid.UUID) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.fileService.Trash(ctx.Request.Context(), accountId, id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+func (c *fileController) restore(ctx *gin.Context, accountId *uuid.UUID) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.fileService.Restore(ctx.Request.Context(), accountId, id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+func (c *fileController) delete(ctx *gin.Context, accountId *uuid.UUID) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	err := c.fileService.Delete(ctx.Request.Context(), accountId, id)
+	ResponseJSON(ctx, gin.H{"id": id}, id.String(), err)
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerController interface {
+	Balances(ctx *gin.Context)
+	Entries(ctx *gin.Context)
+	Check(ctx *gin.Context)
+	Export(ctx *gin.Context)
+}
+
+type ledgerController struct {
+	ledgerService services.LedgerService
+}
+
+func NewLedgerController(ledgerService services.LedgerService) LedgerController {
+	return &ledgerController{ledgerService: ledgerService}
+}
+
+// Ledger Balances godoc
+// @Summary      Ledger Balances
+// @Description  Debits, credits and balance of every ledger account per currency, optionally limited to a period (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        from  query     string  false  "Posted at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param        to    query     string  false  "Posted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success      200   {object}  dto.SuccessResponse[[]dto.LedgerBalance]
+// @Failure      400   {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/ledger/balances [get]
+func (c *ledgerController) Balances(ctx *gin.Context) {
+	period, ok := ParsePeriod(ctx)
+	if !ok {
+		return
+	}
+	res, err := c.ledgerService.Balances(ctx.Request.Context(), period)
+	ResponseJSON(ctx, period, res, err)
+}
+
+// Ledger Entries godoc
+// @Summary      Ledger Entries
+// @Description  Journal entries with their postings, newest first (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        limit   query     int     false  "Page size (default 20)"
+// @Param        offset  query     int     false  "Offset"
+// @Param        search  query     string  false  "Reference contains"
+// @Param        kind    query     string  false  "PAYMENT, FEE or REFUND"
+// @Param        from    query     string  false  "Posted at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param        to      query     string  false  "Posted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.JournalEntry]
+// @Failure      400     {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/ledger/entries [get]
+func (c *ledgerController) Entries(ctx *gin.Context) {
+	period, ok := ParsePeriod(ctx)
+	if !ok {
+		return
+	}
+	pagination := ParsePagination(ctx)
+	res, total, err := c.ledgerService.ListEntries(ctx.Request.Context(), pagination, period, ctx.Query("kind"))
+	ResponseJSON(ctx, gin.H{"limit": pagination.Limit, "offset": pagination.Offset, "total": total}, res, err)
+}
+
+// Check Ledger godoc
+// @Summary      Check Ledger
+// @Description  Verify that debits equal credits for every entry and per currency (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[dto.LedgerCheckReport]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/ledger/check [get]
+func (c *ledgerController) Check(ctx *gin.Context) {
+	res, err := c.ledgerService.Check(ctx.Request.Context())
+	ResponseJSON(ctx, gin.H{}, res, err)
+}
+
+// Export Ledger godoc
+// @Summary      Export Ledger
+// @Description  Download the postings of a period as CSV, one row per posting (admin only)
+// @Tags         Admin
+// @Produce      text/csv
+// @Param        from  query     string  false  "Posted at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param        to    query     string  false  "Posted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success      200   {file}    file
+// @Failure      400   {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/ledger/export [get]
+func (c *ledgerController) Export(ctx *gin.Context) {
+	period, ok := ParsePeriod(ctx)
+	if !ok {
+		return
+	}
+	filename := fmt.Sprintf("ledger-%s.csv", time.Now().Format("20060102-150405"))
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+	// Rows are streamed, so a failure halfway can only end the download early.
+	if err := c.ledgerService.ExportCSV(ctx.Request.Context(), period, ctx.Writer); err != nil {
+		log.Printf("[LEDGER] export failed: %v", err)
+	}
+}
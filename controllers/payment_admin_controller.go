@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var paymentStatuses = []string{
+	entity.PaymentStatusPending,
+	entity.PaymentStatusPaid,
+	entity.PaymentStatusFailed,
+	entity.PaymentStatusExpired,
+	entity.PaymentStatusPartiallyRefunded,
+	entity.PaymentStatusRefunded,
+}
+
+type PaymentAdminController interface {
+	List(ctx *gin.Context)
+	Get(ctx *gin.Context)
+	Export(ctx *gin.Context)
+}
+
+type paymentAdminController struct {
+	paymentAdminService services.PaymentAdminService
+}
+
+func NewPaymentAdminController(paymentAdminService services.PaymentAdminService) PaymentAdminController {
+	return &paymentAdminController{paymentAdminService: paymentAdminService}
+}
+
+// List Payments godoc
+// @Summary      List Payments
+// @Description  List payments with filters, sorting and pagination (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        status      query     string  false  "Comma-separated statuses, e.g. PAID,REFUNDED"
+// @Param        account_id  query     string  false  "Payer account ID"
+// @Param        product_id  query     string  false  "Product ID"
+// @Param        min_amount  query     number  false  "Minimum amount"
+// @Param        max_amount  query     number  false  "Maximum amount"
+// @Param        from        query     string  false  "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param        to          query     string  false  "Created before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Param        search      query     string  false  "External ID, invoice ID, description or payer email contains"
+// @Param        sort_by     query     string  false  "created_at (default), paid_at, amount or status"
+// @Param        order       query     string  false  "asc or desc (default)"
+// @Param        limit       query     int     false  "Page size (default 20)"
+// @Param        offset      query     int     false  "Offset"
+// @Success      200         {object}  dto.SuccessResponse[[]entity.Payment]
+// @Failure      400         {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment [get]
+func (c *paymentAdminController) List(ctx *gin.Context) {
+	filter, ok := parseAdminPaymentFilter(ctx)
+	if !ok {
+		return
+	}
+	pagination := ParsePagination(ctx)
+	res, total, err := c.paymentAdminService.List(ctx.Request.Context(), filter, pagination)
+	ResponseJSON(ctx, gin.H{"filter": filter, "limit": pagination.Limit, "offset": pagination.Offset, "total": total}, res, err)
+}
+
+// Get Payment Detail godoc
+// @Summary      Get Payment Detail
+// @Description  A payment with its payer, refunds, receipt and the callbacks the provider sent about it (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Payment ID"
+// @Success      200  {object}  dto.SuccessResponse[dto.AdminPaymentDetail]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/{id} [get]
+func (c *paymentAdminController) Get(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.paymentAdminService.Get(ctx.Request.Context(), id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+// Export Payments godoc
+// @Summary      Export Payments
+// @Description  Download every payment matching the list filters as CSV or XLSX. Rows are streamed as they are read (admin only)
+// @Tags         Admin
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format      query     string  false  "csv (default) or xlsx"
+// @Param        status      query     string  false  "Comma-separated statuses, e.g. PAID,REFUNDED"
+// @Param        account_id  query     string  false  "Payer account ID"
+// @Param        product_id  query     string  false  "Product ID"
+// @Param        min_amount  query     number  false  "Minimum amount"
+// @Param        max_amount  query     number  false  "Maximum amount"
+// @Param        from        query     string  false  "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param        to          query     string  false  "Created before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Param        search      query     string  false  "External ID, invoice ID, description or payer email contains"
+// @Param        sort_by     query     string  false  "created_at (default), paid_at, amount or status"
+// @Param        order       query     string  false  "asc or desc (default)"
+// @Success      200         {file}    file
+// @Failure      400         {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/export [get]
+func (c *paymentAdminController) Export(ctx *gin.Context) {
+	filter, ok := parseAdminPaymentFilter(ctx)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(ctx.DefaultQuery("format", services.PaymentExportCSV))
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case services.PaymentExportCSV:
+	case services.PaymentExportXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		ResponseJSON(ctx, gin.H{"format": format}, filter, http_error.BAD_REQUEST_ERROR)
+		return
+	}
+
+	filename := fmt.Sprintf("payments-%s.%s", time.Now().Format("20060102-150405"), format)
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+	// Rows are streamed, so a failure halfway can only end the download early.
+	if err := c.paymentAdminService.Export(ctx.Request.Context(), filter, ParsePagination(ctx), format, ctx.Writer); err != nil {
+		log.Printf("[PAYMENT] export failed: %v", err)
+	}
+}
+
+// parseAdminPaymentFilter reads the console filters from the query string, answering 400
+// when one is malformed.
+func parseAdminPaymentFilter(ctx *gin.Context) (dto.AdminPaymentFilter, bool) {
+	period, ok := ParsePeriod(ctx)
+	if !ok {
+		return dto.AdminPaymentFilter{}, false
+	}
+	filter := dto.AdminPaymentFilter{Period: period, Search: ctx.Query("search")}
+	fail := func(name string) (dto.AdminPaymentFilter, bool) {
+		ResponseJSON(ctx, gin.H{name: ctx.Query(name)}, filter, http_error.BAD_REQUEST_ERROR)
+		return dto.AdminPaymentFilter{}, false
+	}
+
+	if raw := ctx.Query("status"); raw != "" {
+		for _, status := range strings.Split(raw, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if !slices.Contains(paymentStatuses, status) {
+				return fail("status")
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	for _, param := range []struct {
+		name   string
+		target **uuid.UUID
+	}{{"account_id", &filter.AccountId}, {"product_id", &filter.ProductId}} {
+		if raw := ctx.Query(param.name); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return fail(param.name)
+			}
+			*param.target = &id
+		}
+	}
+	for _, param := range []struct {
+		name   string
+		target **float64
+	}{{"min_amount", &filter.MinAmount}, {"max_amount", &filter.MaxAmount}} {
+		if raw := ctx.Query(param.name); raw != "" {
+			amount, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return fail(param.name)
+			}
+			*param.target = &amount
+		}
+	}
+	return filter, true
+}
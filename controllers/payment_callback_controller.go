@@ -1,79 +1,82 @@
 package controllers
 
 import (
-	"log"
+	"io"
+	"net/http"
 
-	"abdanhafidz.com/go-boilerplate/models/dto"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 	"abdanhafidz.com/go-boilerplate/services"
 	"abdanhafidz.com/go-boilerplate/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// maxCallbackBodySize bounds how much of a webhook body is read before verification.
+const maxCallbackBodySize = 1 << 20
+
 type PaymentCallbackController interface {
 	HandleCallback(ctx *gin.Context)
+	Replay(ctx *gin.Context)
 }
 
 type paymentCallbackController struct {
-	paymentService services.PaymentService
+	paymentCallbackService services.PaymentCallbackService
 }
 
 func NewPaymentCallbackController(
-	paymentService services.PaymentService,
+	paymentCallbackService services.PaymentCallbackService,
 ) PaymentCallbackController {
 	return &paymentCallbackController{
-		paymentService: paymentService,
+		paymentCallbackService: paymentCallbackService,
 	}
 }
 
 // Handle Payment Callback godoc
 // @Summary      Handle Xendit Payment Callback
-// @Description  Receive and process payment status updates from Xendit
+// @Description  Verify, store and process an invoice, virtual account or e-wallet callback from Xendit. Redelivered events are acknowledged without being applied twice.
 // @Tags         Payment
 // @Accept       json
 // @Produce      json
-// @Param        request  body      map[string]interface{}  true  "Xendit Callback Payload"
-// @Success      200      {object}  dto.SuccessResponse[any]
-// @Failure      400      {object}  dto.ErrorResponse
+// @Param        x-callback-token  header    string                  true   "Xendit callback verification token"
+// @Param        webhook-id        header    string                  false  "Xendit webhook event id"
+// @Param        request           body      map[string]interface{}  true   "Xendit Callback Payload"
+// @Success      200               {object}  dto.SuccessResponse[entity.PaymentCallback]
+// @Failure      400               {object}  dto.ErrorResponse
+// @Failure      401               {object}  dto.ErrorResponse
+// @Failure      500               {object}  dto.ErrorResponse
 // @Router       /api/v1/payment/callback [post]
 func (c *paymentCallbackController) HandleCallback(ctx *gin.Context) {
-	// Xendit sends JSON payload
-	// Basic structure for Invoice Callback:
-	// { "id": "...", "external_id": "...", "status": "PAID", ... }
-	var callbackData map[string]interface{}
-	if err := ctx.ShouldBindJSON(&callbackData); err != nil {
-		utils.ResponseFAILED(ctx, gin.H(nil), http_error.BAD_REQUEST_ERROR)
+	if err := c.paymentCallbackService.VerifyToken(ctx.GetHeader("x-callback-token")); err != nil {
+		utils.ResponseFAILED(ctx, gin.H(nil), err)
 		return
 	}
 
-	log.Printf("Payment Callback Received: %+v", callbackData)
-
-	status, ok := callbackData["status"].(string)
-	if !ok {
-		// Not a status update or unknown format
-		var _ dto.SuccessResponse[any]
-
-		ResponseJSON(ctx, gin.H(nil), "Ignored: No status", nil)
+	payload, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxCallbackBodySize))
+	if err != nil {
+		utils.ResponseFAILED(ctx, gin.H(nil), http_error.BAD_REQUEST_ERROR)
 		return
 	}
 
-	invoiceId, _ := callbackData["id"].(string)
+	res, err := c.paymentCallbackService.Handle(ctx.Request.Context(), ctx.GetHeader("webhook-id"), payload)
+	ResponseJSON(ctx, gin.H{"event_id": res.EventId}, res, err)
+}
 
-	if status == "PAID" || status == "SETTLED" {
-		// Handle Event Payment
-		// We need a method in Service to handle "ConfirmPayment" by InvoiceID
-		// But existing services don't have it.
-		// Let's add it to PaymentService? Yes.
-		err := c.paymentService.ConfirmPayment(ctx.Request.Context(), invoiceId)
-		if err != nil {
-			log.Printf("Payment Confirmation Failed: %v", err)
-			// Don't return error to Xendit if logic failed, but maybe we should?
-			// Xendit expects 200 OK.
-		}
-	} else if status == "EXPIRED" {
-		c.paymentService.ExpirePayment(ctx.Request.Context(), invoiceId)
+// Replay Payment Callback godoc
+// @Summary      Replay Payment Callback
+// @Description  Process a stored Xendit callback again (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Payment Callback ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.PaymentCallback]
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/callbacks/{id}/replay [post]
+func (c *paymentCallbackController) Replay(ctx *gin.Context) {
+	callbackId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
 	}
-
-	// Always return 200 to Xendit
-	ResponseJSON(ctx, gin.H(nil), gin.H{"callback": "Callback Received"}, nil)
+	res, err := c.paymentCallbackService.Replay(ctx.Request.Context(), callbackId)
+	ResponseJSON(ctx, gin.H{"id": callbackId}, res, err)
 }
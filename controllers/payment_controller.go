@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/models/dto"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentController interface {
+	Create(ctx *gin.Context)
+	GetStatus(ctx *gin.Context)
+	History(ctx *gin.Context)
+}
+
+type paymentController struct {
+	paymentService services.PaymentService
+}
+
+func NewPaymentController(paymentService services.PaymentService) PaymentController {
+	return &paymentController{paymentService: paymentService}
+}
+
+// Create Payment godoc
+// @Summary      Create Payment
+// @Description  Create a payment for the authenticated account and issue its invoice
+// @Tags         Payment
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreatePaymentRequest  true  "Create Payment Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Payment]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/payment [post]
+func (c *paymentController) Create(ctx *gin.Context) {
+	req := RequestJSON[dto.CreatePaymentRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	accountId := ParseAccountId(ctx)
+	res, err := c.paymentService.CreatePayment(ctx.Request.Context(), accountId, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Get Payment Status godoc
+// @Summary      Get Payment Status
+// @Description  Retrieve a payment of the authenticated account together with its current status
+// @Tags         Payment
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Payment ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.Payment]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/payment/{id} [get]
+func (c *paymentController) GetStatus(ctx *gin.Context) {
+	paymentId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	accountId := ParseAccountId(ctx)
+	res, err := c.paymentService.GetPayment(ctx.Request.Context(), accountId, paymentId)
+	ResponseJSON(ctx, gin.H{"id": paymentId}, res, err)
+}
+
+// Payment History godoc
+// @Summary      Payment History
+// @Description  List the payments of the authenticated account, newest first, with their refunds
+// @Tags         Payment
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.Payment]
+// @Security     BearerAuth
+// @Router       /api/v1/payment [get]
+func (c *paymentController) History(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	res, err := c.paymentService.ListPayments(ctx.Request.Context(), accountId)
+	ResponseJSON(ctx, gin.H{"account_id": accountId}, res, err)
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentReconciliationController interface {
+	Run(ctx *gin.Context)
+	List(ctx *gin.Context)
+	Get(ctx *gin.Context)
+}
+
+type paymentReconciliationController struct {
+	reconciliationService services.PaymentReconciliationService
+}
+
+func NewPaymentReconciliationController(reconciliationService services.PaymentReconciliationService) PaymentReconciliationController {
+	return &paymentReconciliationController{reconciliationService: reconciliationService}
+}
+
+// Run Payment Reconciliation godoc
+// @Summary      Run Payment Reconciliation
+// @Description  Reconcile stale pending payments with the gateway now and return the report (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[entity.PaymentReconciliation]
+// @Failure      409  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/reconciliations [post]
+func (c *paymentReconciliationController) Run(ctx *gin.Context) {
+	res, err := c.reconciliationService.Run(ctx.Request.Context())
+	ResponseJSON(ctx, gin.H(nil), res, err)
+}
+
+// List Payment Reconciliations godoc
+// @Summary      List Payment Reconciliations
+// @Description  List reconciliation runs, newest first (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        limit   query     int  false  "Page size (default 20)"
+// @Param        offset  query     int  false  "Offset"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.PaymentReconciliation]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/reconciliations [get]
+func (c *paymentReconciliationController) List(ctx *gin.Context) {
+	pagination := ParsePagination(ctx)
+	res, err := c.reconciliationService.List(ctx.Request.Context(), pagination)
+	ResponseJSON(ctx, pagination, res, err)
+}
+
+// Get Payment Reconciliation godoc
+// @Summary      Get Payment Reconciliation
+// @Description  Reconciliation run with its discrepancy report (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Reconciliation ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.PaymentReconciliation]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/reconciliations/{id} [get]
+func (c *paymentReconciliationController) Get(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.reconciliationService.Get(ctx.Request.Context(), id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
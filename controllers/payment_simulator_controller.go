@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"abdanhafidz.com/go-boilerplate/models/dto"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+var simulatorCheckoutTemplate = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Payment Simulator</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 48px auto;">
+<h2>Payment Simulator</h2>
+<p>Invoice <code>{{.Id}}</code><br>Reference <code>{{.ExternalId}}</code></p>
+<p><strong>{{.Currency}} {{printf "%.2f" .Amount}}</strong></p>
+<p>Status: <strong>{{.Status}}</strong></p>
+{{if eq .Status "PENDING"}}
+<form method="post">
+<button name="outcome" value="PAID">Pay</button>
+<button name="outcome" value="EXPIRED">Let it expire</button>
+</form>
+{{end}}
+</body>
+</html>`))
+
+type PaymentSimulatorController interface {
+	Checkout(ctx *gin.Context)
+	Settle(ctx *gin.Context)
+}
+
+type paymentSimulatorController struct {
+	paymentGateway services.PaymentGateway
+}
+
+func NewPaymentSimulatorController(paymentGateway services.PaymentGateway) PaymentSimulatorController {
+	return &paymentSimulatorController{paymentGateway: paymentGateway}
+}
+
+// simulator answers 404 unless the simulator is the configured gateway.
+func (c *paymentSimulatorController) simulator(ctx *gin.Context) (services.PaymentSimulator, bool) {
+	simulator, ok := c.paymentGateway.(services.PaymentSimulator)
+	if !ok {
+		ResponseJSON(ctx, gin.H{"gateway": c.paymentGateway.Name()}, gin.H(nil), http_error.NOT_FOUND_ERROR)
+		return nil, false
+	}
+	return simulator, true
+}
+
+// Simulator Checkout godoc
+// @Summary      Simulator Checkout Page
+// @Description  Hosted checkout page of the local payment simulator
+// @Tags         Payment
+// @Produce      html
+// @Param        id   path      string  true  "Simulated Invoice ID"
+// @Success      200  {string}  string  "Checkout page"
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/v1/payment/simulator/{id} [get]
+func (c *paymentSimulatorController) Checkout(ctx *gin.Context) {
+	simulator, ok := c.simulator(ctx)
+	if !ok {
+		return
+	}
+	inv, err := simulator.GetInvoice(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ResponseJSON(ctx, gin.H{"id": ctx.Param("id")}, gin.H(nil), err)
+		return
+	}
+
+	var page bytes.Buffer
+	if err := simulatorCheckoutTemplate.Execute(&page, inv); err != nil {
+		ResponseJSON(ctx, gin.H{"id": inv.Id}, gin.H(nil), http_error.INTERNAL_SERVER_ERROR)
+		return
+	}
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// Simulator Settle godoc
+// @Summary      Settle Simulated Invoice
+// @Description  Pay or expire a simulated invoice; the simulator then delivers the payment callback
+// @Tags         Payment
+// @Accept       x-www-form-urlencoded
+// @Produce      html
+// @Param        id       path      string  true  "Simulated Invoice ID"
+// @Param        outcome  formData  string  true  "PAID or EXPIRED"
+// @Success      303      {string}  string  "Redirect to the checkout page"
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Router       /api/v1/payment/simulator/{id} [post]
+func (c *paymentSimulatorController) Settle(ctx *gin.Context) {
+	simulator, ok := c.simulator(ctx)
+	if !ok {
+		return
+	}
+	req := RequestForm[dto.SimulatorCheckoutRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+
+	inv, err := simulator.Settle(ctx.Request.Context(), ctx.Param("id"), req.Outcome)
+	if err != nil {
+		ResponseJSON(ctx, req, inv, err)
+		return
+	}
+	ctx.Redirect(http.StatusSeeOther, inv.URL)
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/models/dto"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ProductController interface {
+	List(ctx *gin.Context)
+	Get(ctx *gin.Context)
+	Checkout(ctx *gin.Context)
+	AdminList(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	CreatePrice(ctx *gin.Context)
+	UpdatePrice(ctx *gin.Context)
+}
+
+type productController struct {
+	productService  services.ProductService
+	checkoutService services.CheckoutService
+}
+
+func NewProductController(productService services.ProductService, checkoutService services.CheckoutService) ProductController {
+	return &productController{
+		productService:  productService,
+		checkoutService: checkoutService,
+	}
+}
+
+// List Products godoc
+// @Summary      List Products
+// @Description  List active products with their active prices
+// @Tags         Product
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.Product]
+// @Router       /api/v1/products [get]
+func (c *productController) List(ctx *gin.Context) {
+	res, err := c.productService.List(ctx.Request.Context(), true)
+	ResponseJSON(ctx, gin.H(nil), res, err)
+}
+
+// Get Product godoc
+// @Summary      Get Product
+// @Description  Get an active product by its code
+// @Tags         Product
+// @Accept       json
+// @Produce      json
+// @Param        code  path      string  true  "Product Code"
+// @Success      200   {object}  dto.SuccessResponse[entity.Product]
+// @Failure      404   {object}  dto.ErrorResponse
+// @Router       /api/v1/products/{code} [get]
+func (c *productController) Get(ctx *gin.Context) {
+	code := ctx.Param("code")
+	res, err := c.productService.GetByCode(ctx.Request.Context(), code)
+	ResponseJSON(ctx, gin.H{"code": code}, res, err)
+}
+
+// Checkout Product godoc
+// @Summary      Checkout Product
+// @Description  Issue (or reuse) an invoice for a product price of the authenticated account
+// @Tags         Product
+// @Accept       json
+// @Produce      json
+// @Param        code     path      string               true   "Product Code"
+// @Param        request  body      dto.CheckoutRequest  false  "Checkout Request"
+// @Success      200      {object}  dto.SuccessResponse[dto.CheckoutResponse]
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      502      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/products/{code}/checkout [post]
+func (c *productController) Checkout(ctx *gin.Context) {
+	var req dto.CheckoutRequest
+	_ = ctx.ShouldBindJSON(&req)
+	code := ctx.Param("code")
+	accountId := ParseAccountId(ctx)
+	res, err := c.checkoutService.Checkout(ctx.Request.Context(), accountId, code, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Admin List Products godoc
+// @Summary      Admin List Products
+// @Description  List every product with every price (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.Product]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/products [get]
+func (c *productController) AdminList(ctx *gin.Context) {
+	res, err := c.productService.List(ctx.Request.Context(), false)
+	ResponseJSON(ctx, gin.H(nil), res, err)
+}
+
+// Create Product godoc
+// @Summary      Create Product
+// @Description  Add a product to the catalog (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateProductRequest  true  "Create Product Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Product]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/products [post]
+func (c *productController) Create(ctx *gin.Context) {
+	req := RequestJSON[dto.CreateProductRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.productService.Create(ctx.Request.Context(), req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Update Product godoc
+// @Summary      Update Product
+// @Description  Rename, describe or (de)activate a product (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "Product ID"
+// @Param        request  body      dto.UpdateProductRequest  true  "Update Product Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Product]
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/products/{id} [put]
+func (c *productController) Update(ctx *gin.Context) {
+	productId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	req := RequestJSON[dto.UpdateProductRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.productService.Update(ctx.Request.Context(), productId, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Create Price godoc
+// @Summary      Create Price
+// @Description  Add a price to a product (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Product ID"
+// @Param        request  body      dto.CreatePriceRequest  true  "Create Price Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Price]
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/products/{id}/prices [post]
+func (c *productController) CreatePrice(ctx *gin.Context) {
+	productId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	req := RequestJSON[dto.CreatePriceRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.productService.CreatePrice(ctx.Request.Context(), productId, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Update Price godoc
+// @Summary      Update Price
+// @Description  Activate or deactivate a price (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                  true  "Price ID"
+// @Param        request  body      dto.UpdatePriceRequest  true  "Update Price Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Price]
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/prices/{id} [put]
+func (c *productController) UpdatePrice(ctx *gin.Context) {
+	priceId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	req := RequestJSON[dto.UpdatePriceRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.productService.UpdatePrice(ctx.Request.Context(), priceId, req)
+	ResponseJSON(ctx, req, res, err)
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaController interface {
+	Usage(ctx *gin.Context)
+	AdminUsage(ctx *gin.Context)
+	List(ctx *gin.Context)
+	SetRole(ctx *gin.Context)
+	DeleteRole(ctx *gin.Context)
+	SetAccount(ctx *gin.Context)
+	DeleteAccount(ctx *gin.Context)
+}
+
+type quotaController struct {
+	quotaService services.QuotaService
+}
+
+func NewQuotaController(quotaService services.QuotaService) QuotaController {
+	return &quotaController{quotaService: quotaService}
+}
+
+// Storage Usage godoc
+// @Summary      Storage Usage
+// @Description  Bytes and number of files the authenticated account stores, trashed files included, with its limits. A zero limit is no limit
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[dto.StorageUsageResponse]
+// @Security     BearerAuth
+// @Router       /api/v1/files/usage [get]
+func (c *quotaController) Usage(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	res, err := c.quotaService.Usage(ctx.Request.Context(), accountId)
+	ResponseJSON(ctx, gin.H{"account_id": accountId}, res, err)
+}
+
+// Account Storage Usage godoc
+// @Summary      Account Storage Usage
+// @Description  Storage usage and resolved limits of any account (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        account_id  path      string  true  "Account ID"
+// @Success      200         {object}  dto.SuccessResponse[dto.StorageUsageResponse]
+// @Failure      400         {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/quotas/accounts/{account_id}/usage [get]
+func (c *quotaController) AdminUsage(ctx *gin.Context) {
+	accountId, ok := ParseParamUUID(ctx, "account_id")
+	if !ok {
+		return
+	}
+	res, err := c.quotaService.Usage(ctx.Request.Context(), accountId)
+	ResponseJSON(ctx, gin.H{"account_id": accountId}, res, err)
+}
+
+// List Storage Quotas godoc
+// @Summary      List Storage Quotas
+// @Description  Every role and account override of the default storage limits (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.StorageQuota]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/quotas [get]
+func (c *quotaController) List(ctx *gin.Context) {
+	res, err := c.quotaService.ListOverrides(ctx.Request.Context())
+	ResponseJSON(ctx, gin.H{}, res, err)
+}
+
+// Set Role Quota godoc
+// @Summary      Set Role Quota
+// @Description  Override the default storage limits for every account of a role. A limit left out keeps the default; zero lifts it (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        role     path      string                   true  "Role, e.g. user or admin"
+// @Param        request  body      dto.StorageQuotaRequest  true  "Storage Quota Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.StorageQuota]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/quotas/roles/{role} [put]
+func (c *quotaController) SetRole(ctx *gin.Context) {
+	c.set(ctx, entity.QuotaScopeRole, ctx.Param("role"))
+}
+
+// Delete Role Quota godoc
+// @Summary      Delete Role Quota
+// @Description  Drop the override of a role, back to the default limits (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        role  path      string  true  "Role"
+// @Success      200   {object}  dto.SuccessResponse[string]
+// @Failure      404   {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/quotas/roles/{role} [delete]
+func (c *quotaController) DeleteRole(ctx *gin.Context) {
+	c.delete(ctx, entity.QuotaScopeRole, ctx.Param("role"))
+}
+
+// Set Account Quota godoc
+// @Summary      Set Account Quota
+// @Description  Override the storage limits of one account, above those of its role. A limit left out keeps the role's; zero lifts it (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        account_id  path      string                   true  "Account ID"
+// @Param        request     body      dto.StorageQuotaRequest  true  "Storage Quota Request"
+// @Success      200         {object}  dto.SuccessResponse[entity.StorageQuota]
+// @Failure      400         {object}  dto.ErrorResponse
+// @Failure      404         {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/quotas/accounts/{account_id} [put]
+func (c *quotaController) SetAccount(ctx *gin.Context) {
+	c.set(ctx, entity.QuotaScopeAccount, ctx.Param("account_id"))
+}
+
+// Delete Account Quota godoc
+// @Summary      Delete Account Quota
+// @Description  Drop the override of an account, back to the limits of its role (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        account_id  path      string  true  "Account ID"
+// @Success      200         {object}  dto.SuccessResponse[string]
+// @Failure      404         {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/quotas/accounts/{account_id} [delete]
+func (c *quotaController) DeleteAccount(ctx *gin.Context) {
+	c.delete(ctx, entity.QuotaScopeAccount, ctx.Param("account_id"))
+}
+
+func (c *quotaController) set(ctx *gin.Context, scope string, subject string) {
+	req := RequestJSON[dto.StorageQuotaRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.quotaService.SetOverride(ctx.Request.Context(), scope, subject, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+func (c *quotaController) delete(ctx *gin.Context, scope string, subject string) {
+	err := c.quotaService.DeleteOverride(ctx.Request.Context(), scope, subject)
+	ResponseJSON(ctx, gin.H{"scope": scope, "subject": subject}, subject, err)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReceiptController interface {
+	Get(ctx *gin.Context)
+	Download(ctx *gin.Context)
+}
+
+type receiptController struct {
+	receiptService services.ReceiptService
+}
+
+func NewReceiptController(receiptService services.ReceiptService) ReceiptController {
+	return &receiptController{receiptService: receiptService}
+}
+
+// Get Receipt godoc
+// @Summary      Get Receipt
+// @Description  Get the receipt of a PAID payment of the authenticated account
+// @Tags         Payment
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Payment ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.Receipt]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/payment/{id}/receipt [get]
+func (c *receiptController) Get(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.receiptService.Get(ctx.Request.Context(), ParseAccountId(ctx), id)
+	ResponseJSON(ctx, gin.H{"payment_id": id}, res, err)
+}
+
+// Download Receipt godoc
+// @Summary      Download Receipt
+// @Description  Download the PDF receipt of a PAID payment of the authenticated account
+// @Tags         Payment
+// @Produce      application/pdf
+// @Param        id   path      string  true  "Payment ID"
+// @Success      200  {file}    file
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/payment/{id}/receipt/pdf [get]
+func (c *receiptController) Download(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	receipt, content, err := c.receiptService.Download(ctx.Request.Context(), ParseAccountId(ctx), id)
+	if err != nil {
+		ResponseJSON(ctx, gin.H{"payment_id": id}, receipt, err)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", receipt.Number+".pdf"))
+	ctx.Data(http.StatusOK, "application/pdf", content)
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/models/dto"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RefundController interface {
+	Request(ctx *gin.Context)
+	List(ctx *gin.Context)
+	Approve(ctx *gin.Context)
+	Reject(ctx *gin.Context)
+}
+
+type refundController struct {
+	refundService services.RefundService
+}
+
+func NewRefundController(refundService services.RefundService) RefundController {
+	return &refundController{refundService: refundService}
+}
+
+// Request Refund godoc
+// @Summary      Request Refund
+// @Description  Request a full or partial refund of a paid payment of the authenticated account
+// @Tags         Payment
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true  "Payment ID"
+// @Param        request  body      dto.CreateRefundRequest  true  "Refund Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Refund]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/payment/{id}/refunds [post]
+func (c *refundController) Request(ctx *gin.Context) {
+	paymentId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	req := RequestJSON[dto.CreateRefundRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	accountId := ParseAccountId(ctx)
+	res, err := c.refundService.Request(ctx.Request.Context(), accountId, paymentId, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// List Refunds godoc
+// @Summary      List Refunds
+// @Description  List refund requests, optionally filtered by status (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        status  query     string  false  "REQUESTED, PENDING, SUCCEEDED, FAILED or REJECTED"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.Refund]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/refunds [get]
+func (c *refundController) List(ctx *gin.Context) {
+	status := ctx.Query("status")
+	res, err := c.refundService.List(ctx.Request.Context(), status)
+	ResponseJSON(ctx, gin.H{"status": status}, res, err)
+}
+
+// Approve Refund godoc
+// @Summary      Approve Refund
+// @Description  Approve a refund request and submit it to the payment gateway (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true   "Refund ID"
+// @Param        request  body      dto.ReviewRefundRequest  false  "Review note"
+// @Success      200      {object}  dto.SuccessResponse[entity.Refund]
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      502      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/refunds/{id}/approve [post]
+func (c *refundController) Approve(ctx *gin.Context) {
+	refundId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReviewRefundRequest
+	_ = ctx.ShouldBindJSON(&req)
+	adminId := ParseAccountId(ctx)
+	res, err := c.refundService.Approve(ctx.Request.Context(), adminId, refundId, req)
+	ResponseJSON(ctx, gin.H{"id": refundId}, res, err)
+}
+
+// Reject Refund godoc
+// @Summary      Reject Refund
+// @Description  Reject a refund request (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true   "Refund ID"
+// @Param        request  body      dto.ReviewRefundRequest  false  "Review note"
+// @Success      200      {object}  dto.SuccessResponse[entity.Refund]
+// @Failure      409      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/payment/refunds/{id}/reject [post]
+func (c *refundController) Reject(ctx *gin.Context) {
+	refundId, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReviewRefundRequest
+	_ = ctx.ShouldBindJSON(&req)
+	adminId := ParseAccountId(ctx)
+	res, err := c.refundService.Reject(ctx.Request.Context(), adminId, refundId, req)
+	ResponseJSON(ctx, gin.H{"id": refundId}, res, err)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"strings"
+
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type StorageController interface {
+	Serve(ctx *gin.Context)
+}
+
+type storageController struct {
+	storageService services.StorageService
+	fileService    services.FileService
+}
+
+func NewStorageController(storageService services.StorageService, fileService services.FileService) StorageController {
+	return &storageController{storageService: storageService, fileService: fileService}
+}
+
+// Serve Stored File godoc
+// @Summary      Serve Stored File
+// @Description  Serve a file of the local storage backend. Private files need the expires and signature of a signed URL; files not scanned clean are refused
+// @Tags         Upload
+// @Produce      octet-stream
+// @Param        filepath   path      string  true   "Storage key"
+// @Param        expires    query     string  false  "Expiry of a signed URL, in Unix seconds"
+// @Param        signature  query     string  false  "Signature of a signed URL"
+// @Success      200        {file}    file
+// @Failure      403        {object}  dto.ErrorResponse
+// @Failure      404        {object}  dto.ErrorResponse
+// @Router       /storage/{filepath} [get]
+func (c *storageController) Serve(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("filepath"), "/")
+	local, ok := c.storageService.(services.LocalStorageService)
+	if !ok {
+		ResponseJSON(ctx, gin.H{"key": key}, "", http_error.NOT_FOUND_ERROR)
+		return
+	}
+	if services.IsPrivateKey(key) {
+		if err := local.VerifySignature(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+			ResponseJSON(ctx, gin.H{"key": key}, "", err)
+			return
+		}
+		ctx.Header("Cache-Control", "private, no-store")
+	}
+	if err := c.fileService.CheckServable(ctx.Request.Context(), key); err != nil {
+		ResponseJSON(ctx, gin.H{"key": key}, "", err)
+		return
+	}
+	path, err := local.FilePath(key)
+	if err != nil {
+		ResponseJSON(ctx, gin.H{"key": key}, "", err)
+		return
+	}
+	ctx.File(path)
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SubscriptionController interface {
+	List(ctx *gin.Context)
+	Get(ctx *gin.Context)
+	Cancel(ctx *gin.Context)
+	Resume(ctx *gin.Context)
+	AdminList(ctx *gin.Context)
+	RunRenewals(ctx *gin.Context)
+}
+
+type subscriptionController struct {
+	subscriptionService services.SubscriptionService
+}
+
+func NewSubscriptionController(subscriptionService services.SubscriptionService) SubscriptionController {
+	return &subscriptionController{subscriptionService: subscriptionService}
+}
+
+// List Subscriptions godoc
+// @Summary      List Subscriptions
+// @Description  List the subscriptions of the authenticated account. Subscriptions are started by checking out a plan price.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.Subscription]
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions [get]
+func (c *subscriptionController) List(ctx *gin.Context) {
+	accountId := ParseAccountId(ctx)
+	res, err := c.subscriptionService.List(ctx.Request.Context(), accountId)
+	ResponseJSON(ctx, gin.H{"account_id": accountId}, res, err)
+}
+
+// Get Subscription godoc
+// @Summary      Get Subscription
+// @Description  Get a subscription of the authenticated account
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.Subscription]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions/{id} [get]
+func (c *subscriptionController) Get(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.subscriptionService.Get(ctx.Request.Context(), ParseAccountId(ctx), id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+// Cancel Subscription godoc
+// @Summary      Cancel Subscription
+// @Description  Cancel a subscription at the end of its current period
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.Subscription]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions/{id}/cancel [post]
+func (c *subscriptionController) Cancel(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.subscriptionService.Cancel(ctx.Request.Context(), ParseAccountId(ctx), id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+// Resume Subscription godoc
+// @Summary      Resume Subscription
+// @Description  Undo a cancellation requested for the end of the current period
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.Subscription]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions/{id}/resume [post]
+func (c *subscriptionController) Resume(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.subscriptionService.Resume(ctx.Request.Context(), ParseAccountId(ctx), id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+// Admin List Subscriptions godoc
+// @Summary      Admin List Subscriptions
+// @Description  List subscriptions, newest first, optionally by status (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        status  query     string  false  "INCOMPLETE, ACTIVE, PAST_DUE or CANCELED"
+// @Param        limit   query     int     false  "Page size (default 20)"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.Subscription]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/subscriptions [get]
+func (c *subscriptionController) AdminList(ctx *gin.Context) {
+	pagination := ParsePagination(ctx)
+	res, err := c.subscriptionService.AdminList(ctx.Request.Context(), pagination)
+	ResponseJSON(ctx, pagination, res, err)
+}
+
+// Run Subscription Renewals godoc
+// @Summary      Run Subscription Renewals
+// @Description  Run the renewal job now instead of waiting for its schedule (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[dto.SubscriptionRenewalReport]
+// @Failure      409  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/subscriptions/renewals [post]
+func (c *subscriptionController) RunRenewals(ctx *gin.Context) {
+	res, err := c.subscriptionService.RunRenewals(ctx.Request.Context())
+	ResponseJSON(ctx, gin.H(nil), res, err)
+}
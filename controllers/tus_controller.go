@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+	tusChunkType  = "application/offset+octet-stream"
+)
+
+// TusController speaks the tus 1.0 resumable upload protocol. Answers carry their state
+// in headers, as tus clients expect, instead of the usual JSON envelope.
+type TusController interface {
+	Options(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Head(ctx *gin.Context)
+	Patch(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
+
+type tusController struct {
+	tusService services.TusService
+}
+
+func NewTusController(tusService services.TusService) TusController {
+	return &tusController{tusService: tusService}
+}
+
+// Tus Options godoc
+// @Summary      Tus Capabilities
+// @Description  Advertise the tus version and extensions the server supports
+// @Tags         Upload
+// @Success      204
+// @Router       /api/v1/files/tus [options]
+func (c *tusController) Options(ctx *gin.Context) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Header("Tus-Version", tusVersion)
+	ctx.Header("Tus-Extension", tusExtensions)
+	ctx.Status(http.StatusNoContent)
+}
+
+// Tus Create godoc
+// @Summary      Create Resumable Upload
+// @Description  Start a tus upload. Upload-Metadata must carry filename and may carry context; the announced length is checked against the upload rule of the context
+// @Tags         Upload
+// @Param        Tus-Resumable    header  string  true   "1.0.0"
+// @Param        Upload-Length    header  int     true   "Size of the whole file in bytes"
+// @Param        Upload-Metadata  header  string  true   "filename and optional context, filetype, as base64 pairs"
+// @Success      201
+// @Failure      400
+// @Failure      413
+// @Security     BearerAuth
+// @Router       /api/v1/files/tus [post]
+func (c *tusController) Create(ctx *gin.Context) {
+	if !tusResumable(ctx) {
+		return
+	}
+	if ctx.GetHeader("Upload-Defer-Length") != "" {
+		tusFail(ctx, http.StatusBadRequest, "Upload-Defer-Length is not supported")
+		return
+	}
+	length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		tusFail(ctx, http.StatusBadRequest, "Upload-Length is missing or invalid")
+		return
+	}
+	upload, err := c.tusService.Create(ctx.Request.Context(), ParseAccountId(ctx), length, ctx.GetHeader("Upload-Metadata"))
+	if err != nil {
+		tusError(ctx, err)
+		return
+	}
+	ctx.Header("Location", strings.TrimRight(ctx.Request.URL.Path, "/")+"/"+upload.Id.String())
+	tusUploadHeaders(ctx, upload)
+	ctx.Status(http.StatusCreated)
+}
+
+// Tus Head godoc
+// @Summary      Resumable Upload Offset
+// @Description  How many bytes of a tus upload the server has, to resume from there
+// @Tags         Upload
+// @Param        Tus-Resumable  header  string  true  "1.0.0"
+// @Param        id             path    string  true  "Upload ID"
+// @Success      200
+// @Failure      404
+// @Failure      410
+// @Security     BearerAuth
+// @Router       /api/v1/files/tus/{id} [head]
+func (c *tusController) Head(ctx *gin.Context) {
+	if !tusResumable(ctx) {
+		return
+	}
+	id, ok := parseTusId(ctx)
+	if !ok {
+		return
+	}
+	upload, err := c.tusService.Get(ctx.Request.Context(), ParseAccountId(ctx), id)
+	if err != nil {
+		tusError(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	tusUploadHeaders(ctx, upload)
+	ctx.Status(http.StatusOK)
+}
+
+// Tus Patch godoc
+// @Summary      Upload Chunk
+// @Description  Append a chunk at Upload-Offset. The chunk that completes the upload stores the file and returns its ID in Upload-File-Id
+// @Tags         Upload
+// @Accept       application/offset+octet-stream
+// @Param        Tus-Resumable  header  string  true  "1.0.0"
+// @Param        Upload-Offset  header  int     true  "Offset the chunk starts at"
+// @Param        id             path    string  true  "Upload ID"
+// @Success      204
+// @Failure      409
+// @Failure      410
+// @Failure      415
+// @Security     BearerAuth
+// @Router       /api/v1/files/tus/{id} [patch]
+func (c *tusController) Patch(ctx *gin.Context) {
+	if !tusResumable(ctx) {
+		return
+	}
+	id, ok := parseTusId(ctx)
+	if !ok {
+		return
+	}
+	if ctx.ContentType() != tusChunkType {
+		tusFail(ctx, http.StatusUnsupportedMediaType, "Content-Type must be "+tusChunkType)
+		return
+	}
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		tusFail(ctx, http.StatusBadRequest, "Upload-Offset is missing or invalid")
+		return
+	}
+	upload, err := c.tusService.Append(ctx.Request.Context(), ParseAccountId(ctx), id, offset, ctx.Request.Body)
+	if err != nil {
+		tusError(ctx, err)
+		return
+	}
+	tusUploadHeaders(ctx, upload)
+	ctx.Status(http.StatusNoContent)
+}
+
+// Tus Delete godoc
+// @Summary      Terminate Resumable Upload
+// @Description  Drop a tus upload and the bytes received so far
+// @Tags         Upload
+// @Param        Tus-Resumable  header  string  true  "1.0.0"
+// @Param        id             path    string  true  "Upload ID"
+// @Success      204
+// @Failure      404
+// @Security     BearerAuth
+// @Router       /api/v1/files/tus/{id} [delete]
+func (c *tusController) Delete(ctx *gin.Context) {
+	if !tusResumable(ctx) {
+		return
+	}
+	id, ok := parseTusId(ctx)
+	if !ok {
+		return
+	}
+	if err := c.tusService.Terminate(ctx.Request.Context(), ParseAccountId(ctx), id); err != nil {
+		tusError(ctx, err)
+		return
+	}
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Status(http.StatusNoContent)
+}
+
+// tusResumable refuses requests of another protocol version with 412.
+func tusResumable(ctx *gin.Context) bool {
+	if ctx.GetHeader("Tus-Resumable") != tusVersion {
+		ctx.Header("Tus-Version", tusVersion)
+		tusFail(ctx, http.StatusPreconditionFailed, "Tus-Resumable must be "+tusVersion)
+		return false
+	}
+	return true
+}
+
+// parseTusId answers 404 for an id that cannot name an upload.
+func parseTusId(ctx *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		tusFail(ctx, http.StatusNotFound, "Upload not found")
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func tusUploadHeaders(ctx *gin.Context, upload entity.TusUpload) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	ctx.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.FileId != nil {
+		ctx.Header("Upload-File-Id", upload.FileId.String())
+	} else {
+		ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// tusError answers a failed tus request with the status the protocol expects.
+func tusError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, http_error.NOT_FOUND_ERROR):
+		status = http.StatusNotFound
+	case errors.Is(err, http_error.UPLOAD_EXPIRED):
+		status = http.StatusGone
+	case errors.Is(err, http_error.UPLOAD_OFFSET_MISMATCH):
+		status = http.StatusConflict
+	case errors.Is(err, http_error.FILE_TOO_LARGE), errors.Is(err, http_error.QUOTA_EXCEEDED):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, http_error.BAD_REQUEST_ERROR),
+		errors.Is(err, http_error.INVALID_FILE_TYPE),
+		errors.Is(err, http_error.INVALID_DATA_PAYLOAD),
+		errors.Is(err, http_error.INVALID_UPLOAD_CONTEXT_ERROR):
+		status = http.StatusBadRequest
+	case errors.Is(err, http_error.UNAUTHORIZED):
+		status = http.StatusUnauthorized
+	case errors.Is(err, http_error.FORBIDDEN_ERROR):
+		status = http.StatusForbidden
+	default:
+		log.Printf("[TUS] %s %s failed: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+	}
+	tusFail(ctx, status, err.Error())
+}
+
+func tusFail(ctx *gin.Context, status int, message string) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Status(status)
+		return
+	}
+	ctx.String(status, message)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeTusService fails every request with err.
+type fakeTusService struct {
+	services.TusService
+	err error
+}
+
+func (s fakeTusService) Get(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID) (entity.TusUpload, error) {
+	return entity.TusUpload{}, s.err
+}
+
+func (s fakeTusService) Append(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID, offset int64, chunk io.Reader) (entity.TusUpload, error) {
+	return entity.TusUpload{}, s.err
+}
+
+func TestTusErrorStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		want   int
+	}{
+		{"offset mismatch", http.MethodPatch, http_error.UPLOAD_OFFSET_MISMATCH, http.StatusConflict},
+		{"chunk past the length", http.MethodPatch, http_error.FILE_TOO_LARGE, http.StatusRequestEntityTooLarge},
+		{"interrupted chunk", http.MethodPatch, http_error.BAD_REQUEST_ERROR, http.StatusBadRequest},
+		{"expired upload", http.MethodHead, http_error.UPLOAD_EXPIRED, http.StatusGone},
+		{"unknown upload", http.MethodHead, http_error.NOT_FOUND_ERROR, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewTusController(fakeTusService{err: tt.err})
+			router := gin.New()
+			router.Use(func(ctx *gin.Context) { ctx.Set("account_id", uuid.New().String()) })
+			router.PATCH("/tus/:id", controller.Patch)
+			router.HEAD("/tus/:id", controller.Head)
+
+			req := httptest.NewRequest(tt.method, "/tus/"+uuid.New().String(), strings.NewReader("chunk"))
+			req.Header.Set("Tus-Resumable", tusVersion)
+			req.Header.Set("Upload-Offset", "0")
+			req.Header.Set("Content-Type", tusChunkType)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			if res.Code != tt.want {
+				t.Errorf("status = %d, want %d", res.Code, tt.want)
+			}
+			if res.Header().Get("Tus-Resumable") != tusVersion {
+				t.Errorf("Tus-Resumable = %q, want %s", res.Header().Get("Tus-Resumable"), tusVersion)
+			}
+		})
+	}
+}
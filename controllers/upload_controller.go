@@ -6,13 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 	"abdanhafidz.com/go-boilerplate/services"
 )
@@ -30,15 +30,17 @@ func NewUploadController(uploadService services.UploadService) UploadController
 
 // Upload godoc
 // @Summary      Upload Files
-// @Description  Upload one or more files to the server
+// @Description  Upload one or more files to the server. Images of the image context are stripped of metadata, turned upright, scaled down and given thumbnail variants. Each file counts against the storage quota of the account; one past it is refused with 413
 // @Tags         Upload
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        context  formData  string  false  "Upload Context (e.g., image, submission, material)"
+// @Param        context  formData  string  false  "Upload Context (e.g., image, submission, material); inferred from the first file's extension when empty"
 // @Param        files    formData  file    true   "Files to upload (multiple allowed)"
 // @Success      201      {object}  dto.FileUploadResponse
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      401      {object}  dto.ErrorResponse
+// @Failure      403      {object}  dto.ErrorResponse
+// @Failure      413      {object}  dto.ErrorResponse
 // @Failure      422      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
 // @Router       /api/v1/files [post]
@@ -103,10 +105,6 @@ func (c *uploadController) Upload(ctx *gin.Context) {
 	}
 
 	uploadContext := ctx.PostForm("context")
-	if uploadContext == "" {
-		ext := strings.ToLower(filepath.Ext(files[0].Filename))
-		uploadContext = c.inferContextFromExt(ext)
-	}
 
 	accountIDStr := ctx.GetString("account_id")
 	if accountIDStr == "" {
@@ -138,7 +136,8 @@ func (c *uploadController) Upload(ctx *gin.Context) {
 		if errors.Is(err, http_error.FILE_TOO_LARGE) ||
 			errors.Is(err, http_error.INVALID_FILE_TYPE) ||
 			errors.Is(err, http_error.BAD_REQUEST_ERROR) ||
-			errors.Is(err, http_error.INVALID_DATA_PAYLOAD) {
+			errors.Is(err, http_error.INVALID_DATA_PAYLOAD) ||
+			errors.Is(err, http_error.INVALID_UPLOAD_CONTEXT_ERROR) {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"status":  "error",
 				"message": err.Error(),
@@ -146,6 +145,22 @@ func (c *uploadController) Upload(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, http_error.FORBIDDEN_ERROR) {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"status":  "error",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, http_error.QUOTA_EXCEEDED) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"status":  "error",
+				"message": err.Error(),
+			})
+			return
+		}
+
 		if errors.Is(err, http_error.PARTIAL_UPLOAD_FAILURE) {
 			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
 				"status":  "error",
@@ -164,14 +179,7 @@ func (c *uploadController) Upload(ctx *gin.Context) {
 
 	var fileResponses []dto.FileResponse
 	for _, f := range uploadedFiles {
-		fileResponses = append(fileResponses, dto.FileResponse{
-			Id:           f.Id,
-			OriginalName: f.OriginalName,
-			URL:          f.Path,
-			MimeType:     f.MimeType,
-			Size:         f.Size,
-			CreatedAt:    f.CreatedAt,
-		})
+		fileResponses = append(fileResponses, toFileResponse(f))
 	}
 
 	ctx.JSON(http.StatusCreated, dto.FileUploadResponse{
@@ -183,7 +191,7 @@ func (c *uploadController) Upload(ctx *gin.Context) {
 
 // Get File By ID godoc
 // @Summary      Get File by ID
-// @Description  Retrieve file details using its ID
+// @Description  Retrieve file details using its ID. Private files get a short-lived signed URL
 // @Tags         Upload
 // @Accept       json
 // @Produce      json
@@ -239,43 +247,37 @@ func (c *uploadController) GetFileByID(ctx *gin.Context) {
 		return
 	}
 
-	response := dto.FileResponse{
-		Id:           fileData.Id,
-		OriginalName: fileData.OriginalName,
-		URL:          fileData.Path,
-		MimeType:     fileData.MimeType,
-		Size:         fileData.Size,
-		CreatedAt:    fileData.CreatedAt,
-	}
-
 	ctx.JSON(http.StatusOK, dto.FileResponseSingle{
 		Status:  "success",
 		Message: "File retrieved successfully",
-		Data:    response,
+		Data:    toFileResponse(*fileData),
 	})
 }
 
-// inferContextFromExt infers the upload context based on file extension
-func (c *uploadController) inferContextFromExt(ext string) string {
-	images := map[string]bool{
-		".jpg": true, ".jpeg": true, ".png": true, ".webp": true,
-	}
-	isSourceCode := map[string]bool{
-		".cpp": true, ".c": true, ".py": true, ".java": true,
-		".go": true, ".js": true, ".txt": true,
-	}
-	isDocument := map[string]bool{
-		".pdf": true,
-	}
-
-	if images[ext] {
-		return "image"
-	}
-	if isSourceCode[ext] {
-		return "submission"
+func toFileResponse(file entity.File) dto.FileResponse {
+	response := dto.FileResponse{
+		Id:           file.Id,
+		OriginalName: file.OriginalName,
+		URL:          file.Path,
+		MimeType:     file.MimeType,
+		Size:         file.Size,
+		Private:      file.Private,
+		ScanStatus:   file.ScanStatus,
+		Sha256:       file.Sha256,
+		Width:        file.Width,
+		Height:       file.Height,
+		CreatedAt:    file.CreatedAt,
+		URLExpiresAt: file.URLExpiresAt,
 	}
-	if isDocument[ext] {
-		return "material"
+	for _, variant := range file.Variants {
+		response.Variants = append(response.Variants, dto.FileVariantResponse{
+			Dimension: variant.Dimension,
+			URL:       variant.Path,
+			Width:     variant.Width,
+			Height:    variant.Height,
+			MimeType:  variant.MimeType,
+			Size:      variant.Size,
+		})
 	}
-	return ""
+	return response
 }
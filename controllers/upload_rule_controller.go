@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UploadRuleController interface {
+	List(ctx *gin.Context)
+	Get(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
+
+type uploadRuleController struct {
+	uploadRuleService services.UploadRuleService
+}
+
+func NewUploadRuleController(uploadRuleService services.UploadRuleService) UploadRuleController {
+	return &uploadRuleController{uploadRuleService: uploadRuleService}
+}
+
+// List Upload Rules godoc
+// @Summary      List Upload Rules
+// @Description  Every upload context with its size, type, count, visibility and role limits (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.SuccessResponse[[]entity.UploadRule]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/upload-rules [get]
+func (c *uploadRuleController) List(ctx *gin.Context) {
+	res, err := c.uploadRuleService.List(ctx.Request.Context())
+	ResponseJSON(ctx, gin.H{}, res, err)
+}
+
+// Get Upload Rule godoc
+// @Summary      Get Upload Rule
+// @Description  The rule of one upload context (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        context  path      string  true  "Upload Context"
+// @Success      200      {object}  dto.SuccessResponse[entity.UploadRule]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/upload-rules/{context} [get]
+func (c *uploadRuleController) Get(ctx *gin.Context) {
+	uploadContext := ctx.Param("context")
+	res, err := c.uploadRuleService.Get(ctx.Request.Context(), uploadContext)
+	ResponseJSON(ctx, gin.H{"context": uploadContext}, res, err)
+}
+
+// Create Upload Rule godoc
+// @Summary      Create Upload Rule
+// @Description  Add an upload context. path_template may use {context}, {year} and {month}; an empty roles list lets every role upload, and a positive priority lets uploads without a context be inferred to it (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.UploadRuleRequest  true  "Upload Rule Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.UploadRule]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/upload-rules [post]
+func (c *uploadRuleController) Create(ctx *gin.Context) {
+	req := RequestJSON[dto.UploadRuleRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.uploadRuleService.Create(ctx.Request.Context(), req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Update Upload Rule godoc
+// @Summary      Update Upload Rule
+// @Description  Replace every setting of an upload context. Files already stored keep their visibility and location (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        context  path      string                 true  "Upload Context"
+// @Param        request  body      dto.UploadRuleRequest  true  "Upload Rule Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.UploadRule]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/upload-rules/{context} [put]
+func (c *uploadRuleController) Update(ctx *gin.Context) {
+	req := RequestJSON[dto.UploadRuleRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.uploadRuleService.Update(ctx.Request.Context(), ctx.Param("context"), req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Delete Upload Rule godoc
+// @Summary      Delete Upload Rule
+// @Description  Remove an upload context; its files stay. Contexts the application uploads to itself, such as receipt, cannot be removed (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        context  path      string  true  "Upload Context"
+// @Success      200      {object}  dto.SuccessResponse[string]
+// @Failure      403      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/upload-rules/{context} [delete]
+func (c *uploadRuleController) Delete(ctx *gin.Context) {
+	uploadContext := ctx.Param("context")
+	err := c.uploadRuleService.Delete(ctx.Request.Context(), uploadContext)
+	ResponseJSON(ctx, gin.H{"context": uploadContext}, uploadContext, err)
+}
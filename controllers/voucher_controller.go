@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"abdanhafidz.com/go-boilerplate/models/dto"
+	"abdanhafidz.com/go-boilerplate/services"
+	"github.com/gin-gonic/gin"
+)
+
+type VoucherController interface {
+	List(ctx *gin.Context)
+	Get(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Redemptions(ctx *gin.Context)
+}
+
+type voucherController struct {
+	voucherService services.VoucherService
+}
+
+func NewVoucherController(voucherService services.VoucherService) VoucherController {
+	return &voucherController{voucherService: voucherService}
+}
+
+// List Vouchers godoc
+// @Summary      List Vouchers
+// @Description  List vouchers, newest first, optionally searched by code (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        limit   query     int     false  "Page size (default 20)"
+// @Param        offset  query     int     false  "Offset"
+// @Param        search  query     string  false  "Code contains"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.Voucher]
+// @Security     BearerAuth
+// @Router       /api/v1/admin/vouchers [get]
+func (c *voucherController) List(ctx *gin.Context) {
+	pagination := ParsePagination(ctx)
+	res, err := c.voucherService.List(ctx.Request.Context(), pagination)
+	ResponseJSON(ctx, pagination, res, err)
+}
+
+// Get Voucher godoc
+// @Summary      Get Voucher
+// @Description  Voucher with its product restriction (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Voucher ID"
+// @Success      200  {object}  dto.SuccessResponse[entity.Voucher]
+// @Failure      404  {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/vouchers/{id} [get]
+func (c *voucherController) Get(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	res, err := c.voucherService.Get(ctx.Request.Context(), id)
+	ResponseJSON(ctx, gin.H{"id": id}, res, err)
+}
+
+// Create Voucher godoc
+// @Summary      Create Voucher
+// @Description  Create a percentage or fixed discount code (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateVoucherRequest  true  "Create Voucher Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Voucher]
+// @Failure      400      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/vouchers [post]
+func (c *voucherController) Create(ctx *gin.Context) {
+	req := RequestJSON[dto.CreateVoucherRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.voucherService.Create(ctx.Request.Context(), req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// Update Voucher godoc
+// @Summary      Update Voucher
+// @Description  Change limits, validity, restriction or activation of a voucher (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "Voucher ID"
+// @Param        request  body      dto.UpdateVoucherRequest  true  "Update Voucher Request"
+// @Success      200      {object}  dto.SuccessResponse[entity.Voucher]
+// @Failure      404      {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/vouchers/{id} [put]
+func (c *voucherController) Update(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	req := RequestJSON[dto.UpdateVoucherRequest](ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	res, err := c.voucherService.Update(ctx.Request.Context(), id, req)
+	ResponseJSON(ctx, req, res, err)
+}
+
+// List Voucher Redemptions godoc
+// @Summary      List Voucher Redemptions
+// @Description  Redemptions of a voucher with their payments, newest first (admin only)
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true   "Voucher ID"
+// @Param        limit   query     int     false  "Page size (default 20)"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200     {object}  dto.SuccessResponse[[]entity.VoucherRedemption]
+// @Failure      404     {object}  dto.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/vouchers/{id}/redemptions [get]
+func (c *voucherController) Redemptions(ctx *gin.Context) {
+	id, ok := ParseParamUUID(ctx, "id")
+	if !ok {
+		return
+	}
+	pagination := ParsePagination(ctx)
+	res, err := c.voucherService.ListRedemptions(ctx.Request.Context(), id, pagination)
+	ResponseJSON(ctx, pagination, res, err)
+}
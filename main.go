@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+
 	"abdanhafidz.com/go-boilerplate/provider"
 	"abdanhafidz.com/go-boilerplate/router"
 )
 
 func main() {
 	appProvider := provider.NewAppProvider()
+	appProvider.StartBackgroundJobs(context.Background())
 	router.RunRouter(appProvider)
 }
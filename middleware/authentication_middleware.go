@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 	"abdanhafidz.com/go-boilerplate/services"
 	utils "abdanhafidz.com/go-boilerplate/utils"
@@ -13,6 +14,7 @@ import (
 
 type AuthenticationMiddleware interface {
 	VerifyAccount(ctx *gin.Context)
+	VerifyAdmin(ctx *gin.Context)
 }
 type authenticationMiddleware struct {
 	jwtService services.JWTService
@@ -38,6 +40,7 @@ func (m *authenticationMiddleware) VerifyAccount(c *gin.Context) {
 		}
 		fmt.Println("Claims:", claim)
 		c.Set("account_id", claim.AccountId)
+		c.Set("role", claim.Role)
 		c.Next()
 
 	} else {
@@ -46,3 +49,13 @@ func (m *authenticationMiddleware) VerifyAccount(c *gin.Context) {
 	}
 
 }
+
+// VerifyAdmin must run after VerifyAccount; it rejects tokens whose role is not admin.
+func (m *authenticationMiddleware) VerifyAdmin(c *gin.Context) {
+	if c.GetString("role") != entity.RoleAdmin {
+		utils.ResponseFAILED(c, "Admin Only", http_error.FORBIDDEN_ERROR)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
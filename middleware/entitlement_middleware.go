@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/services"
+	utils "abdanhafidz.com/go-boilerplate/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type EntitlementMiddleware interface {
+	Require(productCode string) gin.HandlerFunc
+}
+
+type entitlementMiddleware struct {
+	entitlementService services.EntitlementService
+}
+
+func NewEntitlementMiddleware(entitlementService services.EntitlementService) EntitlementMiddleware {
+	return &entitlementMiddleware{
+		entitlementService: entitlementService,
+	}
+}
+
+// Require gates a route behind an entitlement to the product; it must run after
+// VerifyAccount. Accounts without access get a 402 whose meta_data holds the checkout.
+func (m *entitlementMiddleware) Require(productCode string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gaccountId, _ := c.Get("account_id")
+		accountId, err := utils.ToUUID(gaccountId)
+		if err != nil {
+			utils.ResponseFAILED(c, "Empty Token", http_error.UNAUTHORIZED)
+			c.Abort()
+			return
+		}
+
+		entitlement, checkout, err := m.entitlementService.Require(c.Request.Context(), accountId, productCode)
+		if err != nil {
+			utils.ResponseFAILED(c, checkout, err)
+			c.Abort()
+			return
+		}
+		c.Set("entitlement_id", entitlement.Id)
+		c.Next()
+	}
+}
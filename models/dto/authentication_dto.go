@@ -29,7 +29,7 @@ type UpdateUserRoleRequest struct {
 
 type ValidateVerifyEmailRequest struct {
 	Email string `json:"email" binding:"required,email"`
-	Token uint   `json:"token" binding:"required"`
+	Token string `json:"token" binding:"required,numeric"`
 }
 
 type ExternalAuthRequest struct {
@@ -37,7 +37,8 @@ type ExternalAuthRequest struct {
 	OauthProvider string `json:"oauth_provider" binding:"required"`
 }
 type ResetPasswordRequest struct {
-	Token       uint   `json:"token" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Token       string `json:"token" binding:"required,numeric"`
 	NewPassword string `json:"new_password" binding:"required"`
 }
 type ForgotPasswordRequest struct {
@@ -45,7 +46,8 @@ type ForgotPasswordRequest struct {
 }
 
 type ValidateForgotPasswordRequest struct {
-	Token       uint   `json:"token" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Token       string `json:"token" binding:"required,numeric"`
 	NewPassword string `json:"new_password"`
 }
 
@@ -1,5 +1,5 @@
 package dto
 
 type DeleteEmailVerificationRequest struct {
-	Token uint `json:"token" binding:"required"`
+	Email string `json:"email" binding:"required,email"`
 }
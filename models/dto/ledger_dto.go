@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Period limits a query to records dated in [From, To). Either end may be
+// left open.
+type Period struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// LedgerBalance sums the postings of one ledger account in one currency. Balance is
+// signed towards the normal side of the account, so revenue and cash are both positive.
+type LedgerBalance struct {
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Currency string  `json:"currency"`
+	Debit    float64 `json:"debit"`
+	Credit   float64 `json:"credit"`
+	Balance  float64 `json:"balance"`
+}
+
+type LedgerCurrencyTotal struct {
+	Currency   string  `json:"currency"`
+	Debit      float64 `json:"debit"`
+	Credit     float64 `json:"credit"`
+	Difference float64 `json:"difference"`
+}
+
+type LedgerUnbalancedEntry struct {
+	JournalEntryId uuid.UUID `json:"journal_entry_id"`
+	Reference      string    `json:"reference"`
+	Debit          float64   `json:"debit"`
+	Credit         float64   `json:"credit"`
+}
+
+// LedgerCheckReport is the result of verifying that debits equal credits, per entry and
+// per currency.
+type LedgerCheckReport struct {
+	Balanced          bool                    `json:"balanced"`
+	CheckedAt         time.Time               `json:"checked_at"`
+	Totals            []LedgerCurrencyTotal   `json:"totals"`
+	UnbalancedEntries []LedgerUnbalancedEntry `json:"unbalanced_entries"`
+}
+
+// LedgerExportRow is one posting with its entry and account, as exported to CSV.
+type LedgerExportRow struct {
+	PostedAt    time.Time
+	Reference   string
+	Kind        string
+	Description string
+	PaymentId   *uuid.UUID
+	RefundId    *uuid.UUID
+	AccountCode string
+	AccountName string
+	Direction   string
+	Amount      float64
+	Currency    string
+}
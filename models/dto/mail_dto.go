@@ -0,0 +1,8 @@
+package dto
+
+// MailAttachment is a file sent along with an email.
+type MailAttachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type OTPIssuedResponse struct {
+	Email     string    `json:"email"`
+	Purpose   string    `json:"purpose"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
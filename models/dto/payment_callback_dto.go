@@ -0,0 +1,68 @@
+package dto
+
+import "time"
+
+// XenditInvoiceCallback is the body Xendit posts when an invoice changes status.
+type XenditInvoiceCallback struct {
+	Id             string     `json:"id"`
+	ExternalId     string     `json:"external_id"`
+	UserId         string     `json:"user_id"`
+	Status         string     `json:"status"`
+	Amount         float64    `json:"amount"`
+	PaidAmount     float64    `json:"paid_amount"`
+	Currency       string     `json:"currency"`
+	PaymentMethod  string     `json:"payment_method"`
+	PaymentChannel string     `json:"payment_channel"`
+	PaidAt         *time.Time `json:"paid_at"`
+}
+
+// XenditVirtualAccountCallback is the body Xendit posts when a fixed virtual account is paid.
+type XenditVirtualAccountCallback struct {
+	Id                       string     `json:"id"`
+	PaymentId                string     `json:"payment_id"`
+	CallbackVirtualAccountId string     `json:"callback_virtual_account_id"`
+	ExternalId               string     `json:"external_id"`
+	BankCode                 string     `json:"bank_code"`
+	AccountNumber            string     `json:"account_number"`
+	Amount                   float64    `json:"amount"`
+	TransactionTimestamp     *time.Time `json:"transaction_timestamp"`
+}
+
+// XenditEWalletCallback is the body Xendit posts for e-wallet charge events.
+type XenditEWalletCallback struct {
+	Event      string                    `json:"event"`
+	BusinessId string                    `json:"business_id"`
+	Created    string                    `json:"created"`
+	Data       XenditEWalletCallbackData `json:"data"`
+}
+
+type XenditEWalletCallbackData struct {
+	Id            string  `json:"id"`
+	ReferenceId   string  `json:"reference_id"`
+	Status        string  `json:"status"`
+	Currency      string  `json:"currency"`
+	ChargeAmount  float64 `json:"charge_amount"`
+	CaptureAmount float64 `json:"capture_amount"`
+	ChannelCode   string  `json:"channel_code"`
+	FailureCode   string  `json:"failure_code"`
+}
+
+// XenditRefundCallback is the body Xendit posts for refund.succeeded and refund.failed.
+type XenditRefundCallback struct {
+	Event      string                   `json:"event"`
+	BusinessId string                   `json:"business_id"`
+	Created    string                   `json:"created"`
+	Data       XenditRefundCallbackData `json:"data"`
+}
+
+type XenditRefundCallbackData struct {
+	Id          string  `json:"id"`
+	PaymentId   string  `json:"payment_id"`
+	InvoiceId   string  `json:"invoice_id"`
+	ReferenceId string  `json:"reference_id"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	Status      string  `json:"status"`
+	Reason      string  `json:"reason"`
+	FailureCode string  `json:"failure_code"`
+}
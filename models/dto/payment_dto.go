@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+)
+
+type CreatePaymentRequest struct {
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Description string  `json:"description" binding:"required"`
+}
+
+// CreateRefundRequest asks for a refund of a paid payment. Amount defaults to the whole
+// refundable balance.
+type CreateRefundRequest struct {
+	Amount float64 `json:"amount" binding:"omitempty,gt=0"`
+	Reason string  `json:"reason" binding:"required"`
+}
+
+type ReviewRefundRequest struct {
+	Note string `json:"note"`
+}
+
+// AdminPaymentFilter narrows the admin payment list and export. Unset fields do not
+// filter; Search matches the external id, invoice id, description and payer email.
+type AdminPaymentFilter struct {
+	Statuses  []string   `json:"statuses,omitempty"`
+	AccountId *uuid.UUID `json:"account_id,omitempty"`
+	ProductId *uuid.UUID `json:"product_id,omitempty"`
+	MinAmount *float64   `json:"min_amount,omitempty"`
+	MaxAmount *float64   `json:"max_amount,omitempty"`
+	Period    Period     `json:"period"`
+	Search    string     `json:"search,omitempty"`
+}
+
+// AdminPaymentDetail is a payment with everything the provider told us about it.
+type AdminPaymentDetail struct {
+	Payment   entity.Payment           `json:"payment"`
+	Callbacks []entity.PaymentCallback `json:"callbacks"`
+}
+
+// PaymentExportRow is one payment as exported to CSV or XLSX.
+type PaymentExportRow struct {
+	Id             uuid.UUID
+	ExternalId     string
+	InvoiceId      string
+	AccountId      uuid.UUID
+	AccountEmail   string
+	ProductCode    string
+	Description    string
+	Currency       string
+	Amount         float64
+	DiscountAmount float64
+	RefundedAmount float64
+	Status         string
+	CreatedAt      time.Time
+	PaidAt         *time.Time
+}
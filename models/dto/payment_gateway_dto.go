@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// GatewayInvoiceRequest is what PaymentService asks a PaymentGateway to bill.
+type GatewayInvoiceRequest struct {
+	ExternalId  string
+	Amount      float64
+	Currency    string
+	Description string
+	PayerEmail  string
+	Duration    time.Duration
+}
+
+// GatewayInvoice is an invoice as reported by the payment provider. Status uses the
+// entity.PaymentStatus values.
+type GatewayInvoice struct {
+	Id         string
+	ExternalId string
+	URL        string
+	Amount     float64
+	Currency   string
+	Status     string
+	ExpiresAt  time.Time
+}
+
+// SimulatorCheckoutRequest settles a simulated invoice from its checkout page.
+type SimulatorCheckoutRequest struct {
+	Outcome string `form:"outcome" json:"outcome" binding:"required,oneof=PAID EXPIRED"`
+}
+
+// GatewayRefundRequest refunds part or all of a paid invoice. ReferenceId is our refund id.
+type GatewayRefundRequest struct {
+	ReferenceId string
+	InvoiceId   string
+	Amount      float64
+	Currency    string
+	Reason      string
+}
+
+// GatewayRefund is a refund as reported by the payment provider. Status uses the
+// entity.RefundStatus values.
+type GatewayRefund struct {
+	Id          string
+	ReferenceId string
+	Amount      float64
+	Status      string
+	FailureCode string
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateProductRequest struct {
+	Code        string `json:"code" binding:"required,max=64"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateProductRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+type CreatePriceRequest struct {
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Currency        string  `json:"currency"`
+	EntitlementDays int     `json:"entitlement_days" binding:"min=0"`
+	IntervalMonths  int     `json:"interval_months" binding:"min=0"`
+}
+
+type UpdatePriceRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+// CheckoutRequest picks the price to pay for a product; the cheapest active price is
+// used when PriceId is empty. Checking out a plan price starts a subscription whose first
+// invoice is returned. VoucherCode optionally applies a discount code to that invoice.
+type CheckoutRequest struct {
+	PriceId     *uuid.UUID `json:"price_id"`
+	VoucherCode string     `json:"voucher_code"`
+}
+
+// CheckoutResponse describes the invoice to pay for a product. It is also the MetaData of
+// a 402 PAYMENT_REQUIRED response. Amount is Subtotal minus Discount; a fully discounted
+// checkout is PAID right away and has no InvoiceURL.
+type CheckoutResponse struct {
+	ProductId   uuid.UUID  `json:"product_id"`
+	ProductCode string     `json:"product_code"`
+	ProductName string     `json:"product_name"`
+	PriceId     uuid.UUID  `json:"price_id"`
+	PaymentId   uuid.UUID  `json:"payment_id"`
+	Status      string     `json:"status"`
+	InvoiceURL  string     `json:"invoice_url,omitempty"`
+	Subtotal    float64    `json:"subtotal"`
+	VoucherCode string     `json:"voucher_code,omitempty"`
+	Discount    float64    `json:"discount"`
+	Amount      float64    `json:"amount"`
+	Currency    string     `json:"currency"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
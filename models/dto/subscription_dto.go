@@ -0,0 +1,10 @@
+package dto
+
+// SubscriptionRenewalReport counts what one renewal run did.
+type SubscriptionRenewalReport struct {
+	Invoiced  int `json:"invoiced"`
+	PastDue   int `json:"past_due"`
+	Canceled  int `json:"canceled"`
+	Abandoned int `json:"abandoned"`
+	Failed    int `json:"failed"`
+}
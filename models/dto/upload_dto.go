@@ -12,7 +12,48 @@ type FileResponse struct {
 	URL          string    `json:"url"`
 	MimeType     string    `json:"mime_type"`
 	Size         int64     `json:"size"`
+	Private      bool      `json:"private"`
+	ScanStatus   string    `json:"scan_status"`
+	Sha256       string    `json:"sha256,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
+
+	// URLExpiresAt is when the signed URL of a private file stops working.
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+
+	// Variants are the thumbnails of an image, smallest first.
+	Variants []FileVariantResponse `json:"variants,omitempty"`
+}
+
+// FileVariantResponse is a thumbnail fitting a Dimension by Dimension box.
+type FileVariantResponse struct {
+	Dimension int    `json:"dimension"`
+	URL       string `json:"url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	MimeType  string `json:"mime_type"`
+	Size      int64  `json:"size"`
+}
+
+// UploadSlotRequest asks for a URL to upload one file straight to storage. Without a
+// context, it is inferred from the filename.
+type UploadSlotRequest struct {
+	Context  string `json:"context"`
+	Filename string `json:"filename" binding:"required"`
+	Size     int64  `json:"size" binding:"required"`
+}
+
+// UploadSlotResponse tells the client where to PUT the file, with exactly Size bytes,
+// before calling the completion endpoint with Id.
+type UploadSlotResponse struct {
+	Id        uuid.UUID `json:"id"`
+	Context   string    `json:"context"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Method    string    `json:"method"`
+	UploadURL string    `json:"upload_url"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type FileUploadResponse struct {
@@ -26,3 +67,69 @@ type FileResponseSingle struct {
 	Message string       `json:"message"`
 	Data    FileResponse `json:"data"`
 }
+
+// FileFilter narrows a file listing. AccountId is nil when an admin lists every account.
+type FileFilter struct {
+	AccountId  *uuid.UUID
+	Context    string
+	MimeType   string
+	Tag        string
+	ScanStatus string
+	Trashed    bool
+	// Period keeps the files uploaded within it.
+	Period Period
+
+	// Corrupted keeps the files whose stored object failed the integrity check.
+	Corrupted bool
+}
+
+// FileArchiveRequest selects the files of a ZIP download, either by id or by filter.
+// AccountId filters by owner and is honoured for admins only; From and To bound the
+// upload time, To excluded.
+type FileArchiveRequest struct {
+	FileIds   []uuid.UUID `json:"file_ids" binding:"omitempty,max=1000"`
+	Context   string      `json:"context"`
+	AccountId *uuid.UUID  `json:"account_id"`
+	From      *time.Time  `json:"from"`
+	To        *time.Time  `json:"to"`
+	// Name is the name of the archive, without extension; "files" by default.
+	Name string `json:"name" binding:"omitempty,max=100"`
+}
+
+// UpdateFileRequest edits the metadata of a file; fields left out are kept.
+type UpdateFileRequest struct {
+	DisplayName *string   `json:"display_name" binding:"omitempty,max=255"`
+	Tags        *[]string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+}
+
+// StorageUsageResponse is what an account stores against its limits. A zero limit is
+// no limit.
+type StorageUsageResponse struct {
+	AccountId uuid.UUID `json:"account_id"`
+	Bytes     int64     `json:"bytes"`
+	Files     int64     `json:"files"`
+	MaxBytes  int64     `json:"max_bytes"`
+	MaxFiles  int64     `json:"max_files"`
+}
+
+// StorageQuotaRequest sets the limits of a role or account override. A limit left out
+// keeps the one it overrides; zero lifts it.
+type StorageQuotaRequest struct {
+	MaxBytes *int64 `json:"max_bytes" binding:"omitempty,min=0"`
+	MaxFiles *int64 `json:"max_files" binding:"omitempty,min=0"`
+}
+
+// UploadRuleRequest creates or replaces an upload rule. It is also the shape of a rule in
+// the UPLOAD_RULES_FILE seed. Context is taken from the path on update.
+type UploadRuleRequest struct {
+	Context          string   `json:"context" yaml:"context"`
+	MaxBytes         int64    `json:"max_bytes" yaml:"max_bytes"`
+	MaxCount         int      `json:"max_count" yaml:"max_count"`
+	AllowedExts      []string `json:"allowed_exts" yaml:"allowed_exts"`
+	AllowedMimeTypes []string `json:"allowed_mime_types" yaml:"allowed_mime_types"`
+	PathTemplate     string   `json:"path_template" yaml:"path_template"`
+	Private          bool     `json:"private" yaml:"private"`
+	ProcessImages    bool     `json:"process_images" yaml:"process_images"`
+	Roles            []string `json:"roles" yaml:"roles"`
+	Priority         int      `json:"priority" yaml:"priority"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateVoucherRequest defines a discount code. DiscountValue is a percentage (at most 100)
+// for PERCENTAGE vouchers and an amount for FIXED ones; zero limits mean unlimited and no
+// ProductIds means every product.
+type CreateVoucherRequest struct {
+	Code           string      `json:"code" binding:"required"`
+	Description    string      `json:"description"`
+	DiscountType   string      `json:"discount_type" binding:"required,oneof=PERCENTAGE FIXED"`
+	DiscountValue  float64     `json:"discount_value" binding:"required,gt=0"`
+	MaxDiscount    float64     `json:"max_discount" binding:"min=0"`
+	StartsAt       *time.Time  `json:"starts_at"`
+	EndsAt         *time.Time  `json:"ends_at"`
+	MaxRedemptions int         `json:"max_redemptions" binding:"min=0"`
+	MaxPerAccount  int         `json:"max_per_account" binding:"min=0"`
+	ProductIds     []uuid.UUID `json:"product_ids"`
+}
+
+// UpdateVoucherRequest changes the fields that are set. An empty ProductIds list lifts the
+// product restriction.
+type UpdateVoucherRequest struct {
+	Description    *string      `json:"description"`
+	DiscountValue  *float64     `json:"discount_value" binding:"omitempty,gt=0"`
+	MaxDiscount    *float64     `json:"max_discount" binding:"omitempty,min=0"`
+	StartsAt       *time.Time   `json:"starts_at"`
+	EndsAt         *time.Time   `json:"ends_at"`
+	MaxRedemptions *int         `json:"max_redemptions" binding:"omitempty,min=0"`
+	MaxPerAccount  *int         `json:"max_per_account" binding:"omitempty,min=0"`
+	IsActive       *bool        `json:"is_active"`
+	ProductIds     *[]uuid.UUID `json:"product_ids"`
+}
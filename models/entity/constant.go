@@ -17,6 +17,96 @@ const (
 	PaymentStatusPaid    = "PAID"
 	PaymentStatusFailed  = "FAILED"
 	PaymentStatusExpired = "EXPIRED"
+
+	PaymentStatusPartiallyRefunded = "PARTIALLY_REFUNDED"
+	PaymentStatusRefunded          = "REFUNDED"
+)
+
+const (
+	RefundStatusRequested = "REQUESTED"
+	RefundStatusRejected  = "REJECTED"
+	RefundStatusPending   = "PENDING"
+	RefundStatusSucceeded = "SUCCEEDED"
+	RefundStatusFailed    = "FAILED"
+)
+
+const (
+	PaymentCallbackTypeInvoice        = "INVOICE"
+	PaymentCallbackTypeVirtualAccount = "VIRTUAL_ACCOUNT"
+	PaymentCallbackTypeEWallet        = "EWALLET"
+	PaymentCallbackTypeRefund         = "REFUND"
+	PaymentCallbackTypeUnknown        = "UNKNOWN"
+)
+
+const (
+	CallbackStatusReceived  = "RECEIVED"
+	CallbackStatusProcessed = "PROCESSED"
+	CallbackStatusIgnored   = "IGNORED"
+	CallbackStatusFailed    = "FAILED"
+)
+
+const (
+	DiscrepancyMissingInvoice  = "MISSING_INVOICE"
+	DiscrepancyInvoiceNotFound = "INVOICE_NOT_FOUND"
+	DiscrepancyGatewayError    = "GATEWAY_ERROR"
+	DiscrepancyAmountMismatch  = "AMOUNT_MISMATCH"
+	DiscrepancyStatusMismatch  = "STATUS_MISMATCH"
+)
+
+const (
+	EntitlementStatusActive  = "ACTIVE"
+	EntitlementStatusRevoked = "REVOKED"
+
+	EntitlementSourcePayment      = "PAYMENT"
+	EntitlementSourceSubscription = "SUBSCRIPTION"
+)
+
+const (
+	LedgerAccountTypeAsset         = "ASSET"
+	LedgerAccountTypeLiability     = "LIABILITY"
+	LedgerAccountTypeRevenue       = "REVENUE"
+	LedgerAccountTypeContraRevenue = "CONTRA_REVENUE"
+	LedgerAccountTypeExpense       = "EXPENSE"
+
+	LedgerDebit  = "DEBIT"
+	LedgerCredit = "CREDIT"
+
+	JournalKindPayment = "PAYMENT"
+	JournalKindFee     = "FEE"
+	JournalKindRefund  = "REFUND"
+)
+
+const (
+	SubscriptionStatusIncomplete = "INCOMPLETE"
+	SubscriptionStatusActive     = "ACTIVE"
+	SubscriptionStatusPastDue    = "PAST_DUE"
+	SubscriptionStatusCanceled   = "CANCELED"
+)
+
+const (
+	VoucherDiscountPercentage = "PERCENTAGE"
+	VoucherDiscountFixed      = "FIXED"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	QuotaScopeRole    = "ROLE"
+	QuotaScopeAccount = "ACCOUNT"
+)
+
+const (
+	ScanStatusPending  = "PENDING"
+	ScanStatusClean    = "CLEAN"
+	ScanStatusInfected = "INFECTED"
+)
+
+const (
+	OTPPurposeEmailVerification = "EMAIL_VERIFICATION"
+	OTPPurposeForgotPassword    = "FORGOT_PASSWORD"
 )
 
 const MB = 1024 * 1024
@@ -35,17 +35,19 @@ type AccountDetail struct {
 
 func (AccountDetail) TableName() string { return "account_details" }
 
-type EmailVerification struct {
+type OneTimePassword struct {
 	Id        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Token     uint      `json:"token,omitempty"`
-	AccountId uuid.UUID `json:"account_id,omitempty"`
+	AccountId uuid.UUID `gorm:"index:idx_otp_account_purpose" json:"account_id,omitempty"`
+	Purpose   string    `gorm:"index:idx_otp_account_purpose" json:"purpose,omitempty"`
+	TokenHash string    `json:"-"`
+	Attempts  int       `json:"attempts"`
 	IsExpired bool      `json:"is_expired,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	ExpiredAt time.Time `json:"expired_at,omitempty"`
 	Account   *Account  `gorm:"foreignKey:AccountId" json:"account,omitempty"`
 }
 
-func (EmailVerification) TableName() string { return "email_verification" }
+func (OneTimePassword) TableName() string { return "one_time_password" }
 
 type ExternalAuth struct {
 	Id            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -64,17 +66,6 @@ type FCM struct {
 
 func (FCM) TableName() string { return "fcm" }
 
-type ForgotPassword struct {
-	Id        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Token     uint      `json:"token,omitempty"`
-	AccountId uuid.UUID `json:"account_id,omitempty"`
-	IsExpired bool      `json:"is_expired,omitempty"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	ExpiredAt time.Time `json:"expired_at,omitempty"`
-}
-
-func (ForgotPassword) TableName() string { return "forgot_password" }
-
 type OptionCategory struct {
 	Id         uint   `gorm:"primaryKey" json:"id"`
 	OptionName string `json:"option_name,omitempty"`
@@ -120,14 +111,459 @@ func (Options) TableName() string { return "options" }
 type File struct {
 	Id           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	OriginalName string    `json:"original_name,omitempty"`
+	DisplayName  string    `json:"display_name,omitempty"`
+	Tags         []string  `gorm:"type:jsonb;serializer:json" json:"tags,omitempty"`
 	StoredName   string    `json:"stored_name,omitempty"`
 	MimeType     string    `json:"mime_type,omitempty"`
 	Size         int64     `json:"size,omitempty"`
 	Path         string    `json:"path,omitempty"`
+	StorageKey   string    `json:"-"`
 	Context      string    `json:"context,omitempty"`
+	Private      bool      `gorm:"not null;default:false" json:"private"`
 	AccountId    uuid.UUID `json:"account_id,omitempty"`
-	CreatedAt    time.Time `json:"created_at,omitempty"`
-	Account      *Account  `gorm:"foreignKey:AccountId" json:"account,omitempty"`
+	// Sha256 is the hex digest of the stored content, shared with every file of the same
+	// content through its Blob. Files stored before deduplication have none.
+	Sha256 string `gorm:"index" json:"sha256,omitempty"`
+	// Width and Height are set for images that went through the image pipeline.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// ScanStatus is PENDING until the malware scan ran; only CLEAN files get a URL.
+	// ScanSignature names what an INFECTED file was found to carry.
+	ScanStatus    string     `gorm:"not null;default:PENDING;index" json:"scan_status,omitempty"`
+	ScanSignature string     `json:"scan_signature,omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at,omitempty"`
+	// DeletedAt is set while the file is in the trash.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	Account   *Account       `gorm:"foreignKey:AccountId" json:"account,omitempty"`
+	Variants  []FileVariant  `gorm:"foreignKey:FileId;constraint:OnDelete:CASCADE" json:"variants,omitempty"`
+
+	// URLExpiresAt is set when Path holds a signed URL of a private file.
+	URLExpiresAt *time.Time `gorm:"-" json:"url_expires_at,omitempty"`
 }
 
 func (File) TableName() string { return "files" }
+
+// FileVariant is a scaled-down copy of an image file, fitting a Dimension by Dimension
+// box. It shares the privacy of its file.
+type FileVariant struct {
+	Id         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FileId     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_file_variant_dimension" json:"file_id"`
+	Dimension  int       `gorm:"not null;uniqueIndex:idx_file_variant_dimension" json:"dimension"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	MimeType   string    `json:"mime_type"`
+	Size       int64     `json:"size"`
+	Sha256     string    `json:"sha256,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	StorageKey string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// URLExpiresAt is set when Path holds a signed URL of a private file.
+	URLExpiresAt *time.Time `gorm:"-" json:"url_expires_at,omitempty"`
+}
+
+func (FileVariant) TableName() string { return "file_variants" }
+
+// FileDownload records a download of a file through the API, for audit. Range is the
+// Range header of a partial download and Bytes what was sent.
+type FileDownload struct {
+	Id        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FileId    uuid.UUID `gorm:"type:uuid;index" json:"file_id"`
+	AccountId uuid.UUID `gorm:"type:uuid;index" json:"account_id"`
+	Status    int       `json:"status"`
+	Range     string    `gorm:"column:byte_range" json:"range,omitempty"`
+	Bytes     int64     `json:"bytes"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (FileDownload) TableName() string { return "file_downloads" }
+
+// Blob is one stored object, shared by every file and variant of the same content and
+// privacy. RefCount counts them; the object is deleted with the last one. CorruptedAt is
+// set when the integrity check finds the object changed or gone.
+// Blob is one stored object, shared by every file and variant with the same content and
+// privacy. RefCount is how many of them point at it.
+type Blob struct {
+	Id          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Sha256      string     `gorm:"not null;uniqueIndex:idx_blob_content" json:"sha256"`
+	Private     bool       `gorm:"not null;default:false;uniqueIndex:idx_blob_content" json:"private"`
+	StorageKey  string     `gorm:"not null;uniqueIndex" json:"storage_key"`
+	URL         string     `json:"url,omitempty"`
+	MimeType    string     `json:"mime_type"`
+	Size        int64      `json:"size"`
+	RefCount    int        `gorm:"not null;default:0" json:"ref_count"`
+	VerifiedAt  *time.Time `gorm:"index" json:"verified_at,omitempty"`
+	CorruptedAt *time.Time `json:"corrupted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Blob) TableName() string { return "blobs" }
+
+// StorageUsage is what an account stores: the bytes and number of its files, trashed
+// ones included, kept up to date by every upload and permanent deletion.
+type StorageUsage struct {
+	AccountId uuid.UUID `gorm:"type:uuid;primary_key" json:"account_id"`
+	Bytes     int64     `gorm:"not null;default:0" json:"bytes"`
+	Files     int64     `gorm:"not null;default:0" json:"files"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (StorageUsage) TableName() string { return "storage_usages" }
+
+// StorageQuota overrides the default storage limits for a role or, above that, for one
+// account. Subject is the role name or the account ID. A nil limit keeps the one it
+// overrides; zero lifts the limit.
+type StorageQuota struct {
+	Id        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Scope     string    `gorm:"not null;uniqueIndex:idx_storage_quota_subject" json:"scope"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_storage_quota_subject" json:"subject"`
+	MaxBytes  *int64    `json:"max_bytes"`
+	MaxFiles  *int64    `json:"max_files"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (StorageQuota) TableName() string { return "storage_quotas" }
+
+// UploadRule limits the uploads of a context: their size and number per request, the
+// extensions and, when listed, MIME types accepted, and the roles that may use it, every
+// role when none is listed. New objects of the context are stored under PathTemplate.
+// Private files are only handed out as short-lived signed URLs; images of a rule with
+// ProcessImages are stripped, capped and given thumbnail variants. An upload naming no
+// context goes to the rule of lowest positive Priority accepting its extension.
+type UploadRule struct {
+	Id               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Context          string    `gorm:"not null;uniqueIndex" json:"context"`
+	MaxBytes         int64     `gorm:"not null" json:"max_bytes"`
+	MaxCount         int       `gorm:"not null" json:"max_count"`
+	AllowedExts      []string  `gorm:"type:jsonb;serializer:json" json:"allowed_exts"`
+	AllowedMimeTypes []string  `gorm:"type:jsonb;serializer:json" json:"allowed_mime_types,omitempty"`
+	PathTemplate     string    `gorm:"not null" json:"path_template"`
+	Private          bool      `gorm:"not null;default:false" json:"private"`
+	ProcessImages    bool      `gorm:"not null;default:false" json:"process_images"`
+	Roles            []string  `gorm:"type:jsonb;serializer:json" json:"roles,omitempty"`
+	Priority         int       `gorm:"not null;default:0" json:"priority"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (UploadRule) TableName() string { return "upload_rules" }
+
+// TusUpload is a resumable upload in progress. Its bytes are staged on local disk until
+// Offset reaches Length; the stored file is then linked through FileId.
+type TusUpload struct {
+	Id        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AccountId uuid.UUID  `gorm:"type:uuid;index" json:"account_id"`
+	Context   string     `json:"context"`
+	Filename  string     `json:"filename"`
+	Length    int64      `json:"length"`
+	Offset    int64      `gorm:"column:upload_offset" json:"offset"`
+	Metadata  string     `json:"metadata,omitempty"`
+	FileId    *uuid.UUID `gorm:"type:uuid" json:"file_id,omitempty"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (TusUpload) TableName() string { return "tus_uploads" }
+
+// UploadSlot is a file a client uploads to storage itself, under StorageKey, before
+// completing it. FileId is set once completed; the slot is then kept until ExpiresAt so
+// a repeated completion finds the same file.
+type UploadSlot struct {
+	Id         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AccountId  uuid.UUID  `gorm:"type:uuid;index" json:"account_id"`
+	Context    string     `json:"context"`
+	Filename   string     `json:"filename"`
+	Size       int64      `json:"size"`
+	StorageKey string     `json:"-"`
+	FileId     *uuid.UUID `gorm:"type:uuid" json:"file_id,omitempty"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (UploadSlot) TableName() string { return "upload_slots" }
+
+type Product struct {
+	Id          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code        string    `gorm:"uniqueIndex" json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	IsActive    bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	Prices      []Price   `gorm:"foreignKey:ProductId" json:"prices,omitempty"`
+}
+
+func (Product) TableName() string { return "products" }
+
+// Price is one way to buy a product. EntitlementDays of 0 grants lifetime access. A price
+// with IntervalMonths is a subscription plan billed every IntervalMonths months instead,
+// and grants access for as long as the subscription lasts.
+type Price struct {
+	Id              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductId       uuid.UUID `gorm:"index" json:"product_id"`
+	Amount          float64   `json:"amount"`
+	Currency        string    `json:"currency"`
+	EntitlementDays int       `json:"entitlement_days"`
+	IntervalMonths  int       `gorm:"not null;default:0" json:"interval_months"`
+	IsActive        bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+	Product         *Product  `gorm:"foreignKey:ProductId" json:"product,omitempty"`
+}
+
+func (Price) TableName() string { return "prices" }
+
+// Entitlement is an account's access to a product. PaymentId is unique so a payment
+// grants access once however often its PAID callback is delivered.
+type Entitlement struct {
+	Id             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AccountId      uuid.UUID  `gorm:"index:idx_entitlement_account_product" json:"account_id"`
+	ProductId      uuid.UUID  `gorm:"index:idx_entitlement_account_product" json:"product_id"`
+	PaymentId      *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"payment_id,omitempty"`
+	SubscriptionId *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"subscription_id,omitempty"`
+	Source         string     `json:"source"`
+	Status         string     `gorm:"index" json:"status"`
+	StartsAt       time.Time  `json:"starts_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+	Product        *Product   `gorm:"foreignKey:ProductId" json:"product,omitempty"`
+}
+
+func (Entitlement) TableName() string { return "entitlements" }
+
+// Subscription bills an account for a plan price every period. NextRenewalAt is when the
+// renewal job next looks at it and RenewalAttempts counts the renewal invoices issued for
+// the coming period. An account has at most one subscription per product that is not
+// CANCELED.
+type Subscription struct {
+	Id                 uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AccountId          uuid.UUID  `gorm:"uniqueIndex:idx_subscription_open,where:status <> 'CANCELED'" json:"account_id"`
+	ProductId          uuid.UUID  `gorm:"uniqueIndex:idx_subscription_open" json:"product_id"`
+	PriceId            uuid.UUID  `gorm:"type:uuid" json:"price_id"`
+	Status             string     `gorm:"index" json:"status"`
+	CurrentPeriodStart *time.Time `json:"current_period_start,omitempty"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd  bool       `gorm:"not null;default:false" json:"cancel_at_period_end"`
+	CanceledAt         *time.Time `json:"canceled_at,omitempty"`
+	NextRenewalAt      *time.Time `gorm:"index" json:"next_renewal_at,omitempty"`
+	RenewalAttempts    int        `gorm:"not null;default:0" json:"renewal_attempts"`
+	LatestPaymentId    *uuid.UUID `gorm:"type:uuid" json:"latest_payment_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at,omitempty"`
+	Product            *Product   `gorm:"foreignKey:ProductId" json:"product,omitempty"`
+	Price              *Price     `gorm:"foreignKey:PriceId" json:"price,omitempty"`
+}
+
+func (Subscription) TableName() string { return "subscriptions" }
+
+// Voucher is a discount code. DiscountValue is a percentage for PERCENTAGE vouchers, capped
+// at MaxDiscount when set, and an amount for FIXED ones. Zero limits mean unlimited, and a
+// voucher without Products applies to every product.
+type Voucher struct {
+	Id             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code           string     `gorm:"uniqueIndex" json:"code"`
+	Description    string     `json:"description,omitempty"`
+	DiscountType   string     `json:"discount_type"`
+	DiscountValue  float64    `json:"discount_value"`
+	MaxDiscount    float64    `json:"max_discount,omitempty"`
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	MaxPerAccount  int        `json:"max_per_account"`
+	IsActive       bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+	Products       []Product  `gorm:"many2many:voucher_products" json:"products,omitempty"`
+}
+
+func (Voucher) TableName() string { return "vouchers" }
+
+// VoucherRedemption is one use of a voucher by a checkout. It holds a slot of the
+// voucher's limits until its payment fails or expires, and is redeemed once it is PAID.
+type VoucherRedemption struct {
+	Id         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	VoucherId  uuid.UUID  `gorm:"index:idx_voucher_redemption_account" json:"voucher_id"`
+	AccountId  uuid.UUID  `gorm:"index:idx_voucher_redemption_account" json:"account_id"`
+	PaymentId  uuid.UUID  `gorm:"type:uuid;uniqueIndex" json:"payment_id"`
+	Discount   float64    `json:"discount"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	Payment    *Payment   `gorm:"foreignKey:PaymentId" json:"payment,omitempty"`
+}
+
+func (VoucherRedemption) TableName() string { return "voucher_redemptions" }
+
+type Payment struct {
+	Id             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AccountId      uuid.UUID  `gorm:"index" json:"account_id,omitempty"`
+	ExternalId     string     `gorm:"uniqueIndex" json:"external_id,omitempty"`
+	InvoiceId      string     `gorm:"index" json:"invoice_id,omitempty"`
+	InvoiceURL     string     `json:"invoice_url,omitempty"`
+	Amount         float64    `json:"amount"`
+	Currency       string     `json:"currency,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	ProductId      *uuid.UUID `gorm:"type:uuid;index" json:"product_id,omitempty"`
+	PriceId        *uuid.UUID `gorm:"type:uuid;index" json:"price_id,omitempty"`
+	VoucherId      *uuid.UUID `gorm:"type:uuid;index" json:"voucher_id,omitempty"`
+	SubscriptionId *uuid.UUID `gorm:"type:uuid;index" json:"subscription_id,omitempty"`
+	DiscountAmount float64    `gorm:"not null;default:0" json:"discount_amount"`
+	Status         string     `gorm:"index" json:"status,omitempty"`
+	RefundedAmount float64    `gorm:"not null;default:0" json:"refunded_amount"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+	Account        *Account   `gorm:"foreignKey:AccountId" json:"account,omitempty"`
+	Refunds        []Refund   `gorm:"foreignKey:PaymentId" json:"refunds,omitempty"`
+	Receipt        *Receipt   `gorm:"foreignKey:PaymentId" json:"receipt,omitempty"`
+}
+
+func (Payment) TableName() string { return "payments" }
+
+// Receipt is the numbered proof of a PAID payment. Its number is taken in the transaction
+// that marks the payment PAID, so numbers have no gaps; the PDF is rendered afterwards and
+// linked through FileId.
+type Receipt struct {
+	Id        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentId uuid.UUID  `gorm:"type:uuid;uniqueIndex" json:"payment_id"`
+	AccountId uuid.UUID  `gorm:"index" json:"account_id"`
+	Number    string     `gorm:"uniqueIndex" json:"number"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	FileId    *uuid.UUID `gorm:"type:uuid" json:"file_id,omitempty"`
+	EmailedAt *time.Time `json:"emailed_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at,omitempty"`
+	Payment   *Payment   `gorm:"foreignKey:PaymentId" json:"payment,omitempty"`
+	File      *File      `gorm:"foreignKey:FileId" json:"file,omitempty"`
+}
+
+func (Receipt) TableName() string { return "receipts" }
+
+// ReceiptSequence holds the last receipt number given out in a year.
+type ReceiptSequence struct {
+	Year       int   `gorm:"primaryKey;autoIncrement:false" json:"year"`
+	LastNumber int64 `gorm:"not null" json:"last_number"`
+}
+
+func (ReceiptSequence) TableName() string { return "receipt_sequences" }
+
+type Refund struct {
+	Id               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentId        uuid.UUID  `gorm:"index" json:"payment_id"`
+	AccountId        uuid.UUID  `gorm:"index" json:"account_id,omitempty"`
+	Amount           float64    `json:"amount"`
+	Reason           string     `json:"reason,omitempty"`
+	Status           string     `gorm:"index" json:"status,omitempty"`
+	ProviderRefundId string     `gorm:"index" json:"provider_refund_id,omitempty"`
+	FailureCode      string     `json:"failure_code,omitempty"`
+	ReviewedBy       *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewNote       string     `json:"review_note,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at,omitempty"`
+	Payment          *Payment   `gorm:"foreignKey:PaymentId" json:"payment,omitempty"`
+}
+
+func (Refund) TableName() string { return "refunds" }
+
+type PaymentCallback struct {
+	Id               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventId          string     `gorm:"uniqueIndex" json:"event_id,omitempty"`
+	EventType        string     `json:"event_type,omitempty"`
+	ReferenceId      string     `gorm:"index" json:"reference_id,omitempty"`
+	ProviderStatus   string     `json:"provider_status,omitempty"`
+	Payload          string     `gorm:"type:jsonb" json:"payload,omitempty"`
+	ProcessingStatus string     `gorm:"index" json:"processing_status,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	Attempts         int        `json:"attempts"`
+	ReceivedAt       time.Time  `json:"received_at,omitempty"`
+	ProcessedAt      *time.Time `json:"processed_at,omitempty"`
+}
+
+func (PaymentCallback) TableName() string { return "payment_callbacks" }
+
+type PaymentReconciliation struct {
+	Id               uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StartedAt        time.Time            `json:"started_at"`
+	FinishedAt       *time.Time           `json:"finished_at,omitempty"`
+	Checked          int                  `json:"checked"`
+	Applied          int                  `json:"applied"`
+	DiscrepancyCount int                  `json:"discrepancy_count"`
+	Error            string               `json:"error,omitempty"`
+	Discrepancies    []PaymentDiscrepancy `gorm:"foreignKey:ReconciliationId" json:"discrepancies,omitempty"`
+}
+
+func (PaymentReconciliation) TableName() string { return "payment_reconciliations" }
+
+type PaymentDiscrepancy struct {
+	Id               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReconciliationId uuid.UUID `gorm:"index" json:"reconciliation_id"`
+	PaymentId        uuid.UUID `gorm:"index" json:"payment_id"`
+	Kind             string    `gorm:"index" json:"kind"`
+	LocalStatus      string    `json:"local_status,omitempty"`
+	GatewayStatus    string    `json:"gateway_status,omitempty"`
+	LocalAmount      float64   `json:"local_amount"`
+	GatewayAmount    float64   `json:"gateway_amount"`
+	Resolved         bool      `json:"resolved"`
+	Detail           string    `json:"detail,omitempty"`
+	CreatedAt        time.Time `json:"created_at,omitempty"`
+}
+
+func (PaymentDiscrepancy) TableName() string { return "payment_discrepancies" }
+
+// LedgerAccount is an account of the double-entry ledger, such as revenue or the money
+// held at the gateway. Accounts are created on first use.
+type LedgerAccount struct {
+	Id        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code      string    `gorm:"uniqueIndex" json:"code"`
+	Name      string    `json:"name"`
+	Type      string    `gorm:"index" json:"type"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+func (LedgerAccount) TableName() string { return "ledger_accounts" }
+
+// JournalEntry groups the postings of one money movement. Entries are append-only: a
+// mistake is corrected by a new entry, never by changing an old one. Reference makes
+// posting the same movement twice a no-op.
+type JournalEntry struct {
+	Id          uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Reference   string          `gorm:"uniqueIndex" json:"reference"`
+	Kind        string          `gorm:"index" json:"kind"`
+	Description string          `json:"description,omitempty"`
+	PaymentId   *uuid.UUID      `gorm:"type:uuid;index" json:"payment_id,omitempty"`
+	RefundId    *uuid.UUID      `gorm:"type:uuid;index" json:"refund_id,omitempty"`
+	AccountId   *uuid.UUID      `gorm:"type:uuid;index" json:"account_id,omitempty"`
+	Currency    string          `json:"currency"`
+	PostedAt    time.Time       `gorm:"index" json:"posted_at"`
+	CreatedAt   time.Time       `json:"created_at,omitempty"`
+	Postings    []LedgerPosting `gorm:"foreignKey:JournalEntryId" json:"postings,omitempty"`
+}
+
+func (JournalEntry) TableName() string { return "journal_entries" }
+
+// LedgerPosting debits or credits one ledger account by a positive amount.
+type LedgerPosting struct {
+	Id              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	JournalEntryId  uuid.UUID      `gorm:"type:uuid;index" json:"journal_entry_id"`
+	LedgerAccountId uuid.UUID      `gorm:"type:uuid;index" json:"ledger_account_id"`
+	Direction       string         `json:"direction"`
+	Amount          float64        `gorm:"type:numeric(20,2);not null" json:"amount"`
+	Currency        string         `json:"currency"`
+	CreatedAt       time.Time      `json:"created_at,omitempty"`
+	LedgerAccount   *LedgerAccount `gorm:"foreignKey:LedgerAccountId" json:"ledger_account,omitempty"`
+}
+
+func (LedgerPosting) TableName() string { return "ledger_postings" }
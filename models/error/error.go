@@ -22,6 +22,7 @@ var (
 	INVALID_ACCOUNT_DIGITS = errors.New("Your account 3 digits is not found in account number data")
 	EXPIRED_TOKEN          = errors.New("Token expired")
 	INVALID_OTP            = errors.New("Invalid OTP code")
+	OTP_ATTEMPTS_EXCEEDED  = errors.New("Too many invalid OTP attempts, please request a new code")
 	EMAIL_ALREADY_EXISTS   = errors.New("Email already registered")
 
 	// ================= EVENT & EXAM =================
@@ -48,6 +49,12 @@ var (
 	UPLOAD_FAILED                = errors.New("Failed to upload file to storage provider")
 	PARTIAL_UPLOAD_FAILURE       = errors.New("Some files failed validation or upload")
 	INVALID_UPLOAD_CONTEXT_ERROR = errors.New("Invalid upload context")
+	INVALID_SIGNED_URL           = errors.New("Download link is invalid or has expired")
+	UPLOAD_OFFSET_MISMATCH       = errors.New("Upload offset does not match the bytes received so far")
+	UPLOAD_EXPIRED               = errors.New("Upload has expired")
+	QUOTA_EXCEEDED               = errors.New("Storage quota exceeded")
+	ARCHIVE_TOO_LARGE            = errors.New("Archive exceeds the size limit")
+	FILE_NOT_CLEAN               = errors.New("File cannot be downloaded until it is scanned clean")
 
 	// ================= ACADEMY =================
 	TITLE_REQUIRED       = errors.New("Title cannot be empty")
@@ -64,6 +71,16 @@ var (
 	PROBLEM_SET_NOT_FOUND = errors.New("problem set not found")
 	QUESTION_NOT_FOUND    = errors.New("question not found")
 
-	PAYMENT_FAILED   = errors.New("There is error during payment process try again later!")
-	PAYMENT_REQUIRED = errors.New("Payment is required to access this content")
+	PAYMENT_FAILED             = errors.New("There is error during payment process try again later!")
+	PAYMENT_REQUIRED           = errors.New("Payment is required to access this content")
+	INVALID_PAYMENT_TRANSITION = errors.New("Payment cannot move to the requested status")
+	REFUND_NOT_ALLOWED         = errors.New("Only paid payments can be refunded")
+	REFUND_AMOUNT_EXCEEDED     = errors.New("Refund amount exceeds the refundable balance of the payment")
+	INVALID_REFUND_TRANSITION  = errors.New("Refund cannot move to the requested status")
+	RECONCILIATION_RUNNING     = errors.New("A payment reconciliation is already running")
+	VOUCHER_NOT_APPLICABLE     = errors.New("Voucher is not valid for this checkout")
+	VOUCHER_EXHAUSTED          = errors.New("Voucher has reached its usage limit")
+	SUBSCRIPTION_EXISTS        = errors.New("Account already has a subscription to this product")
+	INVALID_SUBSCRIPTION_STATE = errors.New("Subscription cannot be changed in its current status")
+	RENEWAL_RUNNING            = errors.New("A subscription renewal run is already in progress")
 )
@@ -11,31 +11,79 @@ type ConfigProvider interface {
 	ProvideSupabaseConfig() config.SupabaseConfig
 	ProvideJWTConfig() config.JWTConfig
 	ProvideXenditConfig() config.XenditConfig
+	ProvidePaymentGatewayConfig() config.PaymentGatewayConfig
+	ProvideJobConfig() config.JobConfig
+	ProvideSubscriptionConfig() config.SubscriptionConfig
+	ProvideReceiptConfig() config.ReceiptConfig
+	ProvideLedgerConfig() config.LedgerConfig
+	ProvideStorageConfig() config.StorageConfig
+	ProvideTusConfig() config.TusConfig
+	ProvideImageConfig() config.ImageConfig
+	ProvideQuotaConfig() config.QuotaConfig
+	ProvideScannerConfig() config.ScannerConfig
+	ProvideOTPConfig() config.OTPConfig
+	ProvideMailConfig() config.MailConfig
 }
 
 type configProvider struct {
-	databaseConfig config.DatabaseConfig
-	envConfig      config.EnvConfig
-	uploadConfig   config.UploadConfig
-	supabaseConfig config.SupabaseConfig
-	jWTConfig      config.JWTConfig
-	xenditConfig   config.XenditConfig
+	databaseConfig       config.DatabaseConfig
+	envConfig            config.EnvConfig
+	uploadConfig         config.UploadConfig
+	supabaseConfig       config.SupabaseConfig
+	jWTConfig            config.JWTConfig
+	xenditConfig         config.XenditConfig
+	paymentGatewayConfig config.PaymentGatewayConfig
+	jobConfig            config.JobConfig
+	subscriptionConfig   config.SubscriptionConfig
+	receiptConfig        config.ReceiptConfig
+	ledgerConfig         config.LedgerConfig
+	storageConfig        config.StorageConfig
+	tusConfig            config.TusConfig
+	imageConfig          config.ImageConfig
+	quotaConfig          config.QuotaConfig
+	scannerConfig        config.ScannerConfig
+	oTPConfig            config.OTPConfig
+	mailConfig           config.MailConfig
 }
 
 func NewConfigProvider() ConfigProvider {
 	envConfig := config.NewEnvConfig("Asia / Jakarta")
 	databaseConfig := config.NewDatabaseConfig(envConfig.GetDatabaseHost(), envConfig.GetDatabaseUser(), envConfig.GetDatabasePassword(), envConfig.GetDatabaseName(), envConfig.GetDatabasePort())
-	uploadConfig := config.NewUploadConfig()
+	uploadConfig := config.NewUploadConfig(envConfig.GetUploadRulesFile())
 	supabaseConfig := config.NewSupabaseConfig(envConfig.GetSupabaseURL(), envConfig.GetSupabaseKey(), envConfig.GetSupabaseBucket())
+	storageConfig := config.NewStorageConfig(envConfig)
+	tusConfig := config.NewTusConfig(envConfig.GetTusStagingDir(), envConfig.GetTusExpiration())
+	imageConfig := config.NewImageConfig(envConfig.GetImageMaxDimension(), envConfig.GetImageVariantSizes(), envConfig.GetImageJPEGQuality())
+	quotaConfig := config.NewQuotaConfig(envConfig.GetQuotaMaxMegabytes(), envConfig.GetQuotaMaxFiles())
+	scannerConfig := config.NewScannerConfig(envConfig.GetScannerDriver(), envConfig.GetClamdAddress(), envConfig.GetClamdTimeout())
 	jWTConfig := config.NewJWTConfig(envConfig.GetSalt())
 	xenditConfig := config.NewXenditConfig(envConfig)
+	paymentGatewayConfig := config.NewPaymentGatewayConfig(envConfig.GetPaymentGateway(), envConfig.GetPaymentPublicURL(), envConfig.GetXenditCallbackToken(), envConfig.GetXenditInvoiceDuration(), envConfig.GetPaymentSimulatorDelay(), envConfig.GetPaymentSimulatorOutcome())
+	jobConfig := config.NewJobConfig(envConfig.GetPaymentReconcileInterval(), envConfig.GetPaymentReconcileAfter(), envConfig.GetSubscriptionRenewalInterval(), envConfig.GetReceiptRenderInterval(), envConfig.GetTusCleanupInterval(), envConfig.GetScanInterval(), envConfig.GetIntegrityInterval())
+	receiptConfig := config.NewReceiptConfig(envConfig.GetReceiptIssuer(), envConfig.GetReceiptEmail())
+	ledgerConfig := config.NewLedgerConfig(envConfig.GetLedgerFeePercent(), envConfig.GetLedgerFeeFixed())
+	subscriptionConfig := config.NewSubscriptionConfig(envConfig.GetSubscriptionRenewalLead(), envConfig.GetSubscriptionGracePeriod(), envConfig.GetSubscriptionRetryInterval(), envConfig.GetSubscriptionMaxAttempts())
+	oTPConfig := config.NewOTPConfig(envConfig.GetOTPSecret(), envConfig.GetEmailVerificationDuration(), envConfig.GetOTPMaxAttempts())
+	mailConfig := config.NewMailConfig(envConfig.GetSMTPHost(), envConfig.GetSMTPPort(), envConfig.GetSMTPUsername(), envConfig.GetSMTPPassword(), envConfig.GetSMTPSender())
 	return &configProvider{
-		databaseConfig: databaseConfig,
-		envConfig:      envConfig,
-		uploadConfig:   uploadConfig,
-		supabaseConfig: supabaseConfig,
-		jWTConfig:      jWTConfig,
-		xenditConfig:   xenditConfig,
+		databaseConfig:       databaseConfig,
+		envConfig:            envConfig,
+		uploadConfig:         uploadConfig,
+		supabaseConfig:       supabaseConfig,
+		jWTConfig:            jWTConfig,
+		xenditConfig:         xenditConfig,
+		paymentGatewayConfig: paymentGatewayConfig,
+		jobConfig:            jobConfig,
+		subscriptionConfig:   subscriptionConfig,
+		receiptConfig:        receiptConfig,
+		ledgerConfig:         ledgerConfig,
+		storageConfig:        storageConfig,
+		tusConfig:            tusConfig,
+		imageConfig:          imageConfig,
+		quotaConfig:          quotaConfig,
+		scannerConfig:        scannerConfig,
+		oTPConfig:            oTPConfig,
+		mailConfig:           mailConfig,
 	}
 }
 
@@ -62,3 +110,51 @@ func (c *configProvider) ProvideJWTConfig() config.JWTConfig {
 func (c *configProvider) ProvideXenditConfig() config.XenditConfig {
 	return c.xenditConfig
 }
+
+func (c *configProvider) ProvidePaymentGatewayConfig() config.PaymentGatewayConfig {
+	return c.paymentGatewayConfig
+}
+
+func (c *configProvider) ProvideJobConfig() config.JobConfig {
+	return c.jobConfig
+}
+
+func (c *configProvider) ProvideSubscriptionConfig() config.SubscriptionConfig {
+	return c.subscriptionConfig
+}
+
+func (c *configProvider) ProvideReceiptConfig() config.ReceiptConfig {
+	return c.receiptConfig
+}
+
+func (c *configProvider) ProvideLedgerConfig() config.LedgerConfig {
+	return c.ledgerConfig
+}
+
+func (c *configProvider) ProvideStorageConfig() config.StorageConfig {
+	return c.storageConfig
+}
+
+func (c *configProvider) ProvideTusConfig() config.TusConfig {
+	return c.tusConfig
+}
+
+func (c *configProvider) ProvideImageConfig() config.ImageConfig {
+	return c.imageConfig
+}
+
+func (c *configProvider) ProvideQuotaConfig() config.QuotaConfig {
+	return c.quotaConfig
+}
+
+func (c *configProvider) ProvideScannerConfig() config.ScannerConfig {
+	return c.scannerConfig
+}
+
+func (c *configProvider) ProvideOTPConfig() config.OTPConfig {
+	return c.oTPConfig
+}
+
+func (c *configProvider) ProvideMailConfig() config.MailConfig {
+	return c.mailConfig
+}
@@ -7,10 +7,28 @@ type ControllerProvider interface {
 	ProvideAuthenticationController() controllers.AuthenticationController
 	ProvideEmailVerificationController() controllers.EmailVerificationController
 	ProvidePaymentCallbackController() controllers.PaymentCallbackController
+	ProvidePaymentController() controllers.PaymentController
+	ProvidePaymentSimulatorController() controllers.PaymentSimulatorController
+	ProvideRefundController() controllers.RefundController
+	ProvidePaymentReconciliationController() controllers.PaymentReconciliationController
+	ProvideProductController() controllers.ProductController
+	ProvideEntitlementController() controllers.EntitlementController
+	ProvideVoucherController() controllers.VoucherController
+	ProvideSubscriptionController() controllers.SubscriptionController
+	ProvideReceiptController() controllers.ReceiptController
+	ProvideLedgerController() controllers.LedgerController
+	ProvidePaymentAdminController() controllers.PaymentAdminController
 	ProvideForgotPasswordController() controllers.ForgotPasswordController
 	ProvideOptionController() controllers.OptionController
 	ProvideRegionController() controllers.RegionController
 	ProvideUploadController() controllers.UploadController
+	ProvideStorageController() controllers.StorageController
+	ProvideFileController() controllers.FileController
+	ProvideTusController() controllers.TusController
+	ProvideDirectUploadController() controllers.DirectUploadController
+	ProvideDownloadController() controllers.DownloadController
+	ProvideQuotaController() controllers.QuotaController
+	ProvideUploadRuleController() controllers.UploadRuleController
 }
 
 type controllerProvider struct {
@@ -18,10 +36,28 @@ type controllerProvider struct {
 	authenticationController    controllers.AuthenticationController
 	emailVerificationController controllers.EmailVerificationController
 	paymentCallbackController   controllers.PaymentCallbackController
+	paymentController           controllers.PaymentController
+	paymentSimulatorController  controllers.PaymentSimulatorController
+	refundController            controllers.RefundController
+	reconciliationController    controllers.PaymentReconciliationController
+	productController           controllers.ProductController
+	entitlementController       controllers.EntitlementController
+	voucherController           controllers.VoucherController
+	subscriptionController      controllers.SubscriptionController
+	receiptController           controllers.ReceiptController
+	ledgerController            controllers.LedgerController
+	paymentAdminController      controllers.PaymentAdminController
 	forgotPasswordController    controllers.ForgotPasswordController
 	optionController            controllers.OptionController
 	regionController            controllers.RegionController
 	uploadController            controllers.UploadController
+	storageController           controllers.StorageController
+	fileController              controllers.FileController
+	tusController               controllers.TusController
+	directUploadController      controllers.DirectUploadController
+	downloadController          controllers.DownloadController
+	quotaController             controllers.QuotaController
+	uploadRuleController        controllers.UploadRuleController
 }
 
 func NewControllerProvider(servicesProvider ServicesProvider) ControllerProvider {
@@ -30,21 +66,57 @@ func NewControllerProvider(servicesProvider ServicesProvider) ControllerProvider
 	authenticationController := controllers.NewAuthenticationController(servicesProvider.ProvideAccountService(), servicesProvider.ProvideExternalAuthService())
 	emailVerificationController := controllers.NewEmailVerificationController(servicesProvider.ProvideEmailVerificationService())
 	paymentCallbackController := controllers.NewPaymentCallbackController(
-		servicesProvider.ProvidePaymentService(),
+		servicesProvider.ProvidePaymentCallbackService(),
 	)
+	paymentController := controllers.NewPaymentController(servicesProvider.ProvidePaymentService())
+	paymentSimulatorController := controllers.NewPaymentSimulatorController(servicesProvider.ProvidePaymentGateway())
+	refundController := controllers.NewRefundController(servicesProvider.ProvideRefundService())
+	reconciliationController := controllers.NewPaymentReconciliationController(servicesProvider.ProvidePaymentReconciliationService())
+	productController := controllers.NewProductController(servicesProvider.ProvideProductService(), servicesProvider.ProvideCheckoutService())
+	entitlementController := controllers.NewEntitlementController(servicesProvider.ProvideEntitlementService())
+	voucherController := controllers.NewVoucherController(servicesProvider.ProvideVoucherService())
+	subscriptionController := controllers.NewSubscriptionController(servicesProvider.ProvideSubscriptionService())
+	receiptController := controllers.NewReceiptController(servicesProvider.ProvideReceiptService())
+	ledgerController := controllers.NewLedgerController(servicesProvider.ProvideLedgerService())
+	paymentAdminController := controllers.NewPaymentAdminController(servicesProvider.ProvidePaymentAdminService())
 	forgotPasswordController := controllers.NewForgotPasswordController(servicesProvider.ProvideForgotPasswordService())
 	optionController := controllers.NewOptionController(servicesProvider.ProvideOptionService())
 	regionController := controllers.NewRegionController(servicesProvider.ProvideRegionService())
 	uploadController := controllers.NewUploadController(servicesProvider.ProvideUploadService())
+	storageController := controllers.NewStorageController(servicesProvider.ProvideStorageService(), servicesProvider.ProvideFileService())
+	fileController := controllers.NewFileController(servicesProvider.ProvideFileService())
+	tusController := controllers.NewTusController(servicesProvider.ProvideTusService())
+	directUploadController := controllers.NewDirectUploadController(servicesProvider.ProvideDirectUploadService())
+	downloadController := controllers.NewDownloadController(servicesProvider.ProvideDownloadService())
+	quotaController := controllers.NewQuotaController(servicesProvider.ProvideQuotaService())
+	uploadRuleController := controllers.NewUploadRuleController(servicesProvider.ProvideUploadRuleService())
 	return &controllerProvider{
 		accountDetailController:     accountDetailController,
 		authenticationController:    authenticationController,
 		emailVerificationController: emailVerificationController,
 		paymentCallbackController:   paymentCallbackController,
+		paymentController:           paymentController,
+		paymentSimulatorController:  paymentSimulatorController,
+		refundController:            refundController,
+		reconciliationController:    reconciliationController,
+		productController:           productController,
+		entitlementController:       entitlementController,
+		voucherController:           voucherController,
+		subscriptionController:      subscriptionController,
+		receiptController:           receiptController,
+		ledgerController:            ledgerController,
+		paymentAdminController:      paymentAdminController,
 		forgotPasswordController:    forgotPasswordController,
 		optionController:            optionController,
 		regionController:            regionController,
 		uploadController:            uploadController,
+		storageController:           storageController,
+		fileController:              fileController,
+		tusController:               tusController,
+		directUploadController:      directUploadController,
+		downloadController:          downloadController,
+		quotaController:             quotaController,
+		uploadRuleController:        uploadRuleController,
 	}
 }
 
@@ -65,6 +137,50 @@ func (c *controllerProvider) ProvidePaymentCallbackController() controllers.Paym
 	return c.paymentCallbackController
 }
 
+func (c *controllerProvider) ProvidePaymentController() controllers.PaymentController {
+	return c.paymentController
+}
+
+func (c *controllerProvider) ProvidePaymentSimulatorController() controllers.PaymentSimulatorController {
+	return c.paymentSimulatorController
+}
+
+func (c *controllerProvider) ProvideRefundController() controllers.RefundController {
+	return c.refundController
+}
+
+func (c *controllerProvider) ProvidePaymentReconciliationController() controllers.PaymentReconciliationController {
+	return c.reconciliationController
+}
+
+func (c *controllerProvider) ProvideProductController() controllers.ProductController {
+	return c.productController
+}
+
+func (c *controllerProvider) ProvideEntitlementController() controllers.EntitlementController {
+	return c.entitlementController
+}
+
+func (c *controllerProvider) ProvideVoucherController() controllers.VoucherController {
+	return c.voucherController
+}
+
+func (c *controllerProvider) ProvideSubscriptionController() controllers.SubscriptionController {
+	return c.subscriptionController
+}
+
+func (c *controllerProvider) ProvideReceiptController() controllers.ReceiptController {
+	return c.receiptController
+}
+
+func (c *controllerProvider) ProvideLedgerController() controllers.LedgerController {
+	return c.ledgerController
+}
+
+func (c *controllerProvider) ProvidePaymentAdminController() controllers.PaymentAdminController {
+	return c.paymentAdminController
+}
+
 func (c *controllerProvider) ProvideForgotPasswordController() controllers.ForgotPasswordController {
 	return c.forgotPasswordController
 }
@@ -80,3 +196,31 @@ func (c *controllerProvider) ProvideRegionController() controllers.RegionControl
 func (c *controllerProvider) ProvideUploadController() controllers.UploadController {
 	return c.uploadController
 }
+
+func (c *controllerProvider) ProvideStorageController() controllers.StorageController {
+	return c.storageController
+}
+
+func (c *controllerProvider) ProvideFileController() controllers.FileController {
+	return c.fileController
+}
+
+func (c *controllerProvider) ProvideTusController() controllers.TusController {
+	return c.tusController
+}
+
+func (c *controllerProvider) ProvideDirectUploadController() controllers.DirectUploadController {
+	return c.directUploadController
+}
+
+func (c *controllerProvider) ProvideDownloadController() controllers.DownloadController {
+	return c.downloadController
+}
+
+func (c *controllerProvider) ProvideQuotaController() controllers.QuotaController {
+	return c.quotaController
+}
+
+func (c *controllerProvider) ProvideUploadRuleController() controllers.UploadRuleController {
+	return c.uploadRuleController
+}
@@ -4,19 +4,27 @@ import "abdanhafidz.com/go-boilerplate/middleware"
 
 type MiddlewareProvider interface {
 	ProvideAuthenticationMiddleware() middleware.AuthenticationMiddleware
+	ProvideEntitlementMiddleware() middleware.EntitlementMiddleware
 }
 
 type middlewareProvider struct {
 	authenticationMiddleware middleware.AuthenticationMiddleware
+	entitlementMiddleware    middleware.EntitlementMiddleware
 }
 
 func NewMiddlewareProvider(servicesProvider ServicesProvider) MiddlewareProvider {
 	authenticationMiddleware := middleware.NewAuthenticationMiddleware(servicesProvider.ProvideJWTService())
+	entitlementMiddleware := middleware.NewEntitlementMiddleware(servicesProvider.ProvideEntitlementService())
 	return &middlewareProvider{
 		authenticationMiddleware: authenticationMiddleware,
+		entitlementMiddleware:    entitlementMiddleware,
 	}
 }
 
 func (p *middlewareProvider) ProvideAuthenticationMiddleware() middleware.AuthenticationMiddleware {
 	return p.authenticationMiddleware
 }
+
+func (p *middlewareProvider) ProvideEntitlementMiddleware() middleware.EntitlementMiddleware {
+	return p.entitlementMiddleware
+}
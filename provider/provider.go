@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"context"
 	"log"
 
 	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"abdanhafidz.com/go-boilerplate/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,6 +16,7 @@ type AppProvider interface {
 	ProvideServices() ServicesProvider
 	ProvideControllers() ControllerProvider
 	ProvideMiddlewares() MiddlewareProvider
+	StartBackgroundJobs(ctx context.Context)
 }
 type appProvider struct {
 	ginRouter            *gin.Engine
@@ -57,10 +60,9 @@ func NewAppProvider() AppProvider {
 		// Accounts & Auth
 		&entity.Account{},
 		&entity.AccountDetail{},
-		&entity.EmailVerification{},
+		&entity.OneTimePassword{},
 		&entity.ExternalAuth{},
 		&entity.FCM{},
-		&entity.ForgotPassword{},
 
 		// Options & Regions
 		&entity.OptionCategory{},
@@ -70,6 +72,34 @@ func NewAppProvider() AppProvider {
 
 		// Files Storage
 		&entity.File{},
+		&entity.FileVariant{},
+		&entity.FileDownload{},
+		&entity.Blob{},
+		&entity.StorageUsage{},
+		&entity.StorageQuota{},
+		&entity.UploadRule{},
+		&entity.TusUpload{},
+		&entity.UploadSlot{},
+
+		// Payments
+		&entity.Product{},
+		&entity.Price{},
+		&entity.Payment{},
+		&entity.Subscription{},
+		&entity.Entitlement{},
+		&entity.Voucher{},
+		&entity.VoucherRedemption{},
+		&entity.Receipt{},
+		&entity.ReceiptSequence{},
+		&entity.Refund{},
+		&entity.PaymentCallback{},
+		&entity.PaymentReconciliation{},
+		&entity.PaymentDiscrepancy{},
+
+		// Ledger
+		&entity.LedgerAccount{},
+		&entity.JournalEntry{},
+		&entity.LedgerPosting{},
 	)
 
 	if err != nil {
@@ -78,6 +108,10 @@ func NewAppProvider() AppProvider {
 
 	log.Println("[BOOT][DB] ✅ Database migration completed")
 
+	if err := servicesProvider.ProvideUploadRuleService().Seed(context.Background()); err != nil {
+		log.Fatalf("[BOOT][UPLOAD] ❌ Seeding upload rules failed: %v", err)
+	}
+
 	log.Println("[BOOT] App Provider initialized successfully")
 
 	return &appProvider{
@@ -112,3 +146,53 @@ func (a *appProvider) ProvideControllers() ControllerProvider {
 func (a *appProvider) ProvideMiddlewares() MiddlewareProvider {
 	return a.middlewareProvider
 }
+
+// StartBackgroundJobs schedules the periodic jobs; they stop when ctx is done.
+func (a *appProvider) StartBackgroundJobs(ctx context.Context) {
+	jobConfig := a.configProvider.ProvideJobConfig()
+
+	log.Printf("[BOOT][JOB] Payment reconciliation every %s", jobConfig.GetReconcileInterval())
+	reconciliationService := a.servicesProvider.ProvidePaymentReconciliationService()
+	utils.RunEvery(ctx, "RECONCILE", jobConfig.GetReconcileInterval(), func(ctx context.Context) error {
+		_, err := reconciliationService.Run(ctx)
+		return err
+	})
+
+	log.Printf("[BOOT][JOB] Subscription renewals every %s", jobConfig.GetRenewalInterval())
+	subscriptionService := a.servicesProvider.ProvideSubscriptionService()
+	utils.RunEvery(ctx, "RENEWAL", jobConfig.GetRenewalInterval(), func(ctx context.Context) error {
+		_, err := subscriptionService.RunRenewals(ctx)
+		return err
+	})
+
+	log.Printf("[BOOT][JOB] Receipt rendering every %s", jobConfig.GetReceiptInterval())
+	receiptService := a.servicesProvider.ProvideReceiptService()
+	utils.RunEvery(ctx, "RECEIPT", jobConfig.GetReceiptInterval(), func(ctx context.Context) error {
+		_, err := receiptService.RenderPending(ctx)
+		return err
+	})
+
+	log.Printf("[BOOT][JOB] Expired tus upload cleanup every %s", jobConfig.GetTusCleanupInterval())
+	tusService := a.servicesProvider.ProvideTusService()
+	utils.RunEvery(ctx, "TUS", jobConfig.GetTusCleanupInterval(), func(ctx context.Context) error {
+		_, err := tusService.CleanupExpired(ctx)
+		return err
+	})
+
+	log.Printf("[BOOT][JOB] Expired direct upload cleanup every %s", jobConfig.GetTusCleanupInterval())
+	directUploadService := a.servicesProvider.ProvideDirectUploadService()
+	utils.RunEvery(ctx, "DIRECT", jobConfig.GetTusCleanupInterval(), func(ctx context.Context) error {
+		_, err := directUploadService.CleanupExpired(ctx)
+		return err
+	})
+
+	log.Printf("[BOOT][JOB] Storage integrity check every %s", jobConfig.GetIntegrityInterval())
+	blobService := a.servicesProvider.ProvideBlobService()
+	utils.RunEvery(ctx, "INTEGRITY", jobConfig.GetIntegrityInterval(), func(ctx context.Context) error {
+		_, _, err := blobService.VerifyIntegrity(ctx)
+		return err
+	})
+
+	log.Printf("[BOOT][JOB] Malware scan worker, polling every %s", jobConfig.GetScanInterval())
+	a.servicesProvider.ProvideScanService().Run(ctx)
+}
@@ -3,57 +3,125 @@ package provider
 import "abdanhafidz.com/go-boilerplate/repositories"
 
 type RepositoriesProvider interface {
+	ProvideTransactor() repositories.Transactor
 	ProvideAccountDetailRepository() repositories.AccountDetailRepository
 	ProvideAccountRepository() repositories.AccountRepository
-	ProvideEmailVerificationRepository() repositories.EmailVerificationRepository
 	ProvideExternalAuthRepository() repositories.ExternalAuthRepository
 	ProvideFCMRepository() repositories.FCMRepository
 	ProvideFileRepository() repositories.FileRepository
-	ProvideForgotPasswordRepository() repositories.ForgotPasswordRepository
+	ProvideTusUploadRepository() repositories.TusUploadRepository
+	ProvideBlobRepository() repositories.BlobRepository
+	ProvideQuotaRepository() repositories.QuotaRepository
+	ProvideUploadRuleRepository() repositories.UploadRuleRepository
+	ProvideUploadSlotRepository() repositories.UploadSlotRepository
+	ProvideFileDownloadRepository() repositories.FileDownloadRepository
 	ProvideOptionRepository() repositories.OptionRepository
+	ProvideOTPRepository() repositories.OTPRepository
+	ProvidePaymentRepository() repositories.PaymentRepository
+	ProvidePaymentCallbackRepository() repositories.PaymentCallbackRepository
+	ProvideRefundRepository() repositories.RefundRepository
+	ProvidePaymentReconciliationRepository() repositories.PaymentReconciliationRepository
+	ProvideProductRepository() repositories.ProductRepository
+	ProvideEntitlementRepository() repositories.EntitlementRepository
+	ProvideVoucherRepository() repositories.VoucherRepository
+	ProvideSubscriptionRepository() repositories.SubscriptionRepository
+	ProvideReceiptRepository() repositories.ReceiptRepository
+	ProvideLedgerRepository() repositories.LedgerRepository
 	ProvideRegionRepository() repositories.RegionRepository
 }
 
 type repositoriesProvider struct {
-	accountDetailRepository     repositories.AccountDetailRepository
-	accountRepository           repositories.AccountRepository
-	emailVerificationRepository repositories.EmailVerificationRepository
-	externalAuthRepository      repositories.ExternalAuthRepository
-	fCMRepository               repositories.FCMRepository
-	fileRepository              repositories.FileRepository
-	forgotPasswordRepository    repositories.ForgotPasswordRepository
-	optionRepository            repositories.OptionRepository
-	regionRepository            repositories.RegionRepository
+	transactor                repositories.Transactor
+	accountDetailRepository   repositories.AccountDetailRepository
+	accountRepository         repositories.AccountRepository
+	externalAuthRepository    repositories.ExternalAuthRepository
+	fCMRepository             repositories.FCMRepository
+	fileRepository            repositories.FileRepository
+	tusUploadRepository       repositories.TusUploadRepository
+	blobRepository            repositories.BlobRepository
+	quotaRepository           repositories.QuotaRepository
+	uploadRuleRepository      repositories.UploadRuleRepository
+	uploadSlotRepository      repositories.UploadSlotRepository
+	fileDownloadRepository    repositories.FileDownloadRepository
+	optionRepository          repositories.OptionRepository
+	oTPRepository             repositories.OTPRepository
+	paymentRepository         repositories.PaymentRepository
+	paymentCallbackRepository repositories.PaymentCallbackRepository
+	refundRepository          repositories.RefundRepository
+	reconciliationRepository  repositories.PaymentReconciliationRepository
+	productRepository         repositories.ProductRepository
+	entitlementRepository     repositories.EntitlementRepository
+	voucherRepository         repositories.VoucherRepository
+	subscriptionRepository    repositories.SubscriptionRepository
+	receiptRepository         repositories.ReceiptRepository
+	ledgerRepository          repositories.LedgerRepository
+	regionRepository          repositories.RegionRepository
 }
 
 func NewRepositoriesProvider(cfg ConfigProvider) RepositoriesProvider {
 	dbConfig := cfg.ProvideDatabaseConfig()
 	db := dbConfig.GetInstance()
 
+	transactor := repositories.NewTransactor(db)
 	accountDetailRepository := repositories.NewAccountDetailRepository(db)
 	accountRepository := repositories.NewAccountRepository(db)
-	emailVerificationRepository := repositories.NewEmailVerificationRepository(db)
 	externalAuthRepository := repositories.NewExternalAuthRepository(db)
 	fCMRepository := repositories.NewFCMRepository(db)
 	fileRepository := repositories.NewFileRepository(db)
-	forgotPasswordRepository := repositories.NewForgotPasswordRepository(db)
+	tusUploadRepository := repositories.NewTusUploadRepository(db)
+	blobRepository := repositories.NewBlobRepository(db)
+	quotaRepository := repositories.NewQuotaRepository(db)
+	uploadRuleRepository := repositories.NewUploadRuleRepository(db)
+	uploadSlotRepository := repositories.NewUploadSlotRepository(db)
+	fileDownloadRepository := repositories.NewFileDownloadRepository(db)
 	optionRepository := repositories.NewOptionRepository(db)
+	oTPRepository := repositories.NewOTPRepository(db)
+	paymentRepository := repositories.NewPaymentRepository(db)
+	paymentCallbackRepository := repositories.NewPaymentCallbackRepository(db)
+	refundRepository := repositories.NewRefundRepository(db)
+	reconciliationRepository := repositories.NewPaymentReconciliationRepository(db)
+	productRepository := repositories.NewProductRepository(db)
+	entitlementRepository := repositories.NewEntitlementRepository(db)
+	voucherRepository := repositories.NewVoucherRepository(db)
+	subscriptionRepository := repositories.NewSubscriptionRepository(db)
+	receiptRepository := repositories.NewReceiptRepository(db)
+	ledgerRepository := repositories.NewLedgerRepository(db)
 	regionRepository := repositories.NewRegionRepository(db)
 
 	return &repositoriesProvider{
-
-		accountDetailRepository:     accountDetailRepository,
-		accountRepository:           accountRepository,
-		emailVerificationRepository: emailVerificationRepository,
-		externalAuthRepository:      externalAuthRepository,
-		fCMRepository:               fCMRepository,
-		fileRepository:              fileRepository,
-		forgotPasswordRepository:    forgotPasswordRepository,
-		optionRepository:            optionRepository,
-		regionRepository:            regionRepository,
+		transactor: transactor,
+
+		accountDetailRepository:   accountDetailRepository,
+		accountRepository:         accountRepository,
+		externalAuthRepository:    externalAuthRepository,
+		fCMRepository:             fCMRepository,
+		fileRepository:            fileRepository,
+		tusUploadRepository:       tusUploadRepository,
+		blobRepository:            blobRepository,
+		quotaRepository:           quotaRepository,
+		uploadRuleRepository:      uploadRuleRepository,
+		uploadSlotRepository:      uploadSlotRepository,
+		fileDownloadRepository:    fileDownloadRepository,
+		optionRepository:          optionRepository,
+		oTPRepository:             oTPRepository,
+		paymentRepository:         paymentRepository,
+		paymentCallbackRepository: paymentCallbackRepository,
+		refundRepository:          refundRepository,
+		reconciliationRepository:  reconciliationRepository,
+		productRepository:         productRepository,
+		entitlementRepository:     entitlementRepository,
+		voucherRepository:         voucherRepository,
+		subscriptionRepository:    subscriptionRepository,
+		receiptRepository:         receiptRepository,
+		ledgerRepository:          ledgerRepository,
+		regionRepository:          regionRepository,
 	}
 }
 
+func (r *repositoriesProvider) ProvideTransactor() repositories.Transactor {
+	return r.transactor
+}
+
 func (r *repositoriesProvider) ProvideAccountDetailRepository() repositories.AccountDetailRepository {
 	return r.accountDetailRepository
 }
@@ -62,10 +130,6 @@ func (r *repositoriesProvider) ProvideAccountRepository() repositories.AccountRe
 	return r.accountRepository
 }
 
-func (r *repositoriesProvider) ProvideEmailVerificationRepository() repositories.EmailVerificationRepository {
-	return r.emailVerificationRepository
-}
-
 func (r *repositoriesProvider) ProvideExternalAuthRepository() repositories.ExternalAuthRepository {
 	return r.externalAuthRepository
 }
@@ -78,14 +142,78 @@ func (r *repositoriesProvider) ProvideFileRepository() repositories.FileReposito
 	return r.fileRepository
 }
 
-func (r *repositoriesProvider) ProvideForgotPasswordRepository() repositories.ForgotPasswordRepository {
-	return r.forgotPasswordRepository
+func (r *repositoriesProvider) ProvideTusUploadRepository() repositories.TusUploadRepository {
+	return r.tusUploadRepository
+}
+
+func (r *repositoriesProvider) ProvideBlobRepository() repositories.BlobRepository {
+	return r.blobRepository
+}
+
+func (r *repositoriesProvider) ProvideQuotaRepository() repositories.QuotaRepository {
+	return r.quotaRepository
+}
+
+func (r *repositoriesProvider) ProvideUploadRuleRepository() repositories.UploadRuleRepository {
+	return r.uploadRuleRepository
+}
+
+func (r *repositoriesProvider) ProvideUploadSlotRepository() repositories.UploadSlotRepository {
+	return r.uploadSlotRepository
+}
+
+func (r *repositoriesProvider) ProvideFileDownloadRepository() repositories.FileDownloadRepository {
+	return r.fileDownloadRepository
 }
 
 func (r *repositoriesProvider) ProvideOptionRepository() repositories.OptionRepository {
 	return r.optionRepository
 }
 
+func (r *repositoriesProvider) ProvideOTPRepository() repositories.OTPRepository {
+	return r.oTPRepository
+}
+
+func (r *repositoriesProvider) ProvidePaymentRepository() repositories.PaymentRepository {
+	return r.paymentRepository
+}
+
+func (r *repositoriesProvider) ProvidePaymentCallbackRepository() repositories.PaymentCallbackRepository {
+	return r.paymentCallbackRepository
+}
+
+func (r *repositoriesProvider) ProvideRefundRepository() repositories.RefundRepository {
+	return r.refundRepository
+}
+
+func (r *repositoriesProvider) ProvidePaymentReconciliationRepository() repositories.PaymentReconciliationRepository {
+	return r.reconciliationRepository
+}
+
+func (r *repositoriesProvider) ProvideProductRepository() repositories.ProductRepository {
+	return r.productRepository
+}
+
+func (r *repositoriesProvider) ProvideEntitlementRepository() repositories.EntitlementRepository {
+	return r.entitlementRepository
+}
+
+func (r *repositoriesProvider) ProvideVoucherRepository() repositories.VoucherRepository {
+	return r.voucherRepository
+}
+
+func (r *repositoriesProvider) ProvideSubscriptionRepository() repositories.SubscriptionRepository {
+	return r.subscriptionRepository
+}
+
+func (r *repositoriesProvider) ProvideReceiptRepository() repositories.ReceiptRepository {
+	return r.receiptRepository
+}
+
+func (r *repositoriesProvider) ProvideLedgerRepository() repositories.LedgerRepository {
+	return r.ledgerRepository
+}
+
 func (r *repositoriesProvider) ProvideRegionRepository() repositories.RegionRepository {
 	return r.regionRepository
 }
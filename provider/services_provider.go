@@ -1,60 +1,171 @@
 package provider
 
 import (
-	"abdanhafidz.com/go-boilerplate/config"
+	"log"
+
 	"abdanhafidz.com/go-boilerplate/services"
 )
 
 type ServicesProvider interface {
 	ProvideRegionService() services.RegionService
 	ProvideJWTService() services.JWTService
+	ProvidePaymentGateway() services.PaymentGateway
 	ProvidePaymentService() services.PaymentService
+	ProvidePaymentCallbackService() services.PaymentCallbackService
+	ProvideRefundService() services.RefundService
+	ProvidePaymentReconciliationService() services.PaymentReconciliationService
+	ProvideProductService() services.ProductService
+	ProvideCheckoutService() services.CheckoutService
+	ProvideEntitlementService() services.EntitlementService
+	ProvideVoucherService() services.VoucherService
+	ProvideSubscriptionService() services.SubscriptionService
+	ProvideReceiptService() services.ReceiptService
+	ProvideLedgerService() services.LedgerService
+	ProvidePaymentAdminService() services.PaymentAdminService
+	ProvideStorageService() services.StorageService
 	ProvideUploadService() services.UploadService
+	ProvideFileService() services.FileService
+	ProvideTusService() services.TusService
+	ProvideDirectUploadService() services.DirectUploadService
+	ProvideDownloadService() services.DownloadService
+	ProvideScanService() services.ScanService
+	ProvideBlobService() services.BlobService
+	ProvideQuotaService() services.QuotaService
+	ProvideUploadRuleService() services.UploadRuleService
 	ProvideOptionService() services.OptionService
 	ProvideAccountService() services.AccountService
 	ProvideForgotPasswordService() services.ForgotPasswordService
 	ProvideEmailVerificationService() services.EmailVerificationService
 	ProvideExternalAuthService() services.ExternalAuthService
+	ProvideOTPService() services.OTPService
+	ProvideMailService() services.MailService
 }
 
 type servicesProvider struct {
 	regionService            services.RegionService
 	jWTService               services.JWTService
+	paymentGateway           services.PaymentGateway
 	paymentService           services.PaymentService
+	paymentCallbackService   services.PaymentCallbackService
+	refundService            services.RefundService
+	reconciliationService    services.PaymentReconciliationService
+	productService           services.ProductService
+	checkoutService          services.CheckoutService
+	entitlementService       services.EntitlementService
+	voucherService           services.VoucherService
+	subscriptionService      services.SubscriptionService
+	receiptService           services.ReceiptService
+	ledgerService            services.LedgerService
+	paymentAdminService      services.PaymentAdminService
+	storageService           services.StorageService
 	uploadService            services.UploadService
+	fileService              services.FileService
+	tusService               services.TusService
+	directUploadService      services.DirectUploadService
+	downloadService          services.DownloadService
+	scanService              services.ScanService
+	blobService              services.BlobService
+	quotaService             services.QuotaService
+	uploadRuleService        services.UploadRuleService
 	optionService            services.OptionService
 	accountService           services.AccountService
 	forgotPasswordService    services.ForgotPasswordService
 	emailVerificationService services.EmailVerificationService
 	externalAuthService      services.ExternalAuthService
+	oTPService               services.OTPService
+	mailService              services.MailService
 }
 
 func NewServicesProvider(repoProvider RepositoriesProvider, configProvider ConfigProvider) ServicesProvider {
-	regionService := services.NewRegionService(repoProvider.ProvideRegionRepository())
-	jWTService := services.NewJWTService(configProvider.ProvideJWTConfig().GetSecretKey())
-	paymentService := services.NewPaymentService(configProvider.ProvideXenditConfig().GetClient())
-	storageService := services.NewSupabaseStorageService(configProvider.ProvideSupabaseConfig().GetURL(), configProvider.ProvideSupabaseConfig().GetServiceKey(), configProvider.ProvideSupabaseConfig().GetBucketName())
+	storageService, err := services.NewStorageService(configProvider.ProvideStorageConfig(), configProvider.ProvideSupabaseConfig())
+	if err != nil {
+		log.Fatalf("[BOOT][STORAGE] %s storage is misconfigured: %v", configProvider.ProvideStorageConfig().GetDriver(), err)
+	}
+	scanner, err := services.NewScanner(configProvider.ProvideScannerConfig())
+	if err != nil {
+		log.Fatalf("[BOOT][SCAN] %s scanner is misconfigured: %v", configProvider.ProvideScannerConfig().GetDriver(), err)
+	}
+	quotaService := services.NewQuotaService(configProvider.ProvideQuotaConfig(), repoProvider.ProvideQuotaRepository(), repoProvider.ProvideAccountRepository())
+	uploadRuleService := services.NewUploadRuleService(configProvider.ProvideUploadConfig(), repoProvider.ProvideUploadRuleRepository(), repoProvider.ProvideAccountRepository())
+	blobService := services.NewBlobService(repoProvider.ProvideTransactor(), configProvider.ProvideJobConfig(), storageService, repoProvider.ProvideBlobRepository())
+	scanService := services.NewScanService(repoProvider.ProvideTransactor(), configProvider.ProvideJobConfig(), scanner, storageService, blobService, repoProvider.ProvideFileRepository())
 	uploadService := services.NewUploadService(
+		repoProvider.ProvideTransactor(),
 		storageService,
+		blobService,
+		quotaService,
 		repoProvider.ProvideFileRepository(),
 		repoProvider.ProvideAccountRepository(),
-		config.NewUploadConfig(),
+		uploadRuleService,
+		configProvider.ProvideUploadConfig(),
+		configProvider.ProvideStorageConfig(),
+		configProvider.ProvideImageConfig(),
+		scanService,
 	)
+	fileService := services.NewFileService(repoProvider.ProvideTransactor(), storageService, blobService, quotaService, configProvider.ProvideStorageConfig(), repoProvider.ProvideFileRepository())
+	tusService := services.NewTusService(configProvider.ProvideTusConfig(), uploadRuleService, uploadService, quotaService, repoProvider.ProvideTusUploadRepository())
+	directUploadService := services.NewDirectUploadService(repoProvider.ProvideTransactor(), configProvider.ProvideStorageConfig(), storageService, uploadRuleService, uploadService, quotaService, repoProvider.ProvideUploadSlotRepository())
+	downloadService := services.NewDownloadService(configProvider.ProvideStorageConfig(), storageService, repoProvider.ProvideFileRepository(), repoProvider.ProvideFileDownloadRepository())
+	mailService := services.NewMailService(configProvider.ProvideMailConfig())
+	regionService := services.NewRegionService(repoProvider.ProvideRegionRepository())
+	jWTService := services.NewJWTService(configProvider.ProvideJWTConfig().GetSecretKey())
+	paymentGateway := services.NewPaymentGateway(configProvider.ProvidePaymentGatewayConfig(), configProvider.ProvideXenditConfig())
+	paymentService := services.NewPaymentService(repoProvider.ProvideTransactor(), paymentGateway, configProvider.ProvidePaymentGatewayConfig(), repoProvider.ProvidePaymentRepository(), repoProvider.ProvideAccountRepository())
+	productService := services.NewProductService(repoProvider.ProvideProductRepository())
+	voucherService := services.NewVoucherService(repoProvider.ProvideTransactor(), repoProvider.ProvideVoucherRepository(), repoProvider.ProvideProductRepository())
+	subscriptionService := services.NewSubscriptionService(repoProvider.ProvideTransactor(), configProvider.ProvideSubscriptionConfig(), paymentService, repoProvider.ProvideSubscriptionRepository(), repoProvider.ProvideEntitlementRepository(), repoProvider.ProvideProductRepository(), repoProvider.ProvidePaymentRepository())
+	checkoutService := services.NewCheckoutService(productService, paymentService, voucherService, subscriptionService, repoProvider.ProvidePaymentRepository())
+	entitlementService := services.NewEntitlementService(productService, checkoutService, repoProvider.ProvideProductRepository(), repoProvider.ProvideEntitlementRepository())
+	paymentService.AddListener(entitlementService)
+	paymentService.AddListener(voucherService)
+	paymentService.AddListener(subscriptionService)
+	receiptService := services.NewReceiptService(configProvider.ProvideReceiptConfig(), uploadService, storageService, mailService, repoProvider.ProvideReceiptRepository(), repoProvider.ProvideFileRepository())
+	paymentService.AddListener(receiptService)
+	ledgerService := services.NewLedgerService(configProvider.ProvideLedgerConfig(), repoProvider.ProvideLedgerRepository())
+	paymentService.AddListener(ledgerService)
+	refundService := services.NewRefundService(repoProvider.ProvideTransactor(), paymentGateway, paymentService, repoProvider.ProvidePaymentRepository(), repoProvider.ProvideRefundRepository())
+	reconciliationService := services.NewPaymentReconciliationService(configProvider.ProvideJobConfig(), paymentGateway, paymentService, repoProvider.ProvidePaymentRepository(), repoProvider.ProvidePaymentReconciliationRepository())
+	paymentAdminService := services.NewPaymentAdminService(repoProvider.ProvidePaymentRepository(), repoProvider.ProvidePaymentCallbackRepository())
+	paymentCallbackService := services.NewPaymentCallbackService(configProvider.ProvidePaymentGatewayConfig(), repoProvider.ProvidePaymentCallbackRepository(), paymentService, refundService)
 	optionService := services.NewOptionService(repoProvider.ProvideOptionRepository())
 	accountService := services.NewAccountService(jWTService, repoProvider.ProvideAccountRepository(), repoProvider.ProvideAccountDetailRepository())
-	forgotPasswordService := services.NewForgotPasswordService(jWTService, repoProvider.ProvideAccountRepository(), repoProvider.ProvideForgotPasswordRepository())
-	emailVerificationService := services.NewEmailVerificationService(accountService, repoProvider.ProvideEmailVerificationRepository())
+	oTPService := services.NewOTPService(repoProvider.ProvideOTPRepository(), configProvider.ProvideOTPConfig())
+	forgotPasswordService := services.NewForgotPasswordService(jWTService, repoProvider.ProvideAccountRepository(), oTPService, mailService, configProvider.ProvideOTPConfig())
+	emailVerificationService := services.NewEmailVerificationService(accountService, oTPService, mailService)
 	externalAuthService := services.NewExternalAuthService(jWTService, accountService, repoProvider.ProvideExternalAuthRepository())
 	return &servicesProvider{
 		regionService:            regionService,
 		jWTService:               jWTService,
+		paymentGateway:           paymentGateway,
 		paymentService:           paymentService,
+		paymentCallbackService:   paymentCallbackService,
+		refundService:            refundService,
+		reconciliationService:    reconciliationService,
+		productService:           productService,
+		checkoutService:          checkoutService,
+		entitlementService:       entitlementService,
+		voucherService:           voucherService,
+		subscriptionService:      subscriptionService,
+		receiptService:           receiptService,
+		ledgerService:            ledgerService,
+		paymentAdminService:      paymentAdminService,
+		storageService:           storageService,
 		uploadService:            uploadService,
+		fileService:              fileService,
+		tusService:               tusService,
+		directUploadService:      directUploadService,
+		downloadService:          downloadService,
+		scanService:              scanService,
+		blobService:              blobService,
+		quotaService:             quotaService,
+		uploadRuleService:        uploadRuleService,
 		optionService:            optionService,
 		accountService:           accountService,
 		forgotPasswordService:    forgotPasswordService,
 		emailVerificationService: emailVerificationService,
 		externalAuthService:      externalAuthService,
+		oTPService:               oTPService,
+		mailService:              mailService,
 	}
 }
 
@@ -66,14 +177,98 @@ func (s *servicesProvider) ProvideJWTService() services.JWTService {
 	return s.jWTService
 }
 
+func (s *servicesProvider) ProvidePaymentGateway() services.PaymentGateway {
+	return s.paymentGateway
+}
+
 func (s *servicesProvider) ProvidePaymentService() services.PaymentService {
 	return s.paymentService
 }
 
+func (s *servicesProvider) ProvidePaymentCallbackService() services.PaymentCallbackService {
+	return s.paymentCallbackService
+}
+
+func (s *servicesProvider) ProvideRefundService() services.RefundService {
+	return s.refundService
+}
+
+func (s *servicesProvider) ProvidePaymentReconciliationService() services.PaymentReconciliationService {
+	return s.reconciliationService
+}
+
+func (s *servicesProvider) ProvideProductService() services.ProductService {
+	return s.productService
+}
+
+func (s *servicesProvider) ProvideCheckoutService() services.CheckoutService {
+	return s.checkoutService
+}
+
+func (s *servicesProvider) ProvideEntitlementService() services.EntitlementService {
+	return s.entitlementService
+}
+
+func (s *servicesProvider) ProvideVoucherService() services.VoucherService {
+	return s.voucherService
+}
+
+func (s *servicesProvider) ProvideSubscriptionService() services.SubscriptionService {
+	return s.subscriptionService
+}
+
+func (s *servicesProvider) ProvideReceiptService() services.ReceiptService {
+	return s.receiptService
+}
+
+func (s *servicesProvider) ProvideLedgerService() services.LedgerService {
+	return s.ledgerService
+}
+
+func (s *servicesProvider) ProvidePaymentAdminService() services.PaymentAdminService {
+	return s.paymentAdminService
+}
+
+func (s *servicesProvider) ProvideStorageService() services.StorageService {
+	return s.storageService
+}
+
 func (s *servicesProvider) ProvideUploadService() services.UploadService {
 	return s.uploadService
 }
 
+func (s *servicesProvider) ProvideFileService() services.FileService {
+	return s.fileService
+}
+
+func (s *servicesProvider) ProvideTusService() services.TusService {
+	return s.tusService
+}
+
+func (s *servicesProvider) ProvideDirectUploadService() services.DirectUploadService {
+	return s.directUploadService
+}
+
+func (s *servicesProvider) ProvideDownloadService() services.DownloadService {
+	return s.downloadService
+}
+
+func (s *servicesProvider) ProvideScanService() services.ScanService {
+	return s.scanService
+}
+
+func (s *servicesProvider) ProvideBlobService() services.BlobService {
+	return s.blobService
+}
+
+func (s *servicesProvider) ProvideQuotaService() services.QuotaService {
+	return s.quotaService
+}
+
+func (s *servicesProvider) ProvideUploadRuleService() services.UploadRuleService {
+	return s.uploadRuleService
+}
+
 func (s *servicesProvider) ProvideOptionService() services.OptionService {
 	return s.optionService
 }
@@ -93,3 +288,11 @@ func (s *servicesProvider) ProvideEmailVerificationService() services.EmailVerif
 func (s *servicesProvider) ProvideExternalAuthService() services.ExternalAuthService {
 	return s.externalAuthService
 }
+
+func (s *servicesProvider) ProvideOTPService() services.OTPService {
+	return s.oTPService
+}
+
+func (s *servicesProvider) ProvideMailService() services.MailService {
+	return s.mailService
+}
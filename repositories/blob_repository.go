@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlobRepository interface {
+	LockContent(ctx context.Context, sha256 string) error
+	GetByContent(ctx context.Context, sha256 string, private bool) (entity.Blob, error)
+	GetByKey(ctx context.Context, storageKey string) (entity.Blob, error)
+	Create(ctx context.Context, blob entity.Blob) (entity.Blob, error)
+	AddRef(ctx context.Context, id uuid.UUID, delta int) (entity.Blob, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListUnverified(ctx context.Context, since time.Time, afterId uuid.UUID, limit int) ([]entity.Blob, error)
+	MarkVerified(ctx context.Context, id uuid.UUID, corrupted bool) error
+}
+
+type blobRepository struct {
+	db *gorm.DB
+}
+
+func NewBlobRepository(db *gorm.DB) BlobRepository {
+	return &blobRepository{db: db}
+}
+
+// LockContent takes a transaction-scoped advisory lock on a digest, so storing and
+// releasing the same content never interleave. It must run inside a transaction.
+func (r *blobRepository) LockContent(ctx context.Context, sha256 string) error {
+	return conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", sha256).Error
+}
+
+func (r *blobRepository) GetByContent(ctx context.Context, sha256 string, private bool) (entity.Blob, error) {
+	var blob entity.Blob
+	err := conn(ctx, r.db).First(&blob, "sha256 = ? AND private = ?", sha256, private).Error
+	return blob, err
+}
+
+func (r *blobRepository) GetByKey(ctx context.Context, storageKey string) (entity.Blob, error) {
+	var blob entity.Blob
+	err := conn(ctx, r.db).First(&blob, "storage_key = ?", storageKey).Error
+	return blob, err
+}
+
+func (r *blobRepository) Create(ctx context.Context, blob entity.Blob) (entity.Blob, error) {
+	err := conn(ctx, r.db).Create(&blob).Error
+	return blob, err
+}
+
+// AddRef changes the reference count by delta and returns the blob as it is afterwards.
+func (r *blobRepository) AddRef(ctx context.Context, id uuid.UUID, delta int) (entity.Blob, error) {
+	var blob entity.Blob
+	err := conn(ctx, r.db).Model(&blob).
+		Clauses(clause.Returning{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ref_count":  gorm.Expr("ref_count + ?", delta),
+			"updated_at": time.Now(),
+		}).Error
+	return blob, err
+}
+
+func (r *blobRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&entity.Blob{}, "id = ?", id).Error
+}
+
+// ListUnverified pages by id through the blobs not verified since the given time.
+func (r *blobRepository) ListUnverified(ctx context.Context, since time.Time, afterId uuid.UUID, limit int) ([]entity.Blob, error) {
+	var blobs []entity.Blob
+	err := conn(ctx, r.db).
+		Where("(verified_at IS NULL OR verified_at < ?) AND id > ?", since, afterId).
+		Order("id").
+		Limit(limit).
+		Find(&blobs).Error
+	return blobs, err
+}
+
+// MarkVerified records a check. A blob found intact again loses its corruption flag.
+func (r *blobRepository) MarkVerified(ctx context.Context, id uuid.UUID, corrupted bool) error {
+	now := time.Now()
+	updates := map[string]interface{}{"verified_at": now, "corrupted_at": nil}
+	if corrupted {
+		updates["corrupted_at"] = now
+	}
+	return conn(ctx, r.db).Model(&entity.Blob{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EntitlementRepository interface {
+	Create(ctx context.Context, entitlement entity.Entitlement) (entity.Entitlement, bool, error)
+	GetActive(ctx context.Context, accountId uuid.UUID, productId uuid.UUID, at time.Time) (entity.Entitlement, error)
+	ListByAccountId(ctx context.Context, accountId uuid.UUID) ([]entity.Entitlement, error)
+	RevokeByPaymentId(ctx context.Context, paymentId uuid.UUID, at time.Time) (int64, error)
+	SaveForSubscription(ctx context.Context, entitlement entity.Entitlement) error
+	SetSubscriptionExpiry(ctx context.Context, subscriptionId uuid.UUID, expiresAt time.Time) error
+	RevokeBySubscriptionId(ctx context.Context, subscriptionId uuid.UUID, at time.Time) (int64, error)
+}
+
+type entitlementRepository struct {
+	db *gorm.DB
+}
+
+func NewEntitlementRepository(db *gorm.DB) EntitlementRepository {
+	return &entitlementRepository{db: db}
+}
+
+// Create inserts the entitlement unless one already exists for its payment, and reports
+// whether it inserted.
+func (r *entitlementRepository) Create(ctx context.Context, entitlement entity.Entitlement) (entity.Entitlement, bool, error) {
+	tx := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&entitlement)
+	if tx.Error != nil {
+		return entity.Entitlement{}, false, tx.Error
+	}
+	return entitlement, tx.RowsAffected == 1, nil
+}
+
+// GetActive returns the entitlement that lasts longest among the ones valid at the given time.
+func (r *entitlementRepository) GetActive(ctx context.Context, accountId uuid.UUID, productId uuid.UUID, at time.Time) (entity.Entitlement, error) {
+	var entitlement entity.Entitlement
+	err := conn(ctx, r.db).
+		Where("account_id = ? AND product_id = ? AND status = ?", accountId, productId, entity.EntitlementStatusActive).
+		Where("starts_at <= ? AND (expires_at IS NULL OR expires_at > ?)", at, at).
+		Order("expires_at DESC NULLS FIRST").
+		First(&entitlement).Error
+	if err != nil {
+		return entity.Entitlement{}, err
+	}
+	return entitlement, nil
+}
+
+func (r *entitlementRepository) ListByAccountId(ctx context.Context, accountId uuid.UUID) ([]entity.Entitlement, error) {
+	var list []entity.Entitlement
+	err := conn(ctx, r.db).
+		Preload("Product").
+		Where("account_id = ?", accountId).
+		Order("created_at DESC").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *entitlementRepository) RevokeByPaymentId(ctx context.Context, paymentId uuid.UUID, at time.Time) (int64, error) {
+	tx := conn(ctx, r.db).
+		Model(&entity.Entitlement{}).
+		Where("payment_id = ? AND status = ?", paymentId, entity.EntitlementStatusActive).
+		Updates(map[string]interface{}{"status": entity.EntitlementStatusRevoked, "revoked_at": at})
+	return tx.RowsAffected, tx.Error
+}
+
+// SaveForSubscription creates the entitlement of a subscription or, when it exists,
+// reactivates it with the new expiry. The first StartsAt is kept.
+func (r *entitlementRepository) SaveForSubscription(ctx context.Context, entitlement entity.Entitlement) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subscription_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "expires_at", "revoked_at", "updated_at"}),
+	}).Create(&entitlement).Error
+}
+
+func (r *entitlementRepository) SetSubscriptionExpiry(ctx context.Context, subscriptionId uuid.UUID, expiresAt time.Time) error {
+	return conn(ctx, r.db).
+		Model(&entity.Entitlement{}).
+		Where("subscription_id = ? AND status = ?", subscriptionId, entity.EntitlementStatusActive).
+		Update("expires_at", expiresAt).Error
+}
+
+func (r *entitlementRepository) RevokeBySubscriptionId(ctx context.Context, subscriptionId uuid.UUID, at time.Time) (int64, error) {
+	tx := conn(ctx, r.db).
+		Model(&entity.Entitlement{}).
+		Where("subscription_id = ? AND status = ?", subscriptionId, entity.EntitlementStatusActive).
+		Updates(map[string]interface{}{"status": entity.EntitlementStatusRevoked, "revoked_at": at})
+	return tx.RowsAffected, tx.Error
+}
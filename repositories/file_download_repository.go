@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FileDownloadRepository interface {
+	Create(ctx context.Context, download entity.FileDownload) (entity.FileDownload, error)
+	ListByFile(ctx context.Context, fileId uuid.UUID, pagination entity.Pagination) ([]entity.FileDownload, int64, error)
+}
+
+type fileDownloadRepository struct {
+	db *gorm.DB
+}
+
+func NewFileDownloadRepository(db *gorm.DB) FileDownloadRepository {
+	return &fileDownloadRepository{db: db}
+}
+
+func (r *fileDownloadRepository) Create(ctx context.Context, download entity.FileDownload) (entity.FileDownload, error) {
+	err := conn(ctx, r.db).Create(&download).Error
+	return download, err
+}
+
+// ListByFile returns the downloads of a file newest first.
+func (r *fileDownloadRepository) ListByFile(ctx context.Context, fileId uuid.UUID, pagination entity.Pagination) ([]entity.FileDownload, int64, error) {
+	query := conn(ctx, r.db).Model(&entity.FileDownload{}).Where("file_id = ?", fileId).Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var downloads []entity.FileDownload
+	err := query.
+		Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&downloads).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return downloads, total, nil
+}
@@ -2,11 +2,15 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
 	entity "abdanhafidz.com/go-boilerplate/models/entity"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 )
@@ -14,6 +18,25 @@ import (
 type FileRepository interface {
 	Create(ctx context.Context, file *entity.File) error
 	FindByID(ctx context.Context, id uuid.UUID) (*entity.File, error)
+	FindByIDWithTrashed(ctx context.Context, id uuid.UUID) (*entity.File, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.File, error)
+	List(ctx context.Context, filter dto.FileFilter, pagination entity.Pagination) ([]entity.File, int64, error)
+	UpdateMetadata(ctx context.Context, id uuid.UUID, displayName *string, tags *[]string) error
+	Trash(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByScanStatus(ctx context.Context, status string, afterId uuid.UUID, limit int) ([]entity.File, error)
+	MarkClean(ctx context.Context, id uuid.UUID) error
+	Quarantine(ctx context.Context, id uuid.UUID, storageKey string, signature string) error
+	ScanStatusesByKey(ctx context.Context, storageKey string) ([]string, error)
+}
+
+// fileSorts maps the sort_by values a file listing accepts to their columns.
+var fileSorts = map[string]string{
+	"created_at":    "created_at",
+	"deleted_at":    "deleted_at",
+	"size":          "size",
+	"original_name": "original_name",
 }
 
 type fileRepository struct {
@@ -25,12 +48,12 @@ func NewFileRepository(db *gorm.DB) FileRepository {
 }
 
 func (r *fileRepository) Create(ctx context.Context, file *entity.File) error {
-	return r.db.WithContext(ctx).Create(file).Error
+	return conn(ctx, r.db).Create(file).Error
 }
 
 func (r *fileRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.File, error) {
 	var file entity.File
-	result := r.db.WithContext(ctx).First(&file, "id = ?", id)
+	result := withVariants(r.db.WithContext(ctx)).First(&file, "id = ?", id)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -39,4 +62,178 @@ func (r *fileRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Fi
 		return nil, result.Error
 	}
 	return &file, nil
-}
\ No newline at end of file
+}
+
+// FindByIDWithTrashed also finds a file that is in the trash.
+func (r *fileRepository) FindByIDWithTrashed(ctx context.Context, id uuid.UUID) (*entity.File, error) {
+	var file entity.File
+	err := withVariants(conn(ctx, r.db).Unscoped()).First(&file, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http_error.NOT_FOUND_ERROR
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FindByIDs returns the live files among ids, in no particular order; missing and
+// trashed ones are left out.
+func (r *fileRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.File, error) {
+	var files []entity.File
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&files).Error
+	return files, err
+}
+
+// List pages through the files matching the filter, either live ones or the trash.
+// Search matches the original and the display name.
+func (r *fileRepository) List(ctx context.Context, filter dto.FileFilter, pagination entity.Pagination) ([]entity.File, int64, error) {
+	query := conn(ctx, r.db).Model(&entity.File{})
+	if filter.Trashed {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.AccountId != nil {
+		query = query.Where("account_id = ?", *filter.AccountId)
+	}
+	if filter.Context != "" {
+		query = query.Where("context = ?", filter.Context)
+	}
+	if filter.MimeType != "" {
+		// A type without subtype, such as "image", matches all of its subtypes.
+		if strings.Contains(filter.MimeType, "/") {
+			query = query.Where("mime_type = ?", filter.MimeType)
+		} else {
+			query = query.Where("mime_type LIKE ?", filter.MimeType+"/%")
+		}
+	}
+	if filter.ScanStatus != "" {
+		query = query.Where("scan_status = ?", filter.ScanStatus)
+	}
+	query = withPeriod(query, "created_at", filter.Period)
+	if filter.Corrupted {
+		query = query.Where("storage_key IN (SELECT storage_key FROM blobs WHERE corrupted_at IS NOT NULL)")
+	}
+	if filter.Tag != "" {
+		tag, _ := json.Marshal([]string{filter.Tag})
+		query = query.Where("tags @> ?::jsonb", string(tag))
+	}
+	if pagination.Search != "" {
+		search := "%" + pagination.Search + "%"
+		query = query.Where("original_name ILIKE ? OR display_name ILIKE ?", search, search)
+	}
+
+	// A new session lets the count and the page share the filter without leaking into each other.
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := fileSorts[pagination.SortBy]
+	if !ok {
+		column = fileSorts["created_at"]
+	}
+	direction := "DESC"
+	if strings.EqualFold(pagination.Order, "asc") {
+		direction = "ASC"
+	}
+	var files []entity.File
+	err := withVariants(query).
+		Order(column + " " + direction + " NULLS LAST, id " + direction).
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&files).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return files, total, nil
+}
+
+// UpdateMetadata sets the display name and tags that are given; nil leaves one as is.
+func (r *fileRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, displayName *string, tags *[]string) error {
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if displayName != nil {
+		updates["display_name"] = *displayName
+	}
+	if tags != nil {
+		encoded, err := json.Marshal(*tags)
+		if err != nil {
+			return err
+		}
+		updates["tags"] = string(encoded)
+	}
+	return conn(ctx, r.db).Unscoped().Model(&entity.File{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Trash moves a file to the trash; its object stays in storage until it is deleted.
+func (r *fileRepository) Trash(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&entity.File{}, "id = ?", id).Error
+}
+
+func (r *fileRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Unscoped().Model(&entity.File{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error
+}
+
+// Delete removes the row for good, whether or not it is in the trash. Its variants go
+// with it through the foreign key.
+func (r *fileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Unscoped().Delete(&entity.File{}, "id = ?", id).Error
+}
+
+// ListByScanStatus pages by id through the files, trashed ones included, with a scan
+// status, starting after afterId.
+func (r *fileRepository) ListByScanStatus(ctx context.Context, status string, afterId uuid.UUID, limit int) ([]entity.File, error) {
+	var files []entity.File
+	err := withVariants(conn(ctx, r.db).Unscoped()).
+		Where("scan_status = ? AND id > ?", status, afterId).
+		Order("id").
+		Limit(limit).
+		Find(&files).Error
+	return files, err
+}
+
+// MarkClean records a clean scan of a file still pending.
+func (r *fileRepository) MarkClean(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Unscoped().Model(&entity.File{}).
+		Where("id = ? AND scan_status = ?", id, entity.ScanStatusPending).
+		Updates(map[string]interface{}{"scan_status": entity.ScanStatusClean, "scanned_at": time.Now()}).Error
+}
+
+// Quarantine marks a file infected, points it at its quarantined copy and drops its
+// variants.
+func (r *fileRepository) Quarantine(ctx context.Context, id uuid.UUID, storageKey string, signature string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&entity.File{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"scan_status":    entity.ScanStatusInfected,
+			"scan_signature": signature,
+			"scanned_at":     time.Now(),
+			"storage_key":    storageKey,
+			"path":           "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("file_id = ?", id).Delete(&entity.FileVariant{}).Error
+	})
+}
+
+// ScanStatusesByKey returns the scan statuses of the files, trashed ones included, stored
+// under storageKey either themselves or as one of their variants.
+func (r *fileRepository) ScanStatusesByKey(ctx context.Context, storageKey string) ([]string, error) {
+	var statuses []string
+	err := conn(ctx, r.db).Unscoped().Model(&entity.File{}).
+		Where("storage_key = ? OR id IN (?)", storageKey,
+			conn(ctx, r.db).Model(&entity.FileVariant{}).Select("file_id").Where("storage_key = ?", storageKey)).
+		Distinct().
+		Pluck("scan_status", &statuses).Error
+	return statuses, err
+}
+
+// withVariants loads the image variants of the files, smallest first.
+func withVariants(db *gorm.DB) *gorm.DB {
+	return db.Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("dimension")
+	})
+}
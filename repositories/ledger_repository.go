@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerRepository reads and appends to the double-entry ledger. There is deliberately no
+// way to update or delete entries.
+type LedgerRepository interface {
+	EnsureAccount(ctx context.Context, account entity.LedgerAccount) (entity.LedgerAccount, error)
+	ListAccounts(ctx context.Context) ([]entity.LedgerAccount, error)
+	CreateEntry(ctx context.Context, entry entity.JournalEntry) (entity.JournalEntry, bool, error)
+	ListEntries(ctx context.Context, pagination entity.Pagination, period dto.Period, kind string) ([]entity.JournalEntry, int64, error)
+	Balances(ctx context.Context, period dto.Period) ([]dto.LedgerBalance, error)
+	CurrencyTotals(ctx context.Context) ([]dto.LedgerCurrencyTotal, error)
+	UnbalancedEntries(ctx context.Context, limit int) ([]dto.LedgerUnbalancedEntry, error)
+	ExportPostings(ctx context.Context, period dto.Period, fn func(row dto.LedgerExportRow) error) error
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// EnsureAccount returns the account with the code, creating it on first use.
+func (r *ledgerRepository) EnsureAccount(ctx context.Context, account entity.LedgerAccount) (entity.LedgerAccount, error) {
+	err := conn(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+		Create(&account).Error
+	if err != nil {
+		return entity.LedgerAccount{}, err
+	}
+	var existing entity.LedgerAccount
+	if err := conn(ctx, r.db).First(&existing, "code = ?", account.Code).Error; err != nil {
+		return entity.LedgerAccount{}, err
+	}
+	return existing, nil
+}
+
+func (r *ledgerRepository) ListAccounts(ctx context.Context) ([]entity.LedgerAccount, error) {
+	var list []entity.LedgerAccount
+	if err := conn(ctx, r.db).Order("code ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateEntry appends an entry with its postings and reports whether it did; an entry
+// with the same reference already posted is left alone.
+func (r *ledgerRepository) CreateEntry(ctx context.Context, entry entity.JournalEntry) (entity.JournalEntry, bool, error) {
+	postings := entry.Postings
+	entry.Postings = nil
+	tx := conn(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "reference"}}, DoNothing: true}).
+		Create(&entry)
+	if tx.Error != nil || tx.RowsAffected == 0 {
+		return entity.JournalEntry{}, false, tx.Error
+	}
+	for i := range postings {
+		postings[i].JournalEntryId = entry.Id
+	}
+	if err := conn(ctx, r.db).Omit("LedgerAccount").Create(&postings).Error; err != nil {
+		return entity.JournalEntry{}, false, err
+	}
+	entry.Postings = postings
+	return entry, true, nil
+}
+
+func (r *ledgerRepository) ListEntries(ctx context.Context, pagination entity.Pagination, period dto.Period, kind string) ([]entity.JournalEntry, int64, error) {
+	query := withPeriod(conn(ctx, r.db).Model(&entity.JournalEntry{}), "posted_at", period)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if pagination.Search != "" {
+		query = query.Where("reference ILIKE ?", "%"+pagination.Search+"%")
+	}
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []entity.JournalEntry
+	err := query.
+		Preload("Postings.LedgerAccount").
+		Order("posted_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// Balances sums the postings of every account per currency. Debit-normal accounts
+// (assets, expenses, contra revenue) count debits as positive, the others credits.
+func (r *ledgerRepository) Balances(ctx context.Context, period dto.Period) ([]dto.LedgerBalance, error) {
+	var list []dto.LedgerBalance
+	query := conn(ctx, r.db).
+		Table("ledger_postings p").
+		Joins("JOIN ledger_accounts a ON a.id = p.ledger_account_id").
+		Joins("JOIN journal_entries e ON e.id = p.journal_entry_id")
+	err := withPeriod(query, "e.posted_at", period).
+		Select(`a.code, a.name, a.type, p.currency,
+			COALESCE(SUM(CASE WHEN p.direction = ? THEN p.amount END), 0) AS debit,
+			COALESCE(SUM(CASE WHEN p.direction = ? THEN p.amount END), 0) AS credit`,
+			entity.LedgerDebit, entity.LedgerCredit).
+		Group("a.code, a.name, a.type, p.currency").
+		Order("a.code ASC, p.currency ASC").
+		Scan(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		switch list[i].Type {
+		case entity.LedgerAccountTypeAsset, entity.LedgerAccountTypeExpense, entity.LedgerAccountTypeContraRevenue:
+			list[i].Balance = list[i].Debit - list[i].Credit
+		default:
+			list[i].Balance = list[i].Credit - list[i].Debit
+		}
+	}
+	return list, nil
+}
+
+func (r *ledgerRepository) CurrencyTotals(ctx context.Context) ([]dto.LedgerCurrencyTotal, error) {
+	var list []dto.LedgerCurrencyTotal
+	err := conn(ctx, r.db).
+		Table("ledger_postings").
+		Select(`currency,
+			COALESCE(SUM(CASE WHEN direction = ? THEN amount END), 0) AS debit,
+			COALESCE(SUM(CASE WHEN direction = ? THEN amount END), 0) AS credit,
+			COALESCE(SUM(CASE WHEN direction = ? THEN amount ELSE -amount END), 0) AS difference`,
+			entity.LedgerDebit, entity.LedgerCredit, entity.LedgerDebit).
+		Group("currency").
+		Order("currency ASC").
+		Scan(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UnbalancedEntries lists entries whose debits and credits differ, including entries
+// without postings. The sums are numeric, so the comparison is exact.
+func (r *ledgerRepository) UnbalancedEntries(ctx context.Context, limit int) ([]dto.LedgerUnbalancedEntry, error) {
+	var list []dto.LedgerUnbalancedEntry
+	err := conn(ctx, r.db).
+		Table("journal_entries e").
+		Joins("LEFT JOIN ledger_postings p ON p.journal_entry_id = e.id").
+		Select(`e.id AS journal_entry_id, e.reference,
+			COALESCE(SUM(CASE WHEN p.direction = ? THEN p.amount END), 0) AS debit,
+			COALESCE(SUM(CASE WHEN p.direction = ? THEN p.amount END), 0) AS credit`,
+			entity.LedgerDebit, entity.LedgerCredit).
+		Group("e.id, e.reference").
+		Having(`COUNT(p.id) = 0 OR COALESCE(SUM(CASE WHEN p.direction = ? THEN p.amount END), 0)
+			<> COALESCE(SUM(CASE WHEN p.direction = ? THEN p.amount END), 0)`,
+			entity.LedgerDebit, entity.LedgerCredit).
+		Order("e.reference ASC").
+		Limit(limit).
+		Scan(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ExportPostings walks the postings of the period in posting order without loading them
+// all at once.
+func (r *ledgerRepository) ExportPostings(ctx context.Context, period dto.Period, fn func(row dto.LedgerExportRow) error) error {
+	query := conn(ctx, r.db).
+		Table("ledger_postings p").
+		Joins("JOIN ledger_accounts a ON a.id = p.ledger_account_id").
+		Joins("JOIN journal_entries e ON e.id = p.journal_entry_id")
+	rows, err := withPeriod(query, "e.posted_at", period).
+		Select(`e.posted_at, e.reference, e.kind, e.description, e.payment_id, e.refund_id,
+			a.code AS account_code, a.name AS account_name, p.direction, p.amount, p.currency`).
+		Order("e.posted_at ASC, e.reference ASC, p.direction DESC, a.code ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	db := conn(ctx, r.db)
+	for rows.Next() {
+		var row dto.LedgerExportRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func withPeriod(query *gorm.DB, column string, period dto.Period) *gorm.DB {
+	if period.From != nil {
+		query = query.Where(column+" >= ?", *period.From)
+	}
+	if period.To != nil {
+		query = query.Where(column+" < ?", *period.To)
+	}
+	return query
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OTPRepository interface {
+	Create(ctx context.Context, otp entity.OneTimePassword) (entity.OneTimePassword, error)
+	GetActiveByAccountAndPurpose(ctx context.Context, accountID uuid.UUID, purpose string) (entity.OneTimePassword, error)
+	ExpireActiveByAccountAndPurpose(ctx context.Context, accountID uuid.UUID, purpose string) error
+	IncrementAttempts(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
+	Consume(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
+	MarkExpired(ctx context.Context, id uuid.UUID) error
+}
+
+type otpRepository struct {
+	db *gorm.DB
+}
+
+func NewOTPRepository(db *gorm.DB) OTPRepository {
+	return &otpRepository{db: db}
+}
+
+func (r *otpRepository) Create(ctx context.Context, otp entity.OneTimePassword) (entity.OneTimePassword, error) {
+	if err := r.db.WithContext(ctx).Create(&otp).Error; err != nil {
+		return entity.OneTimePassword{}, err
+	}
+	return otp, nil
+}
+
+func (r *otpRepository) GetActiveByAccountAndPurpose(ctx context.Context, accountID uuid.UUID, purpose string) (entity.OneTimePassword, error) {
+	var otp entity.OneTimePassword
+	if err := r.db.WithContext(ctx).
+		Where("account_id = ? AND purpose = ? AND is_expired = ?", accountID, purpose, false).
+		Order("created_at DESC").
+		First(&otp).Error; err != nil {
+		return entity.OneTimePassword{}, err
+	}
+	return otp, nil
+}
+
+func (r *otpRepository) ExpireActiveByAccountAndPurpose(ctx context.Context, accountID uuid.UUID, purpose string) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.OneTimePassword{}).
+		Where("account_id = ? AND purpose = ? AND is_expired = ?", accountID, purpose, false).
+		Update("is_expired", true).Error
+}
+
+// IncrementAttempts counts a wrong guess only while the code is active and below the
+// cap, in the same statement that checks it, so parallel guesses cannot get past the
+// cap. It reports false when the guess was not counted.
+func (r *otpRepository) IncrementAttempts(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	tx := r.db.WithContext(ctx).
+		Model(&entity.OneTimePassword{}).
+		Where("id = ? AND attempts < ? AND is_expired = ?", id, maxAttempts, false).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return tx.RowsAffected == 1, tx.Error
+}
+
+// Consume expires the code only if it is still active and below the attempt cap, so a
+// code can be redeemed once even when two requests race on it.
+func (r *otpRepository) Consume(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	tx := r.db.WithContext(ctx).
+		Model(&entity.OneTimePassword{}).
+		Where("id = ? AND attempts < ? AND is_expired = ?", id, maxAttempts, false).
+		Update("is_expired", true)
+	return tx.RowsAffected == 1, tx.Error
+}
+
+func (r *otpRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.OneTimePassword{}).
+		Where("id = ?", id).
+		Update("is_expired", true).Error
+}
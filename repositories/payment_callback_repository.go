@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentCallbackRepository interface {
+	Create(ctx context.Context, callback entity.PaymentCallback) (entity.PaymentCallback, error)
+	Update(ctx context.Context, callback entity.PaymentCallback) (entity.PaymentCallback, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.PaymentCallback, error)
+	GetByEventId(ctx context.Context, eventId string) (entity.PaymentCallback, error)
+	ListByReferenceIds(ctx context.Context, referenceIds []string) ([]entity.PaymentCallback, error)
+}
+
+type paymentCallbackRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentCallbackRepository(db *gorm.DB) PaymentCallbackRepository {
+	return &paymentCallbackRepository{db: db}
+}
+
+func (r *paymentCallbackRepository) Create(ctx context.Context, callback entity.PaymentCallback) (entity.PaymentCallback, error) {
+	if err := conn(ctx, r.db).Create(&callback).Error; err != nil {
+		return entity.PaymentCallback{}, err
+	}
+	return callback, nil
+}
+
+func (r *paymentCallbackRepository) Update(ctx context.Context, callback entity.PaymentCallback) (entity.PaymentCallback, error) {
+	if err := conn(ctx, r.db).Save(&callback).Error; err != nil {
+		return entity.PaymentCallback{}, err
+	}
+	return callback, nil
+}
+
+func (r *paymentCallbackRepository) GetById(ctx context.Context, id uuid.UUID) (entity.PaymentCallback, error) {
+	var callback entity.PaymentCallback
+	if err := conn(ctx, r.db).First(&callback, "id = ?", id).Error; err != nil {
+		return entity.PaymentCallback{}, err
+	}
+	return callback, nil
+}
+
+func (r *paymentCallbackRepository) GetByEventId(ctx context.Context, eventId string) (entity.PaymentCallback, error) {
+	var callback entity.PaymentCallback
+	if err := conn(ctx, r.db).First(&callback, "event_id = ?", eventId).Error; err != nil {
+		return entity.PaymentCallback{}, err
+	}
+	return callback, nil
+}
+
+// ListByReferenceIds returns the callbacks about any of the references, oldest first.
+func (r *paymentCallbackRepository) ListByReferenceIds(ctx context.Context, referenceIds []string) ([]entity.PaymentCallback, error) {
+	var list []entity.PaymentCallback
+	if len(referenceIds) == 0 {
+		return list, nil
+	}
+	err := conn(ctx, r.db).
+		Where("reference_id IN ?", referenceIds).
+		Order("received_at ASC").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentReconciliationRepository interface {
+	Create(ctx context.Context, run entity.PaymentReconciliation) (entity.PaymentReconciliation, error)
+	Update(ctx context.Context, run entity.PaymentReconciliation) (entity.PaymentReconciliation, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.PaymentReconciliation, error)
+	List(ctx context.Context, pagination entity.Pagination) ([]entity.PaymentReconciliation, error)
+	CreateDiscrepancy(ctx context.Context, discrepancy entity.PaymentDiscrepancy) (entity.PaymentDiscrepancy, error)
+}
+
+type paymentReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentReconciliationRepository(db *gorm.DB) PaymentReconciliationRepository {
+	return &paymentReconciliationRepository{db: db}
+}
+
+func (r *paymentReconciliationRepository) Create(ctx context.Context, run entity.PaymentReconciliation) (entity.PaymentReconciliation, error) {
+	if err := conn(ctx, r.db).Create(&run).Error; err != nil {
+		return entity.PaymentReconciliation{}, err
+	}
+	return run, nil
+}
+
+func (r *paymentReconciliationRepository) Update(ctx context.Context, run entity.PaymentReconciliation) (entity.PaymentReconciliation, error) {
+	if err := conn(ctx, r.db).Omit("Discrepancies").Save(&run).Error; err != nil {
+		return entity.PaymentReconciliation{}, err
+	}
+	return run, nil
+}
+
+func (r *paymentReconciliationRepository) GetById(ctx context.Context, id uuid.UUID) (entity.PaymentReconciliation, error) {
+	var run entity.PaymentReconciliation
+	err := conn(ctx, r.db).
+		Preload("Discrepancies", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&run, "id = ?", id).Error
+	if err != nil {
+		return entity.PaymentReconciliation{}, err
+	}
+	return run, nil
+}
+
+// List returns runs newest first without their discrepancies.
+func (r *paymentReconciliationRepository) List(ctx context.Context, pagination entity.Pagination) ([]entity.PaymentReconciliation, error) {
+	var list []entity.PaymentReconciliation
+	err := conn(ctx, r.db).
+		Order("started_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *paymentReconciliationRepository) CreateDiscrepancy(ctx context.Context, discrepancy entity.PaymentDiscrepancy) (entity.PaymentDiscrepancy, error) {
+	if err := conn(ctx, r.db).Create(&discrepancy).Error; err != nil {
+		return entity.PaymentDiscrepancy{}, err
+	}
+	return discrepancy, nil
+}
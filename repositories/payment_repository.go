@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment entity.Payment) (entity.Payment, error)
+	SetInvoice(ctx context.Context, id uuid.UUID, invoiceId string, invoiceURL string, expiresAt time.Time) error
+	GetById(ctx context.Context, id uuid.UUID) (entity.Payment, error)
+	LockById(ctx context.Context, id uuid.UUID) (entity.Payment, error)
+	GetByInvoiceId(ctx context.Context, invoiceId string) (entity.Payment, error)
+	GetByExternalId(ctx context.Context, externalId string) (entity.Payment, error)
+	ListByAccountId(ctx context.Context, accountId uuid.UUID) ([]entity.Payment, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, from string, to string, fields map[string]interface{}) (bool, error)
+	AddRefundedAmount(ctx context.Context, id uuid.UUID, amount float64) (bool, error)
+	ListPendingCreatedBefore(ctx context.Context, before time.Time, after entity.Payment, limit int) ([]entity.Payment, error)
+	GetOpenByAccountAndPrice(ctx context.Context, accountId uuid.UUID, priceId uuid.UUID, voucherId *uuid.UUID, validUntil time.Time) (entity.Payment, error)
+	GetDetailById(ctx context.Context, id uuid.UUID) (entity.Payment, error)
+	AdminList(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination) ([]entity.Payment, int64, error)
+	AdminExport(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination, fn func(row dto.PaymentExportRow) error) error
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) Create(ctx context.Context, payment entity.Payment) (entity.Payment, error) {
+	if err := conn(ctx, r.db).Create(&payment).Error; err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+// SetInvoice records the invoice issued for a payment without touching its status.
+func (r *paymentRepository) SetInvoice(ctx context.Context, id uuid.UUID, invoiceId string, invoiceURL string, expiresAt time.Time) error {
+	return conn(ctx, r.db).
+		Model(&entity.Payment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"invoice_id": invoiceId, "invoice_url": invoiceURL, "expires_at": expiresAt}).Error
+}
+
+func (r *paymentRepository) GetById(ctx context.Context, id uuid.UUID) (entity.Payment, error) {
+	var payment entity.Payment
+	if err := conn(ctx, r.db).First(&payment, "id = ?", id).Error; err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+// LockById reads the payment with a row lock held until the surrounding transaction ends.
+func (r *paymentRepository) LockById(ctx context.Context, id uuid.UUID) (entity.Payment, error) {
+	var payment entity.Payment
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&payment, "id = ?", id).Error
+	if err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) GetByInvoiceId(ctx context.Context, invoiceId string) (entity.Payment, error) {
+	var payment entity.Payment
+	if err := conn(ctx, r.db).First(&payment, "invoice_id = ?", invoiceId).Error; err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) GetByExternalId(ctx context.Context, externalId string) (entity.Payment, error) {
+	var payment entity.Payment
+	if err := conn(ctx, r.db).First(&payment, "external_id = ?", externalId).Error; err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) ListByAccountId(ctx context.Context, accountId uuid.UUID) ([]entity.Payment, error) {
+	var list []entity.Payment
+	if err := conn(ctx, r.db).Preload("Refunds", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Where("account_id = ?", accountId).Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateStatus moves the payment from one status to another only if it is still in the
+// expected status, so concurrent updates cannot both apply.
+func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from string, to string, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	tx := conn(ctx, r.db).
+		Model(&entity.Payment{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	return tx.RowsAffected == 1, tx.Error
+}
+
+// AddRefundedAmount books a settled refund against a paid payment and moves it to
+// PARTIALLY_REFUNDED or REFUNDED. It refuses to refund more than the payment amount.
+func (r *paymentRepository) AddRefundedAmount(ctx context.Context, id uuid.UUID, amount float64) (bool, error) {
+	tx := conn(ctx, r.db).
+		Model(&entity.Payment{}).
+		Where("id = ? AND status IN ? AND refunded_amount + ? <= amount", id,
+			[]string{entity.PaymentStatusPaid, entity.PaymentStatusPartiallyRefunded}, amount).
+		Updates(map[string]interface{}{
+			"refunded_amount": gorm.Expr("refunded_amount + ?", amount),
+			"status": gorm.Expr("CASE WHEN refunded_amount + ? >= amount THEN ? ELSE ? END",
+				amount, entity.PaymentStatusRefunded, entity.PaymentStatusPartiallyRefunded),
+		})
+	return tx.RowsAffected == 1, tx.Error
+}
+
+// ListPendingCreatedBefore pages through payments still PENDING that were created before
+// the cutoff, ordered by creation. Pass the last payment of the previous page as after;
+// keyset paging stays correct while earlier rows leave PENDING.
+func (r *paymentRepository) ListPendingCreatedBefore(ctx context.Context, before time.Time, after entity.Payment, limit int) ([]entity.Payment, error) {
+	var list []entity.Payment
+	err := conn(ctx, r.db).
+		Where("status = ? AND created_at < ?", entity.PaymentStatusPending, before).
+		Where("(created_at, id) > (?, ?)", after.CreatedAt, after.Id).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetOpenByAccountAndPrice finds a pending payment of the account for the price and
+// voucher whose invoice is still payable at validUntil, so a checkout can hand out the same
+// invoice again.
+func (r *paymentRepository) GetOpenByAccountAndPrice(ctx context.Context, accountId uuid.UUID, priceId uuid.UUID, voucherId *uuid.UUID, validUntil time.Time) (entity.Payment, error) {
+	var payment entity.Payment
+	query := conn(ctx, r.db).
+		Where("account_id = ? AND price_id = ? AND status = ?", accountId, priceId, entity.PaymentStatusPending)
+	if voucherId == nil {
+		query = query.Where("voucher_id IS NULL")
+	} else {
+		query = query.Where("voucher_id = ?", *voucherId)
+	}
+	err := query.
+		Where("invoice_url <> '' AND expires_at > ?", validUntil).
+		Order("created_at DESC").
+		First(&payment).Error
+	if err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+// GetDetailById loads a payment with its payer, refunds and receipt.
+func (r *paymentRepository) GetDetailById(ctx context.Context, id uuid.UUID) (entity.Payment, error) {
+	var payment entity.Payment
+	err := conn(ctx, r.db).
+		Preload("Account").
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Receipt").
+		First(&payment, "id = ?", id).Error
+	if err != nil {
+		return entity.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) AdminList(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination) ([]entity.Payment, int64, error) {
+	// A new session lets the count and the page share the filter without leaking into each other.
+	query := r.adminQuery(ctx, filter).Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []entity.Payment
+	err := query.
+		Preload("Account").
+		Order(adminPaymentOrder(pagination)).
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// AdminExport walks every payment matching the filter, in list order, one row at a time.
+func (r *paymentRepository) AdminExport(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination, fn func(row dto.PaymentExportRow) error) error {
+	rows, err := r.adminQuery(ctx, filter).
+		Joins("LEFT JOIN products ON products.id = payments.product_id").
+		Select(`payments.id, payments.external_id, payments.invoice_id, payments.account_id,
+			accounts.email AS account_email, products.code AS product_code, payments.description,
+			payments.currency, payments.amount, payments.discount_amount, payments.refunded_amount,
+			payments.status, payments.created_at, payments.paid_at`).
+		Order(adminPaymentOrder(pagination)).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	db := conn(ctx, r.db)
+	for rows.Next() {
+		var row dto.PaymentExportRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *paymentRepository) adminQuery(ctx context.Context, filter dto.AdminPaymentFilter) *gorm.DB {
+	query := conn(ctx, r.db).
+		Model(&entity.Payment{}).
+		Joins("LEFT JOIN accounts ON accounts.id = payments.account_id")
+	if len(filter.Statuses) > 0 {
+		query = query.Where("payments.status IN ?", filter.Statuses)
+	}
+	if filter.AccountId != nil {
+		query = query.Where("payments.account_id = ?", *filter.AccountId)
+	}
+	if filter.ProductId != nil {
+		query = query.Where("payments.product_id = ?", *filter.ProductId)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("payments.amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("payments.amount <= ?", *filter.MaxAmount)
+	}
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.Where("(payments.external_id ILIKE ? OR payments.invoice_id ILIKE ? OR payments.description ILIKE ? OR accounts.email ILIKE ?)",
+			search, search, search, search)
+	}
+	return withPeriod(query, "payments.created_at", filter.Period)
+}
+
+// adminPaymentSorts maps the sort_by values the console accepts to columns.
+var adminPaymentSorts = map[string]string{
+	"created_at": "payments.created_at",
+	"paid_at":    "payments.paid_at",
+	"amount":     "payments.amount",
+	"status":     "payments.status",
+}
+
+// adminPaymentOrder sorts by a known column, newest first by default. The id breaks ties
+// so pages and exports have a stable order.
+func adminPaymentOrder(pagination entity.Pagination) string {
+	column, ok := adminPaymentSorts[pagination.SortBy]
+	if !ok {
+		column = adminPaymentSorts["created_at"]
+	}
+	direction := "DESC"
+	if strings.EqualFold(pagination.Order, "asc") {
+		direction = "ASC"
+	}
+	return column + " " + direction + " NULLS LAST, payments.id " + direction
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductRepository interface {
+	CreateProduct(ctx context.Context, product entity.Product) (entity.Product, error)
+	UpdateProduct(ctx context.Context, product entity.Product) (entity.Product, error)
+	GetProductById(ctx context.Context, id uuid.UUID) (entity.Product, error)
+	GetProductByCode(ctx context.Context, code string) (entity.Product, error)
+	ListProducts(ctx context.Context, activeOnly bool) ([]entity.Product, error)
+	CreatePrice(ctx context.Context, price entity.Price) (entity.Price, error)
+	UpdatePrice(ctx context.Context, price entity.Price) (entity.Price, error)
+	GetPriceById(ctx context.Context, id uuid.UUID) (entity.Price, error)
+}
+
+type productRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{db: db}
+}
+
+func (r *productRepository) CreateProduct(ctx context.Context, product entity.Product) (entity.Product, error) {
+	if err := conn(ctx, r.db).Create(&product).Error; err != nil {
+		return entity.Product{}, err
+	}
+	return product, nil
+}
+
+func (r *productRepository) UpdateProduct(ctx context.Context, product entity.Product) (entity.Product, error) {
+	if err := conn(ctx, r.db).Omit("Prices").Save(&product).Error; err != nil {
+		return entity.Product{}, err
+	}
+	return product, nil
+}
+
+func (r *productRepository) GetProductById(ctx context.Context, id uuid.UUID) (entity.Product, error) {
+	var product entity.Product
+	if err := conn(ctx, r.db).Preload("Prices", orderPrices).First(&product, "id = ?", id).Error; err != nil {
+		return entity.Product{}, err
+	}
+	return product, nil
+}
+
+func (r *productRepository) GetProductByCode(ctx context.Context, code string) (entity.Product, error) {
+	var product entity.Product
+	if err := conn(ctx, r.db).Preload("Prices", orderPrices).First(&product, "code = ?", code).Error; err != nil {
+		return entity.Product{}, err
+	}
+	return product, nil
+}
+
+func (r *productRepository) ListProducts(ctx context.Context, activeOnly bool) ([]entity.Product, error) {
+	var list []entity.Product
+	query := conn(ctx, r.db).Preload("Prices", orderPrices).Order("name ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *productRepository) CreatePrice(ctx context.Context, price entity.Price) (entity.Price, error) {
+	if err := conn(ctx, r.db).Create(&price).Error; err != nil {
+		return entity.Price{}, err
+	}
+	return price, nil
+}
+
+func (r *productRepository) UpdatePrice(ctx context.Context, price entity.Price) (entity.Price, error) {
+	if err := conn(ctx, r.db).Omit("Product").Save(&price).Error; err != nil {
+		return entity.Price{}, err
+	}
+	return price, nil
+}
+
+func (r *productRepository) GetPriceById(ctx context.Context, id uuid.UUID) (entity.Price, error) {
+	var price entity.Price
+	if err := conn(ctx, r.db).First(&price, "id = ?", id).Error; err != nil {
+		return entity.Price{}, err
+	}
+	return price, nil
+}
+
+func orderPrices(db *gorm.DB) *gorm.DB {
+	return db.Order("amount ASC")
+}
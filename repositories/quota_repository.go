@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuotaRepository interface {
+	EnsureUsage(ctx context.Context, accountId uuid.UUID) error
+	GetUsage(ctx context.Context, accountId uuid.UUID) (entity.StorageUsage, error)
+	AddUsage(ctx context.Context, accountId uuid.UUID, bytes int64, files int64, maxBytes int64, maxFiles int64) (bool, error)
+	ListQuotas(ctx context.Context) ([]entity.StorageQuota, error)
+	GetQuota(ctx context.Context, scope string, subject string) (entity.StorageQuota, error)
+	SaveQuota(ctx context.Context, quota entity.StorageQuota) (entity.StorageQuota, error)
+	DeleteQuota(ctx context.Context, scope string, subject string) (int64, error)
+}
+
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+func NewQuotaRepository(db *gorm.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// EnsureUsage creates the usage row of an account from the files it already has, so
+// accounts older than quotas start from what they really store.
+func (r *quotaRepository) EnsureUsage(ctx context.Context, accountId uuid.UUID) error {
+	return conn(ctx, r.db).Exec(`INSERT INTO storage_usages (account_id, bytes, files, updated_at)
+		SELECT ?, COALESCE(SUM(size), 0), COUNT(*), ? FROM files WHERE account_id = ?
+		ON CONFLICT (account_id) DO NOTHING`, accountId, time.Now(), accountId).Error
+}
+
+func (r *quotaRepository) GetUsage(ctx context.Context, accountId uuid.UUID) (entity.StorageUsage, error) {
+	var usage entity.StorageUsage
+	err := conn(ctx, r.db).First(&usage, "account_id = ?", accountId).Error
+	return usage, err
+}
+
+// AddUsage adds bytes and files to the usage of an account in one conditional update,
+// reporting false, and changing nothing, when the result would pass maxBytes or
+// maxFiles. A zero limit is no limit. The row stays locked until the transaction ends,
+// so concurrent uploads of the account wait for each other instead of overshooting.
+func (r *quotaRepository) AddUsage(ctx context.Context, accountId uuid.UUID, bytes int64, files int64, maxBytes int64, maxFiles int64) (bool, error) {
+	tx := conn(ctx, r.db).Model(&entity.StorageUsage{}).
+		Where("account_id = ?", accountId).
+		Where("? = 0 OR bytes + ? <= ?", maxBytes, bytes, maxBytes).
+		Where("? = 0 OR files + ? <= ?", maxFiles, files, maxFiles).
+		Updates(map[string]interface{}{
+			"bytes":      gorm.Expr("GREATEST(bytes + ?, 0)", bytes),
+			"files":      gorm.Expr("GREATEST(files + ?, 0)", files),
+			"updated_at": time.Now(),
+		})
+	return tx.RowsAffected == 1, tx.Error
+}
+
+func (r *quotaRepository) ListQuotas(ctx context.Context) ([]entity.StorageQuota, error) {
+	var quotas []entity.StorageQuota
+	err := conn(ctx, r.db).Order("scope, subject").Find(&quotas).Error
+	return quotas, err
+}
+
+func (r *quotaRepository) GetQuota(ctx context.Context, scope string, subject string) (entity.StorageQuota, error) {
+	var quota entity.StorageQuota
+	err := conn(ctx, r.db).First(&quota, "scope = ? AND subject = ?", scope, subject).Error
+	return quota, err
+}
+
+// SaveQuota creates the override of a subject or replaces its limits.
+func (r *quotaRepository) SaveQuota(ctx context.Context, quota entity.StorageQuota) (entity.StorageQuota, error) {
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "subject"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_bytes", "max_files", "updated_at"}),
+	}).Create(&quota).Error
+	if err != nil {
+		return entity.StorageQuota{}, err
+	}
+	return r.GetQuota(ctx, quota.Scope, quota.Subject)
+}
+
+func (r *quotaRepository) DeleteQuota(ctx context.Context, scope string, subject string) (int64, error) {
+	tx := conn(ctx, r.db).Delete(&entity.StorageQuota{}, "scope = ? AND subject = ?", scope, subject)
+	return tx.RowsAffected, tx.Error
+}
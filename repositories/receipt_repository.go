@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReceiptRepository interface {
+	NextNumber(ctx context.Context, year int) (int64, error)
+	Create(ctx context.Context, receipt entity.Receipt) (entity.Receipt, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.Receipt, error)
+	GetByPaymentId(ctx context.Context, paymentId uuid.UUID) (entity.Receipt, error)
+	ListUnrendered(ctx context.Context, limit int) ([]entity.Receipt, error)
+	SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) (bool, error)
+	MarkEmailed(ctx context.Context, id uuid.UUID, at time.Time) error
+	SetError(ctx context.Context, id uuid.UUID, message string) error
+}
+
+type receiptRepository struct {
+	db *gorm.DB
+}
+
+func NewReceiptRepository(db *gorm.DB) ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+// NextNumber takes the next receipt number of the year. The sequence row stays locked
+// until the surrounding transaction ends, so numbers are handed out one at a time and a
+// rolled back transaction gives its number back.
+func (r *receiptRepository) NextNumber(ctx context.Context, year int) (int64, error) {
+	var number int64
+	err := conn(ctx, r.db).Raw(
+		`INSERT INTO receipt_sequences (year, last_number) VALUES (?, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = receipt_sequences.last_number + 1
+		RETURNING last_number`, year).Scan(&number).Error
+	return number, err
+}
+
+func (r *receiptRepository) Create(ctx context.Context, receipt entity.Receipt) (entity.Receipt, error) {
+	if err := conn(ctx, r.db).Omit("Payment", "File").Create(&receipt).Error; err != nil {
+		return entity.Receipt{}, err
+	}
+	return receipt, nil
+}
+
+func (r *receiptRepository) GetById(ctx context.Context, id uuid.UUID) (entity.Receipt, error) {
+	var receipt entity.Receipt
+	err := conn(ctx, r.db).
+		Preload("Payment.Account").
+		Preload("File").
+		First(&receipt, "id = ?", id).Error
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+	return receipt, nil
+}
+
+func (r *receiptRepository) GetByPaymentId(ctx context.Context, paymentId uuid.UUID) (entity.Receipt, error) {
+	var receipt entity.Receipt
+	err := conn(ctx, r.db).
+		Preload("Payment.Account").
+		Preload("File").
+		First(&receipt, "payment_id = ?", paymentId).Error
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+	return receipt, nil
+}
+
+func (r *receiptRepository) ListUnrendered(ctx context.Context, limit int) ([]entity.Receipt, error) {
+	var list []entity.Receipt
+	err := conn(ctx, r.db).
+		Where("file_id IS NULL").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SetFile links the rendered PDF unless another one was linked first, and reports
+// whether it did.
+func (r *receiptRepository) SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) (bool, error) {
+	tx := conn(ctx, r.db).
+		Model(&entity.Receipt{}).
+		Where("id = ? AND file_id IS NULL", id).
+		Updates(map[string]interface{}{"file_id": fileId, "error": ""})
+	return tx.RowsAffected == 1, tx.Error
+}
+
+func (r *receiptRepository) MarkEmailed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return conn(ctx, r.db).Model(&entity.Receipt{}).Where("id = ?", id).Update("emailed_at", at).Error
+}
+
+func (r *receiptRepository) SetError(ctx context.Context, id uuid.UUID, message string) error {
+	return conn(ctx, r.db).Model(&entity.Receipt{}).Where("id = ?", id).Update("error", message).Error
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefundRepository interface {
+	Create(ctx context.Context, refund entity.Refund) (entity.Refund, error)
+	Update(ctx context.Context, refund entity.Refund) (entity.Refund, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.Refund, error)
+	GetByProviderRefundId(ctx context.Context, providerRefundId string) (entity.Refund, error)
+	List(ctx context.Context, status string) ([]entity.Refund, error)
+	SumOutstandingByPaymentId(ctx context.Context, paymentId uuid.UUID) (float64, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, from string, to string, fields map[string]interface{}) (bool, error)
+}
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) Create(ctx context.Context, refund entity.Refund) (entity.Refund, error) {
+	if err := conn(ctx, r.db).Create(&refund).Error; err != nil {
+		return entity.Refund{}, err
+	}
+	return refund, nil
+}
+
+func (r *refundRepository) Update(ctx context.Context, refund entity.Refund) (entity.Refund, error) {
+	if err := conn(ctx, r.db).Save(&refund).Error; err != nil {
+		return entity.Refund{}, err
+	}
+	return refund, nil
+}
+
+func (r *refundRepository) GetById(ctx context.Context, id uuid.UUID) (entity.Refund, error) {
+	var refund entity.Refund
+	if err := conn(ctx, r.db).First(&refund, "id = ?", id).Error; err != nil {
+		return entity.Refund{}, err
+	}
+	return refund, nil
+}
+
+func (r *refundRepository) GetByProviderRefundId(ctx context.Context, providerRefundId string) (entity.Refund, error) {
+	var refund entity.Refund
+	if err := conn(ctx, r.db).First(&refund, "provider_refund_id = ?", providerRefundId).Error; err != nil {
+		return entity.Refund{}, err
+	}
+	return refund, nil
+}
+
+// List returns refunds newest first, optionally narrowed to one status.
+func (r *refundRepository) List(ctx context.Context, status string) ([]entity.Refund, error) {
+	var list []entity.Refund
+	query := conn(ctx, r.db).Preload("Payment").Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SumOutstandingByPaymentId totals the refunds of a payment that are requested, in
+// flight or settled, i.e. everything that still counts against the refundable balance.
+func (r *refundRepository) SumOutstandingByPaymentId(ctx context.Context, paymentId uuid.UUID) (float64, error) {
+	var total float64
+	err := conn(ctx, r.db).
+		Model(&entity.Refund{}).
+		Where("payment_id = ? AND status IN ?", paymentId,
+			[]string{entity.RefundStatusRequested, entity.RefundStatusPending, entity.RefundStatusSucceeded}).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// UpdateStatus moves the refund from one status to another only if it is still in the
+// expected status, so concurrent reviews and callbacks cannot both apply.
+func (r *refundRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from string, to string, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	tx := conn(ctx, r.db).
+		Model(&entity.Refund{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	return tx.RowsAffected == 1, tx.Error
+}
@@ -1 +1,81 @@
 package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// txState is the transaction carried by a context, with the hooks to run once it ends.
+type txState struct {
+	tx            *gorm.DB
+	afterCommit   []func(ctx context.Context)
+	afterRollback []func(ctx context.Context)
+}
+
+// Transactor runs a function inside one database transaction. Repositories pick the
+// transaction up from the context, so services can compose repository calls atomically.
+// Side effects outside the database, such as storage writes, are tied to its outcome
+// with AfterCommit and AfterRollback.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+	AfterRollback(ctx context.Context, fn func(ctx context.Context))
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction joins the transaction already carried by ctx instead of nesting one.
+// The hooks registered inside it run once the outermost transaction ends, with ctx.
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+	state := &txState{}
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	hooks := state.afterCommit
+	if err != nil {
+		hooks = state.afterRollback
+	}
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+	return err
+}
+
+// AfterCommit runs fn once the transaction carried by ctx commits, or right away when
+// ctx carries none.
+func (t *transactor) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// AfterRollback runs fn should the transaction carried by ctx roll back, a failed commit
+// included. Without a transaction there is nothing to roll back and fn never runs.
+func (t *transactor) AfterRollback(ctx context.Context, fn func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterRollback = append(state.afterRollback, fn)
+	}
+}
+
+// conn returns the transaction carried by ctx, or db when there is none.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SubscriptionRepository interface {
+	Create(ctx context.Context, subscription entity.Subscription) (entity.Subscription, error)
+	Update(ctx context.Context, subscription entity.Subscription) (entity.Subscription, error)
+	UpdateWhere(ctx context.Context, id uuid.UUID, conditions map[string]interface{}, fields map[string]interface{}) (bool, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.Subscription, error)
+	LockById(ctx context.Context, id uuid.UUID) (entity.Subscription, error)
+	GetOpen(ctx context.Context, accountId uuid.UUID, productId uuid.UUID) (entity.Subscription, error)
+	ListByAccountId(ctx context.Context, accountId uuid.UUID) ([]entity.Subscription, error)
+	List(ctx context.Context, pagination entity.Pagination) ([]entity.Subscription, error)
+	ListDue(ctx context.Context, at time.Time, limit int) ([]entity.Subscription, error)
+	ListAbandoned(ctx context.Context, limit int) ([]entity.Subscription, error)
+}
+
+type subscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+func (r *subscriptionRepository) Create(ctx context.Context, subscription entity.Subscription) (entity.Subscription, error) {
+	if err := conn(ctx, r.db).Omit("Product", "Price").Create(&subscription).Error; err != nil {
+		return entity.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) Update(ctx context.Context, subscription entity.Subscription) (entity.Subscription, error) {
+	if err := conn(ctx, r.db).Omit("Product", "Price").Save(&subscription).Error; err != nil {
+		return entity.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// UpdateWhere applies fields only while the subscription still matches conditions, and
+// reports whether it did. The renewal job uses it so it never overwrites a change made
+// since it read the subscription.
+func (r *subscriptionRepository) UpdateWhere(ctx context.Context, id uuid.UUID, conditions map[string]interface{}, fields map[string]interface{}) (bool, error) {
+	tx := conn(ctx, r.db).
+		Model(&entity.Subscription{}).
+		Where("id = ?", id).
+		Where(conditions).
+		Updates(fields)
+	return tx.RowsAffected == 1, tx.Error
+}
+
+func (r *subscriptionRepository) GetById(ctx context.Context, id uuid.UUID) (entity.Subscription, error) {
+	var subscription entity.Subscription
+	if err := conn(ctx, r.db).Preload("Product").Preload("Price").First(&subscription, "id = ?", id).Error; err != nil {
+		return entity.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// LockById reads the subscription with a row lock held until the surrounding transaction ends.
+func (r *subscriptionRepository) LockById(ctx context.Context, id uuid.UUID) (entity.Subscription, error) {
+	var subscription entity.Subscription
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&subscription, "id = ?", id).Error
+	if err != nil {
+		return entity.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// GetOpen returns the subscription of the account to the product that is not CANCELED.
+func (r *subscriptionRepository) GetOpen(ctx context.Context, accountId uuid.UUID, productId uuid.UUID) (entity.Subscription, error) {
+	var subscription entity.Subscription
+	err := conn(ctx, r.db).
+		Where("account_id = ? AND product_id = ? AND status <> ?", accountId, productId, entity.SubscriptionStatusCanceled).
+		First(&subscription).Error
+	if err != nil {
+		return entity.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) ListByAccountId(ctx context.Context, accountId uuid.UUID) ([]entity.Subscription, error) {
+	var list []entity.Subscription
+	err := conn(ctx, r.db).
+		Preload("Product").
+		Preload("Price").
+		Where("account_id = ?", accountId).
+		Order("created_at DESC").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *subscriptionRepository) List(ctx context.Context, pagination entity.Pagination) ([]entity.Subscription, error) {
+	var list []entity.Subscription
+	query := conn(ctx, r.db).Preload("Product").Preload("Price")
+	if pagination.Status != nil {
+		query = query.Where("status = ?", *pagination.Status)
+	}
+	err := query.
+		Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListDue returns running subscriptions the renewal job has to look at: those whose
+// renewal time or period end has come.
+func (r *subscriptionRepository) ListDue(ctx context.Context, at time.Time, limit int) ([]entity.Subscription, error) {
+	var list []entity.Subscription
+	err := conn(ctx, r.db).
+		Preload("Product").
+		Preload("Price").
+		Where("status IN ?", []string{entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}).
+		Where("(next_renewal_at <= ? OR current_period_end <= ?)", at, at).
+		Order("next_renewal_at ASC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListAbandoned returns INCOMPLETE subscriptions whose first invoice failed or expired.
+func (r *subscriptionRepository) ListAbandoned(ctx context.Context, limit int) ([]entity.Subscription, error) {
+	var list []entity.Subscription
+	err := conn(ctx, r.db).
+		Joins("JOIN payments ON payments.id = subscriptions.latest_payment_id").
+		Where("subscriptions.status = ?", entity.SubscriptionStatusIncomplete).
+		Where("payments.status IN ?", []string{entity.PaymentStatusFailed, entity.PaymentStatusExpired}).
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TusUploadRepository interface {
+	Create(ctx context.Context, upload entity.TusUpload) (entity.TusUpload, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.TusUpload, error)
+	AdvanceOffset(ctx context.Context, id uuid.UUID, from int64, to int64) (bool, error)
+	SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.TusUpload, error)
+}
+
+type tusUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewTusUploadRepository(db *gorm.DB) TusUploadRepository {
+	return &tusUploadRepository{db: db}
+}
+
+func (r *tusUploadRepository) Create(ctx context.Context, upload entity.TusUpload) (entity.TusUpload, error) {
+	err := conn(ctx, r.db).Create(&upload).Error
+	return upload, err
+}
+
+func (r *tusUploadRepository) GetById(ctx context.Context, id uuid.UUID) (entity.TusUpload, error) {
+	var upload entity.TusUpload
+	err := conn(ctx, r.db).First(&upload, "id = ?", id).Error
+	return upload, err
+}
+
+// AdvanceOffset moves the offset only while it is still at from, so two writers cannot
+// both claim the same bytes.
+func (r *tusUploadRepository) AdvanceOffset(ctx context.Context, id uuid.UUID, from int64, to int64) (bool, error) {
+	result := conn(ctx, r.db).Model(&entity.TusUpload{}).
+		Where("id = ? AND upload_offset = ?", id, from).
+		Updates(map[string]interface{}{"upload_offset": to, "updated_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *tusUploadRepository) SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) error {
+	return conn(ctx, r.db).Model(&entity.TusUpload{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"file_id": fileId, "updated_at": time.Now()}).Error
+}
+
+func (r *tusUploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&entity.TusUpload{}, "id = ?", id).Error
+}
+
+func (r *tusUploadRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.TusUpload, error) {
+	var uploads []entity.TusUpload
+	err := conn(ctx, r.db).
+		Where("expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
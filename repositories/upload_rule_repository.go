@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UploadRuleRepository interface {
+	List(ctx context.Context) ([]entity.UploadRule, error)
+	GetByContext(ctx context.Context, uploadContext string) (entity.UploadRule, error)
+	Create(ctx context.Context, rule entity.UploadRule) (entity.UploadRule, error)
+	CreateMissing(ctx context.Context, rule entity.UploadRule) (bool, error)
+	Update(ctx context.Context, rule entity.UploadRule) (entity.UploadRule, error)
+	Delete(ctx context.Context, uploadContext string) (int64, error)
+}
+
+type uploadRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRuleRepository(db *gorm.DB) UploadRuleRepository {
+	return &uploadRuleRepository{db: db}
+}
+
+func (r *uploadRuleRepository) List(ctx context.Context) ([]entity.UploadRule, error) {
+	var rules []entity.UploadRule
+	err := conn(ctx, r.db).Order("context").Find(&rules).Error
+	return rules, err
+}
+
+func (r *uploadRuleRepository) GetByContext(ctx context.Context, uploadContext string) (entity.UploadRule, error) {
+	var rule entity.UploadRule
+	err := conn(ctx, r.db).First(&rule, "context = ?", uploadContext).Error
+	return rule, err
+}
+
+func (r *uploadRuleRepository) Create(ctx context.Context, rule entity.UploadRule) (entity.UploadRule, error) {
+	err := conn(ctx, r.db).Create(&rule).Error
+	return rule, err
+}
+
+// CreateMissing creates the rule unless its context exists, reporting whether it did.
+func (r *uploadRuleRepository) CreateMissing(ctx context.Context, rule entity.UploadRule) (bool, error) {
+	tx := conn(ctx, r.db).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "context"}}, DoNothing: true}).Create(&rule)
+	return tx.RowsAffected == 1, tx.Error
+}
+
+// Update replaces every setting of the rule of rule.Context.
+func (r *uploadRuleRepository) Update(ctx context.Context, rule entity.UploadRule) (entity.UploadRule, error) {
+	tx := conn(ctx, r.db).Model(&entity.UploadRule{}).
+		Where("context = ?", rule.Context).
+		Select("max_bytes", "max_count", "allowed_exts", "allowed_mime_types", "path_template", "private", "process_images", "roles", "priority", "updated_at").
+		Updates(&rule)
+	if tx.Error != nil {
+		return entity.UploadRule{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return entity.UploadRule{}, gorm.ErrRecordNotFound
+	}
+	return r.GetByContext(ctx, rule.Context)
+}
+
+func (r *uploadRuleRepository) Delete(ctx context.Context, uploadContext string) (int64, error) {
+	tx := conn(ctx, r.db).Delete(&entity.UploadRule{}, "context = ?", uploadContext)
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UploadSlotRepository interface {
+	Create(ctx context.Context, slot entity.UploadSlot) (entity.UploadSlot, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.UploadSlot, error)
+	LockById(ctx context.Context, id uuid.UUID) (entity.UploadSlot, error)
+	SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.UploadSlot, error)
+}
+
+type uploadSlotRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadSlotRepository(db *gorm.DB) UploadSlotRepository {
+	return &uploadSlotRepository{db: db}
+}
+
+func (r *uploadSlotRepository) Create(ctx context.Context, slot entity.UploadSlot) (entity.UploadSlot, error) {
+	err := conn(ctx, r.db).Create(&slot).Error
+	return slot, err
+}
+
+func (r *uploadSlotRepository) GetById(ctx context.Context, id uuid.UUID) (entity.UploadSlot, error) {
+	var slot entity.UploadSlot
+	err := conn(ctx, r.db).First(&slot, "id = ?", id).Error
+	return slot, err
+}
+
+// LockById reads the slot with a row lock held until the surrounding transaction ends.
+func (r *uploadSlotRepository) LockById(ctx context.Context, id uuid.UUID) (entity.UploadSlot, error) {
+	var slot entity.UploadSlot
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&slot, "id = ?", id).Error
+	return slot, err
+}
+
+func (r *uploadSlotRepository) SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) error {
+	return conn(ctx, r.db).Model(&entity.UploadSlot{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"file_id": fileId, "updated_at": time.Now()}).Error
+}
+
+func (r *uploadSlotRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&entity.UploadSlot{}, "id = ?", id).Error
+}
+
+func (r *uploadSlotRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.UploadSlot, error) {
+	var slots []entity.UploadSlot
+	err := conn(ctx, r.db).
+		Where("expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&slots).Error
+	return slots, err
+}
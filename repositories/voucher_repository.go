@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository interface {
+	Create(ctx context.Context, voucher entity.Voucher) (entity.Voucher, error)
+	Update(ctx context.Context, voucher entity.Voucher, products []entity.Product) (entity.Voucher, error)
+	GetById(ctx context.Context, id uuid.UUID) (entity.Voucher, error)
+	GetByCode(ctx context.Context, code string) (entity.Voucher, error)
+	LockByCode(ctx context.Context, code string) (entity.Voucher, error)
+	List(ctx context.Context, pagination entity.Pagination) ([]entity.Voucher, error)
+	CountRedemptions(ctx context.Context, voucherId uuid.UUID, accountId *uuid.UUID) (int64, error)
+	CreateRedemption(ctx context.Context, redemption entity.VoucherRedemption) (entity.VoucherRedemption, error)
+	DeleteRedemptionByPaymentId(ctx context.Context, paymentId uuid.UUID) error
+	MarkRedeemed(ctx context.Context, paymentId uuid.UUID, at time.Time) error
+	ListRedemptions(ctx context.Context, voucherId uuid.UUID, pagination entity.Pagination) ([]entity.VoucherRedemption, error)
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db: db}
+}
+
+func (r *voucherRepository) Create(ctx context.Context, voucher entity.Voucher) (entity.Voucher, error) {
+	if err := conn(ctx, r.db).Create(&voucher).Error; err != nil {
+		return entity.Voucher{}, err
+	}
+	return voucher, nil
+}
+
+// Update saves the voucher and, when products is not nil, replaces its product restriction.
+func (r *voucherRepository) Update(ctx context.Context, voucher entity.Voucher, products []entity.Product) (entity.Voucher, error) {
+	db := conn(ctx, r.db)
+	if err := db.Omit("Products").Save(&voucher).Error; err != nil {
+		return entity.Voucher{}, err
+	}
+	if products != nil {
+		if err := db.Model(&voucher).Association("Products").Replace(products); err != nil {
+			return entity.Voucher{}, err
+		}
+	}
+	return voucher, nil
+}
+
+func (r *voucherRepository) GetById(ctx context.Context, id uuid.UUID) (entity.Voucher, error) {
+	var voucher entity.Voucher
+	if err := conn(ctx, r.db).Preload("Products").First(&voucher, "id = ?", id).Error; err != nil {
+		return entity.Voucher{}, err
+	}
+	return voucher, nil
+}
+
+func (r *voucherRepository) GetByCode(ctx context.Context, code string) (entity.Voucher, error) {
+	var voucher entity.Voucher
+	if err := conn(ctx, r.db).Preload("Products").First(&voucher, "code = ?", code).Error; err != nil {
+		return entity.Voucher{}, err
+	}
+	return voucher, nil
+}
+
+// LockByCode reads the voucher with a row lock held until the surrounding transaction
+// ends, so concurrent redemptions of the same voucher are counted one at a time.
+func (r *voucherRepository) LockByCode(ctx context.Context, code string) (entity.Voucher, error) {
+	var voucher entity.Voucher
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&voucher, "code = ?", code).Error
+	if err != nil {
+		return entity.Voucher{}, err
+	}
+	if err := conn(ctx, r.db).Model(&voucher).Association("Products").Find(&voucher.Products); err != nil {
+		return entity.Voucher{}, err
+	}
+	return voucher, nil
+}
+
+func (r *voucherRepository) List(ctx context.Context, pagination entity.Pagination) ([]entity.Voucher, error) {
+	var list []entity.Voucher
+	query := conn(ctx, r.db).Preload("Products")
+	if pagination.Search != "" {
+		query = query.Where("code ILIKE ?", "%"+pagination.Search+"%")
+	}
+	err := query.
+		Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountRedemptions counts the redemptions that hold a slot of the voucher, for one account
+// when accountId is set. Redemptions whose payment failed or expired give their slot back.
+func (r *voucherRepository) CountRedemptions(ctx context.Context, voucherId uuid.UUID, accountId *uuid.UUID) (int64, error) {
+	var count int64
+	query := conn(ctx, r.db).
+		Model(&entity.VoucherRedemption{}).
+		Joins("LEFT JOIN payments ON payments.id = voucher_redemptions.payment_id").
+		Where("voucher_redemptions.voucher_id = ?", voucherId).
+		Where("(payments.status IS NULL OR payments.status NOT IN ?)", []string{entity.PaymentStatusFailed, entity.PaymentStatusExpired})
+	if accountId != nil {
+		query = query.Where("voucher_redemptions.account_id = ?", *accountId)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *voucherRepository) CreateRedemption(ctx context.Context, redemption entity.VoucherRedemption) (entity.VoucherRedemption, error) {
+	if err := conn(ctx, r.db).Create(&redemption).Error; err != nil {
+		return entity.VoucherRedemption{}, err
+	}
+	return redemption, nil
+}
+
+func (r *voucherRepository) DeleteRedemptionByPaymentId(ctx context.Context, paymentId uuid.UUID) error {
+	return conn(ctx, r.db).Where("payment_id = ?", paymentId).Delete(&entity.VoucherRedemption{}).Error
+}
+
+func (r *voucherRepository) MarkRedeemed(ctx context.Context, paymentId uuid.UUID, at time.Time) error {
+	return conn(ctx, r.db).
+		Model(&entity.VoucherRedemption{}).
+		Where("payment_id = ? AND redeemed_at IS NULL", paymentId).
+		Update("redeemed_at", at).Error
+}
+
+func (r *voucherRepository) ListRedemptions(ctx context.Context, voucherId uuid.UUID, pagination entity.Pagination) ([]entity.VoucherRedemption, error) {
+	var list []entity.VoucherRedemption
+	err := conn(ctx, r.db).
+		Preload("Payment").
+		Where("voucher_id = ?", voucherId).
+		Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
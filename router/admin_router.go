@@ -8,6 +8,18 @@ import (
 func AdminRouter(router *gin.Engine, middleware provider.MiddlewareProvider, controller provider.ControllerProvider) {
 	authenticationMiddleware := middleware.ProvideAuthenticationMiddleware()
 	authenticationController := controller.ProvideAuthenticationController()
+	paymentCallbackController := controller.ProvidePaymentCallbackController()
+	paymentAdminController := controller.ProvidePaymentAdminController()
+	refundController := controller.ProvideRefundController()
+	reconciliationController := controller.ProvidePaymentReconciliationController()
+	productController := controller.ProvideProductController()
+	voucherController := controller.ProvideVoucherController()
+	subscriptionController := controller.ProvideSubscriptionController()
+	ledgerController := controller.ProvideLedgerController()
+	fileController := controller.ProvideFileController()
+	quotaController := controller.ProvideQuotaController()
+	uploadRuleController := controller.ProvideUploadRuleController()
+	downloadController := controller.ProvideDownloadController()
 
 	// Authentication Admin Routes
 	authAdminGroup := router.Group("/api/v1/admin/authentication", authenticationMiddleware.VerifyAccount)
@@ -15,4 +27,80 @@ func AdminRouter(router *gin.Engine, middleware provider.MiddlewareProvider, con
 		authAdminGroup.PUT("/:account_id/assign", authenticationController.UpdateUserRole)
 	}
 
+	// Payment Admin Routes
+	paymentAdminGroup := router.Group("/api/v1/admin/payment", authenticationMiddleware.VerifyAccount, authenticationMiddleware.VerifyAdmin)
+	{
+		paymentAdminGroup.GET("", paymentAdminController.List)
+		paymentAdminGroup.GET("/export", paymentAdminController.Export)
+		paymentAdminGroup.POST("/callbacks/:id/replay", paymentCallbackController.Replay)
+		paymentAdminGroup.GET("/refunds", refundController.List)
+		paymentAdminGroup.POST("/refunds/:id/approve", refundController.Approve)
+		paymentAdminGroup.POST("/refunds/:id/reject", refundController.Reject)
+		paymentAdminGroup.POST("/reconciliations", reconciliationController.Run)
+		paymentAdminGroup.GET("/reconciliations", reconciliationController.List)
+		paymentAdminGroup.GET("/reconciliations/:id", reconciliationController.Get)
+		paymentAdminGroup.GET("/:id", paymentAdminController.Get)
+	}
+
+	// Product & Voucher Admin Routes
+	productAdminGroup := router.Group("/api/v1/admin", authenticationMiddleware.VerifyAccount, authenticationMiddleware.VerifyAdmin)
+	{
+		productAdminGroup.GET("/products", productController.AdminList)
+		productAdminGroup.POST("/products", productController.Create)
+		productAdminGroup.PUT("/products/:id", productController.Update)
+		productAdminGroup.POST("/products/:id/prices", productController.CreatePrice)
+		productAdminGroup.PUT("/prices/:id", productController.UpdatePrice)
+		productAdminGroup.GET("/vouchers", voucherController.List)
+		productAdminGroup.POST("/vouchers", voucherController.Create)
+		productAdminGroup.GET("/vouchers/:id", voucherController.Get)
+		productAdminGroup.PUT("/vouchers/:id", voucherController.Update)
+		productAdminGroup.GET("/vouchers/:id/redemptions", voucherController.Redemptions)
+		productAdminGroup.GET("/subscriptions", subscriptionController.AdminList)
+		productAdminGroup.POST("/subscriptions/renewals", subscriptionController.RunRenewals)
+	}
+
+	// Ledger Admin Routes
+	ledgerAdminGroup := router.Group("/api/v1/admin/ledger", authenticationMiddleware.VerifyAccount, authenticationMiddleware.VerifyAdmin)
+	{
+		ledgerAdminGroup.GET("/balances", ledgerController.Balances)
+		ledgerAdminGroup.GET("/entries", ledgerController.Entries)
+		ledgerAdminGroup.GET("/check", ledgerController.Check)
+		ledgerAdminGroup.GET("/export", ledgerController.Export)
+	}
+
+	// File Admin Routes
+	fileAdminGroup := router.Group("/api/v1/admin/files", authenticationMiddleware.VerifyAccount, authenticationMiddleware.VerifyAdmin)
+	{
+		fileAdminGroup.GET("", fileController.AdminList)
+		fileAdminGroup.POST("/archive", downloadController.AdminArchive)
+		fileAdminGroup.GET("/:id", fileController.AdminGet)
+		fileAdminGroup.GET("/:id/content", downloadController.AdminContent)
+		fileAdminGroup.GET("/:id/downloads", downloadController.AdminList)
+		fileAdminGroup.PATCH("/:id", fileController.AdminUpdate)
+		fileAdminGroup.DELETE("/:id", fileController.AdminTrash)
+		fileAdminGroup.POST("/:id/restore", fileController.AdminRestore)
+		fileAdminGroup.DELETE("/:id/permanent", fileController.AdminDelete)
+	}
+
+	// Storage Quota Admin Routes
+	quotaAdminGroup := router.Group("/api/v1/admin/quotas", authenticationMiddleware.VerifyAccount, authenticationMiddleware.VerifyAdmin)
+	{
+		quotaAdminGroup.GET("", quotaController.List)
+		quotaAdminGroup.PUT("/roles/:role", quotaController.SetRole)
+		quotaAdminGroup.DELETE("/roles/:role", quotaController.DeleteRole)
+		quotaAdminGroup.PUT("/accounts/:account_id", quotaController.SetAccount)
+		quotaAdminGroup.DELETE("/accounts/:account_id", quotaController.DeleteAccount)
+		quotaAdminGroup.GET("/accounts/:account_id/usage", quotaController.AdminUsage)
+	}
+
+	// Upload Rule Admin Routes
+	uploadRuleAdminGroup := router.Group("/api/v1/admin/upload-rules", authenticationMiddleware.VerifyAccount, authenticationMiddleware.VerifyAdmin)
+	{
+		uploadRuleAdminGroup.GET("", uploadRuleController.List)
+		uploadRuleAdminGroup.POST("", uploadRuleController.Create)
+		uploadRuleAdminGroup.GET("/:context", uploadRuleController.Get)
+		uploadRuleAdminGroup.PUT("/:context", uploadRuleController.Update)
+		uploadRuleAdminGroup.DELETE("/:context", uploadRuleController.Delete)
+	}
+
 }
@@ -10,6 +10,6 @@ func ForgotPasswordRouter(router *gin.Engine, controller provider.ControllerProv
 	forgotPasswordController := controller.ProvideForgotPasswordController()
 	{
 		routerGroup.POST("/", forgotPasswordController.Request)
+		routerGroup.POST("/reset", forgotPasswordController.Reset)
 	}
 }
-
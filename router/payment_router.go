@@ -0,0 +1,22 @@
+package router
+
+import (
+	"abdanhafidz.com/go-boilerplate/provider"
+	"github.com/gin-gonic/gin"
+)
+
+func PaymentRouter(router *gin.Engine, middleware provider.MiddlewareProvider, controller provider.ControllerProvider) {
+	routerGroup := router.Group("/api/v1/payment")
+	paymentController := controller.ProvidePaymentController()
+	refundController := controller.ProvideRefundController()
+	receiptController := controller.ProvideReceiptController()
+	authenticationMiddleware := middleware.ProvideAuthenticationMiddleware()
+	{
+		routerGroup.POST("", authenticationMiddleware.VerifyAccount, paymentController.Create)
+		routerGroup.GET("", authenticationMiddleware.VerifyAccount, paymentController.History)
+		routerGroup.GET("/:id", authenticationMiddleware.VerifyAccount, paymentController.GetStatus)
+		routerGroup.POST("/:id/refunds", authenticationMiddleware.VerifyAccount, refundController.Request)
+		routerGroup.GET("/:id/receipt", authenticationMiddleware.VerifyAccount, receiptController.Get)
+		routerGroup.GET("/:id/receipt/pdf", authenticationMiddleware.VerifyAccount, receiptController.Download)
+	}
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"abdanhafidz.com/go-boilerplate/provider"
+	"github.com/gin-gonic/gin"
+)
+
+func PaymentSimulatorRouter(router *gin.Engine, controller provider.ControllerProvider) {
+	routerGroup := router.Group("/api/v1/payment/simulator")
+	paymentSimulatorController := controller.ProvidePaymentSimulatorController()
+	{
+		routerGroup.GET("/:id", paymentSimulatorController.Checkout)
+		routerGroup.POST("/:id", paymentSimulatorController.Settle)
+	}
+}
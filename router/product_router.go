@@ -0,0 +1,25 @@
+package router
+
+import (
+	"abdanhafidz.com/go-boilerplate/provider"
+	"github.com/gin-gonic/gin"
+)
+
+func ProductRouter(router *gin.Engine, middleware provider.MiddlewareProvider, controller provider.ControllerProvider) {
+	productController := controller.ProvideProductController()
+	entitlementController := controller.ProvideEntitlementController()
+	authenticationMiddleware := middleware.ProvideAuthenticationMiddleware()
+
+	productGroup := router.Group("/api/v1/products")
+	{
+		productGroup.GET("", productController.List)
+		productGroup.GET("/:code", productController.Get)
+		productGroup.POST("/:code/checkout", authenticationMiddleware.VerifyAccount, productController.Checkout)
+	}
+
+	entitlementGroup := router.Group("/api/v1/entitlements", authenticationMiddleware.VerifyAccount)
+	{
+		entitlementGroup.GET("", entitlementController.List)
+		entitlementGroup.GET("/:code", entitlementController.Check)
+	}
+}
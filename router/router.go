@@ -29,8 +29,14 @@ func RunRouter(appProvider provider.AppProvider) {
 	EmailVerificationRouter(router, controller)
 	OptionsRouter(router, controller)
 	UploadRouter(router, middleware, controller)
+	TusRouter(router, middleware, controller)
 	AdminRouter(router, middleware, controller)
+	PaymentRouter(router, middleware, controller)
 	PaymentCallbackRouter(router, controller)
+	PaymentSimulatorRouter(router, controller)
+	ProductRouter(router, middleware, controller)
+	SubscriptionRouter(router, middleware, controller)
+	StorageRouter(router, controller, config)
 	SwaggerRouter(router)
 	router.Run(config.ProvideEnvConfig().GetTCPAddress())
 }
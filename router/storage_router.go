@@ -0,0 +1,18 @@
+package router
+
+import (
+	"abdanhafidz.com/go-boilerplate/config"
+	"abdanhafidz.com/go-boilerplate/provider"
+	"github.com/gin-gonic/gin"
+)
+
+// StorageRouter serves the files of the local storage driver; other drivers serve their
+// own URLs.
+func StorageRouter(router *gin.Engine, controller provider.ControllerProvider, configProvider provider.ConfigProvider) {
+	if configProvider.ProvideStorageConfig().GetDriver() != config.StorageDriverLocal {
+		return
+	}
+	storageController := controller.ProvideStorageController()
+	router.GET(config.LocalStorageRoute+"/*filepath", storageController.Serve)
+	router.HEAD(config.LocalStorageRoute+"/*filepath", storageController.Serve)
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"abdanhafidz.com/go-boilerplate/provider"
+	"github.com/gin-gonic/gin"
+)
+
+func SubscriptionRouter(router *gin.Engine, middleware provider.MiddlewareProvider, controller provider.ControllerProvider) {
+	subscriptionController := controller.ProvideSubscriptionController()
+	authenticationMiddleware := middleware.ProvideAuthenticationMiddleware()
+
+	subscriptionGroup := router.Group("/api/v1/subscriptions", authenticationMiddleware.VerifyAccount)
+	{
+		subscriptionGroup.GET("", subscriptionController.List)
+		subscriptionGroup.GET("/:id", subscriptionController.Get)
+		subscriptionGroup.POST("/:id/cancel", subscriptionController.Cancel)
+		subscriptionGroup.POST("/:id/resume", subscriptionController.Resume)
+	}
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"abdanhafidz.com/go-boilerplate/provider"
+	"github.com/gin-gonic/gin"
+)
+
+// TusRouter serves resumable uploads. Chunks are not gzipped, and OPTIONS stays open so
+// clients can discover the protocol before they authenticate.
+func TusRouter(r *gin.Engine, middleware provider.MiddlewareProvider, controller provider.ControllerProvider) {
+	tusController := controller.ProvideTusController()
+	authenticationMiddleware := middleware.ProvideAuthenticationMiddleware()
+
+	r.OPTIONS("/api/v1/files/tus", tusController.Options)
+
+	routerGroup := r.Group("/api/v1/files/tus")
+	routerGroup.Use(authenticationMiddleware.VerifyAccount)
+
+	{
+		routerGroup.POST("", tusController.Create)
+		routerGroup.HEAD("/:id", tusController.Head)
+		routerGroup.PATCH("/:id", tusController.Patch)
+		routerGroup.DELETE("/:id", tusController.Delete)
+	}
+}
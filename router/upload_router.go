@@ -8,14 +8,31 @@ import (
 
 func UploadRouter(r *gin.Engine, middleware provider.MiddlewareProvider, controller provider.ControllerProvider) {
     uploadController := controller.ProvideUploadController()
+    fileController := controller.ProvideFileController()
+    quotaController := controller.ProvideQuotaController()
+    directUploadController := controller.ProvideDirectUploadController()
+    downloadController := controller.ProvideDownloadController()
     authenticationMiddleware := middleware.ProvideAuthenticationMiddleware()
 
     routerGroup := r.Group("/api/v1/files")
-    routerGroup.Use(gzip.Gzip(gzip.DefaultCompression))
+    // Downloads are served as stored: compressing them would break byte ranges, and a ZIP
+    // is compressed already.
+    routerGroup.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPathsRegexs([]string{`/content$`, `/archive$`})))
     routerGroup.Use(authenticationMiddleware.VerifyAccount)
 
     {
         routerGroup.POST("/", uploadController.Upload)
+        routerGroup.GET("", fileController.List)
+        routerGroup.GET("/trash", fileController.ListTrash)
+        routerGroup.GET("/usage", quotaController.Usage)
+        routerGroup.POST("/archive", downloadController.Archive)
+        routerGroup.POST("/direct", directUploadController.CreateSlot)
+        routerGroup.POST("/direct/:id/complete", directUploadController.Complete)
         routerGroup.GET("/:id", uploadController.GetFileByID)
+        routerGroup.GET("/:id/content", downloadController.Content)
+        routerGroup.PATCH("/:id", fileController.Update)
+        routerGroup.DELETE("/:id", fileController.Trash)
+        routerGroup.POST("/:id/restore", fileController.Restore)
+        routerGroup.DELETE("/:id/permanent", fileController.Delete)
     }
 }
\ No newline at end of file
@@ -62,7 +62,7 @@ func (s *accountService) Create(ctx context.Context, name string, email string,
 		return entity.Account{}, err
 	}
 
-	acc := entity.Account{Email: email, Username: username, Password: string(bytes), Role: "user"}
+	acc := entity.Account{Email: email, Username: username, Password: string(bytes), Role: entity.RoleUser}
 	created, err := s.accountRepo.CreateAccount(ctx, acc)
 
 	if err != nil {
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const blobVerifyBatchSize = 50
+
+// BlobService stores file content by its SHA-256 digest. Identical content shares one
+// storage object, counted by the blob rows referencing it, and is deleted with its last
+// reference. Public and private content never share an object, as only private keys are
+// kept from being served without a signature.
+type BlobService interface {
+	Store(ctx context.Context, open func() (io.ReadCloser, error), prefix string, ext string, contentType string, private bool) (entity.Blob, error)
+	Release(ctx context.Context, storageKey string) error
+	VerifyIntegrity(ctx context.Context) (int, int, error)
+}
+
+type blobService struct {
+	transactor     repositories.Transactor
+	jobConfig      config.JobConfig
+	storageService StorageService
+	blobRepo       repositories.BlobRepository
+}
+
+func NewBlobService(transactor repositories.Transactor, jobConfig config.JobConfig, storageService StorageService, blobRepo repositories.BlobRepository) BlobService {
+	return &blobService{
+		transactor:     transactor,
+		jobConfig:      jobConfig,
+		storageService: storageService,
+		blobRepo:       blobRepo,
+	}
+}
+
+// Store hashes the content as it streams, then either adds a reference to the blob that
+// already holds it or uploads it under its content key below prefix. open is called again
+// for the upload, so it must return the same bytes each time. Content already stored keeps
+// its key, whatever the prefix. Content known to be corrupted is uploaded again, which
+// repairs the blob. Called inside a transaction, the reference is only counted if that
+// transaction commits; should it roll back, a new object is deleted again.
+func (s *blobService) Store(ctx context.Context, open func() (io.ReadCloser, error), prefix string, ext string, contentType string, private bool) (entity.Blob, error) {
+	digest, size, err := hashContent(open)
+	if err != nil {
+		return entity.Blob{}, fmt.Errorf("%w: hashing upload: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+
+	var blob entity.Blob
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.blobRepo.LockContent(ctx, digest); err != nil {
+			return err
+		}
+		existing, err := s.blobRepo.GetByContent(ctx, digest, private)
+		if err == nil {
+			if existing.CorruptedAt != nil {
+				if _, err := s.upload(ctx, open, existing.StorageKey, contentType); err != nil {
+					return err
+				}
+				if err := s.blobRepo.MarkVerified(ctx, existing.Id, false); err != nil {
+					return err
+				}
+				log.Printf("[BLOB] 🩹 corrupted object %s repaired by a new upload", existing.StorageKey)
+			}
+			blob, err = s.blobRepo.AddRef(ctx, existing.Id, 1)
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		key := contentKey(prefix, digest, ext, private)
+		url, err := s.upload(ctx, open, key, contentType)
+		if err != nil {
+			return err
+		}
+		s.transactor.AfterRollback(ctx, func(ctx context.Context) {
+			s.deleteUnreferenced(ctx, digest, key)
+		})
+		if private {
+			url = ""
+		}
+		now := time.Now()
+		blob, err = s.blobRepo.Create(ctx, entity.Blob{
+			Id:         uuid.New(),
+			Sha256:     digest,
+			Private:    private,
+			StorageKey: key,
+			URL:        url,
+			MimeType:   contentType,
+			Size:       size,
+			RefCount:   1,
+			VerifiedAt: &now,
+		})
+		return err
+	})
+	return blob, err
+}
+
+// Release drops one reference to the object under storageKey and deletes the object
+// with the last one. Objects stored before deduplication, or in quarantine, have no blob
+// and are deleted as well. Called inside a transaction, the object is only deleted once
+// that transaction commits, so a rollback never leaves a row pointing at nothing.
+func (s *blobService) Release(ctx context.Context, storageKey string) error {
+	blob, err := s.blobRepo.GetByKey(ctx, storageKey)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.transactor.AfterCommit(ctx, func(ctx context.Context) {
+			if err := s.storageService.DeleteFile(ctx, storageKey); err != nil {
+				log.Printf("[BLOB] ⚠️ object %s left behind: %v", storageKey, err)
+			}
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.blobRepo.LockContent(ctx, blob.Sha256); err != nil {
+			return err
+		}
+		if _, err := s.blobRepo.GetByKey(ctx, storageKey); errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		released, err := s.blobRepo.AddRef(ctx, blob.Id, -1)
+		if err != nil {
+			return err
+		}
+		if released.RefCount > 0 {
+			return nil
+		}
+		if err := s.blobRepo.Delete(ctx, blob.Id); err != nil {
+			return err
+		}
+		s.transactor.AfterCommit(ctx, func(ctx context.Context) {
+			s.deleteUnreferenced(ctx, blob.Sha256, storageKey)
+		})
+		return nil
+	})
+}
+
+// deleteUnreferenced deletes the object under storageKey unless a blob references it.
+// It holds the content lock, so an upload of the same content that created a blob in
+// the meantime keeps its object. A failure only leaves an orphan behind, so it is logged.
+func (s *blobService) deleteUnreferenced(ctx context.Context, digest string, storageKey string) {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.blobRepo.LockContent(ctx, digest); err != nil {
+			return err
+		}
+		if _, err := s.blobRepo.GetByKey(ctx, storageKey); err == nil {
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return s.storageService.DeleteFile(ctx, storageKey)
+	})
+	if err != nil {
+		log.Printf("[BLOB] ⚠️ object %s left behind: %v", storageKey, err)
+	}
+}
+
+// VerifyIntegrity downloads and hashes again every blob not verified within the
+// integrity interval, flagging those whose content changed or disappeared. It returns
+// how many blobs it checked and how many of them are corrupted. A storage failure stops
+// the run rather than flag blobs it could not read.
+func (s *blobService) VerifyIntegrity(ctx context.Context) (int, int, error) {
+	since := time.Now().Add(-s.jobConfig.GetIntegrityInterval())
+	checked, corrupted := 0, 0
+	after := uuid.Nil
+	for {
+		blobs, err := s.blobRepo.ListUnverified(ctx, since, after, blobVerifyBatchSize)
+		if err != nil {
+			return checked, corrupted, err
+		}
+		for _, blob := range blobs {
+			intact, err := s.verify(ctx, blob)
+			if err != nil {
+				return checked, corrupted, err
+			}
+			if err := s.blobRepo.MarkVerified(ctx, blob.Id, !intact); err != nil {
+				return checked, corrupted, err
+			}
+			checked++
+			if !intact {
+				corrupted++
+				log.Printf("[BLOB] ⚠️ object %s does not match its digest %s", blob.StorageKey, blob.Sha256)
+			}
+			after = blob.Id
+		}
+		if len(blobs) < blobVerifyBatchSize {
+			return checked, corrupted, nil
+		}
+	}
+}
+
+// verify reports whether the stored object still has the blob's digest and size,
+// streaming it through the hash. A missing object is not intact.
+func (s *blobService) verify(ctx context.Context, blob entity.Blob) (bool, error) {
+	content, err := s.storageService.OpenFile(ctx, blob.StorageKey, 0)
+	if errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer content.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return false, fmt.Errorf("%w: reading %s: %v", http_error.INTERNAL_SERVER_ERROR, blob.StorageKey, err)
+	}
+	return size == blob.Size && hex.EncodeToString(hash.Sum(nil)) == blob.Sha256, nil
+}
+
+func (s *blobService) upload(ctx context.Context, open func() (io.ReadCloser, error), key string, contentType string) (string, error) {
+	src, err := open()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+	defer src.Close()
+	url, err := s.storageService.UploadFile(ctx, src, key, contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	return url, nil
+}
+
+// hashContent streams the content through SHA-256, returning the hex digest and size.
+func hashContent(open func() (io.ReadCloser, error)) (string, int64, error) {
+	src, err := open()
+	if err != nil {
+		return "", 0, err
+	}
+	defer src.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, src)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// contentKey is "[private/]<prefix>/<first two hex digits>/<digest><ext>", prefix being
+// "objects" when empty; the two-digit level keeps directories of the local backend small.
+func contentKey(prefix string, digest string, ext string, private bool) string {
+	if prefix == "" {
+		prefix = "objects"
+	}
+	key := prefix + "/" + digest[:2] + "/" + digest + ext
+	if private {
+		return config.PrivateStoragePrefix + key
+	}
+	return key
+}
+
+// bytesSource opens data in memory, for content Store should hash and upload.
+func bytesSource(data []byte) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+}
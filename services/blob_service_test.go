@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+)
+
+func TestBlobVerify(t *testing.T) {
+	storage := NewMemoryStorageService()
+	ctx := context.Background()
+	storage.UploadFile(ctx, strings.NewReader("content"), "objects/ab/intact", "text/plain")
+	storage.UploadFile(ctx, strings.NewReader("tampered"), "objects/ab/changed", "text/plain")
+	s := &blobService{storageService: storage}
+
+	digest := sha256.Sum256([]byte("content"))
+	tests := []struct {
+		name string
+		key  string
+		size int64
+		want bool
+	}{
+		{"intact", "objects/ab/intact", 7, true},
+		{"other size", "objects/ab/intact", 8, false},
+		{"changed content", "objects/ab/changed", 7, false},
+		{"missing object", "objects/ab/missing", 7, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intact, err := s.verify(ctx, entity.Blob{StorageKey: tt.key, Size: tt.size, Sha256: hex.EncodeToString(digest[:])})
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if intact != tt.want {
+				t.Errorf("verify = %v, want %v", intact, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// checkoutReuseMargin is how long an open invoice must still be payable to be handed out
+// again instead of issuing a new one.
+const checkoutReuseMargin = 10 * time.Minute
+
+type CheckoutService interface {
+	Checkout(ctx context.Context, accountId uuid.UUID, productCode string, req dto.CheckoutRequest) (dto.CheckoutResponse, error)
+}
+
+type checkoutService struct {
+	productService      ProductService
+	paymentService      PaymentService
+	voucherService      VoucherService
+	subscriptionService SubscriptionService
+	paymentRepo         repositories.PaymentRepository
+}
+
+func NewCheckoutService(productService ProductService, paymentService PaymentService, voucherService VoucherService, subscriptionService SubscriptionService, paymentRepo repositories.PaymentRepository) CheckoutService {
+	return &checkoutService{
+		productService:      productService,
+		paymentService:      paymentService,
+		voucherService:      voucherService,
+		subscriptionService: subscriptionService,
+		paymentRepo:         paymentRepo,
+	}
+}
+
+// Checkout bills the account for a product price, discounted by the requested voucher. An
+// open invoice for the same price and voucher is returned again rather than billing twice.
+func (s *checkoutService) Checkout(ctx context.Context, accountId uuid.UUID, productCode string, req dto.CheckoutRequest) (dto.CheckoutResponse, error) {
+	product, err := s.productService.GetByCode(ctx, productCode)
+	if err != nil {
+		return dto.CheckoutResponse{}, err
+	}
+	price, err := pickPrice(product, req.PriceId)
+	if err != nil {
+		return dto.CheckoutResponse{}, err
+	}
+
+	var voucher entity.Voucher
+	var voucherId *uuid.UUID
+	if req.VoucherCode != "" {
+		if voucher, err = s.voucherService.Find(ctx, req.VoucherCode); err != nil {
+			return dto.CheckoutResponse{}, err
+		}
+		voucherId = &voucher.Id
+	}
+
+	payment, err := s.paymentRepo.GetOpenByAccountAndPrice(ctx, accountId, price.Id, voucherId, time.Now().Add(checkoutReuseMargin))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		payment, err = s.charge(ctx, accountId, product, price, voucher.Code)
+	}
+	if err != nil {
+		return dto.CheckoutResponse{}, err
+	}
+
+	return dto.CheckoutResponse{
+		ProductId:   product.Id,
+		ProductCode: product.Code,
+		ProductName: product.Name,
+		PriceId:     price.Id,
+		PaymentId:   payment.Id,
+		Status:      payment.Status,
+		InvoiceURL:  payment.InvoiceURL,
+		Subtotal:    payment.Amount + payment.DiscountAmount,
+		VoucherCode: voucher.Code,
+		Discount:    payment.DiscountAmount,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		ExpiresAt:   payment.ExpiresAt,
+	}, nil
+}
+
+// charge creates the payment for a price, through a new subscription for a plan price.
+// With a voucher, a redemption is reserved under the payment's id first and released
+// again if the payment cannot be created.
+func (s *checkoutService) charge(ctx context.Context, accountId uuid.UUID, product entity.Product, price entity.Price, voucherCode string) (entity.Payment, error) {
+	payment := entity.Payment{
+		Amount:      price.Amount,
+		Currency:    price.Currency,
+		Description: product.Name,
+		ProductId:   &product.Id,
+		PriceId:     &price.Id,
+	}
+	if voucherCode != "" {
+		payment.Id = uuid.New()
+		voucher, discount, err := s.voucherService.Reserve(ctx, accountId, voucherCode, product, price, payment.Id)
+		if err != nil {
+			return entity.Payment{}, err
+		}
+		payment.VoucherId = &voucher.Id
+		payment.DiscountAmount = discount
+		payment.Amount = price.Amount - discount
+	}
+
+	var charged entity.Payment
+	var err error
+	if price.IntervalMonths > 0 {
+		charged, err = s.subscriptionService.Start(ctx, accountId, price, payment)
+	} else {
+		charged, err = s.paymentService.Charge(ctx, accountId, payment)
+	}
+	if err != nil && payment.VoucherId != nil {
+		if releaseErr := s.voucherService.Release(ctx, payment.Id); releaseErr != nil {
+			log.Printf("[CHECKOUT] release voucher %s of payment %s failed: %v", voucherCode, payment.Id, releaseErr)
+		}
+	}
+	return charged, err
+}
+
+// pickPrice returns the requested active price of the product, or its cheapest one.
+func pickPrice(product entity.Product, priceId *uuid.UUID) (entity.Price, error) {
+	for _, price := range product.Prices {
+		if priceId == nil || price.Id == *priceId {
+			return price, nil
+		}
+	}
+	return entity.Price{}, http_error.NOT_FOUND_ERROR
+}
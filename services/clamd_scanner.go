@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	clamdBootTimeout = 10 * time.Second
+	clamdChunkSize   = 64 * 1024
+)
+
+// clamdScanner streams files to a ClamAV daemon with the INSTREAM command, over TCP or a
+// unix socket. Each scan uses its own connection.
+type clamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner checks that clamd answers PING.
+func NewClamdScanner(network string, address string, timeout time.Duration) (Scanner, error) {
+	s := &clamdScanner{network: network, address: address, timeout: timeout}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clamdBootTimeout)
+	defer cancel()
+	reply, err := s.command(ctx, "PING", nil)
+	if err != nil {
+		return nil, fmt.Errorf("clamd at %s is not reachable: %w", address, err)
+	}
+	if reply != "PONG" {
+		return nil, fmt.Errorf("clamd at %s answered %q to PING", address, reply)
+	}
+	return s, nil
+}
+
+// Scan reads "stream: OK" as clean and "stream: <signature> FOUND" as infected; any
+// other answer, such as a size limit error, is an error.
+func (s *clamdScanner) Scan(ctx context.Context, content io.Reader) (ScanResult, error) {
+	reply, err := s.command(ctx, "INSTREAM", content)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("clamd scan: %w", err)
+	}
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return ScanResult{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return ScanResult{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("clamd scan: %s", reply)
+	}
+}
+
+// command sends a null-terminated command and, for INSTREAM, the content as
+// length-prefixed chunks ended by an empty one, then reads the null-terminated reply.
+func (s *clamdScanner) command(ctx context.Context, name string, content io.Reader) (string, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	_, writeErr := conn.Write([]byte("z" + name + "\x00"))
+	if writeErr == nil && content != nil {
+		writeErr = writeClamdChunks(conn, content)
+	}
+	// clamd answers and hangs up when a stream breaks its limits, so its reply is read
+	// even after a failed write.
+	reply, readErr := bufio.NewReader(conn).ReadString(0)
+	reply = strings.TrimSpace(strings.TrimSuffix(reply, "\x00"))
+	if reply != "" {
+		return reply, nil
+	}
+	if writeErr != nil {
+		return "", writeErr
+	}
+	return "", readErr
+}
+
+func writeClamdChunks(conn net.Conn, content io.Reader) error {
+	buffer := make([]byte, 4+clamdChunkSize)
+	for {
+		n, err := io.ReadFull(content, buffer[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buffer, uint32(n))
+			if _, writeErr := conn.Write(buffer[:4+n]); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
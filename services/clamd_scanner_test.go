@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd speaks the clamd protocol on a local listener. It answers PING, and INSTREAM
+// by looking for the EICAR marker in the streamed content; streams over maxStream bytes
+// are cut off with clamd's size limit error.
+type fakeClamd struct {
+	t         *testing.T
+	listener  net.Listener
+	maxStream int
+	streamed  chan []byte
+}
+
+const eicarMarker = "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"
+
+func newFakeClamd(t *testing.T) *fakeClamd {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeClamd{t: t, listener: listener, maxStream: 1 << 20, streamed: make(chan []byte, 8)}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	command, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+	switch command {
+	case "zPING\x00":
+		conn.Write([]byte("PONG\x00"))
+	case "zINSTREAM\x00":
+		var content bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if size > clamdChunkSize {
+				f.t.Errorf("chunk of %d bytes, over %d", size, clamdChunkSize)
+			}
+			if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+				return
+			}
+			if content.Len() > f.maxStream {
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				return
+			}
+		}
+		f.streamed <- content.Bytes()
+		if bytes.Contains(content.Bytes(), []byte(eicarMarker)) {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+			return
+		}
+		conn.Write([]byte("stream: OK\x00"))
+	default:
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+func TestClamdScanner(t *testing.T) {
+	clamd := newFakeClamd(t)
+	scanner, err := NewClamdScanner("tcp", clamd.listener.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewClamdScanner: %v", err)
+	}
+
+	large := bytes.Repeat([]byte("0123456789abcdef"), 10000)
+	tests := []struct {
+		name    string
+		content []byte
+		want    ScanResult
+	}{
+		{"clean", []byte("hello"), ScanResult{}},
+		{"empty", nil, ScanResult{}},
+		{"several chunks", large, ScanResult{}},
+		{"infected", append(append([]byte{}, large...), eicarMarker...), ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scanner.Scan(context.Background(), bytes.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan = %+v, want %+v", got, tt.want)
+			}
+			if streamed := <-clamd.streamed; !bytes.Equal(streamed, tt.content) {
+				t.Errorf("clamd received %d bytes, want the %d scanned", len(streamed), len(tt.content))
+			}
+		})
+	}
+}
+
+func TestClamdScannerSizeLimit(t *testing.T) {
+	clamd := newFakeClamd(t)
+	clamd.maxStream = clamdChunkSize
+	scanner, err := NewClamdScanner("tcp", clamd.listener.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewClamdScanner: %v", err)
+	}
+	_, err = scanner.Scan(context.Background(), bytes.NewReader(make([]byte, 4*1024*1024)))
+	if err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Errorf("Scan over the limit: err = %v, want clamd's size limit error", err)
+	}
+}
+
+func TestClamdScannerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	if _, err := NewClamdScanner("tcp", address, time.Second); err == nil {
+		t.Fatal("NewClamdScanner succeeded without clamd")
+	}
+}
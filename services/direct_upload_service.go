@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	directUploadCleanupBatchSize = 100
+
+	// directUploadPrefix keeps objects uploaded by clients apart until they are completed.
+	directUploadPrefix = config.PrivateStoragePrefix + "direct/"
+)
+
+// DirectUploadService lets clients upload a file to storage themselves, in two steps:
+// CreateSlot checks the file against its upload rule and the quota and hands out a
+// presigned URL; Complete checks what was uploaded and stores it as a File. Only backends
+// that presign uploads, S3 and Supabase, support it.
+type DirectUploadService interface {
+	CreateSlot(ctx context.Context, accountId uuid.UUID, req dto.UploadSlotRequest) (dto.UploadSlotResponse, error)
+	Complete(ctx context.Context, accountId uuid.UUID, slotId uuid.UUID) (*entity.File, error)
+	CleanupExpired(ctx context.Context) (int, error)
+}
+
+type directUploadService struct {
+	transactor     repositories.Transactor
+	storageConfig  config.StorageConfig
+	storageService StorageService
+	ruleService    UploadRuleService
+	uploadService  UploadService
+	quotaService   QuotaService
+	slotRepo       repositories.UploadSlotRepository
+}
+
+func NewDirectUploadService(transactor repositories.Transactor, storageConfig config.StorageConfig, storageService StorageService, ruleService UploadRuleService, uploadService UploadService, quotaService QuotaService, slotRepo repositories.UploadSlotRepository) DirectUploadService {
+	return &directUploadService{
+		transactor:     transactor,
+		storageConfig:  storageConfig,
+		storageService: storageService,
+		ruleService:    ruleService,
+		uploadService:  uploadService,
+		quotaService:   quotaService,
+		slotRepo:       slotRepo,
+	}
+}
+
+// CreateSlot refuses a file its rule or the quota of the account would refuse before any
+// byte is sent. The upload URL only accepts a PUT of the announced size where the backend
+// can enforce it; Complete checks the size in any case.
+func (s *directUploadService) CreateSlot(ctx context.Context, accountId uuid.UUID, req dto.UploadSlotRequest) (dto.UploadSlotResponse, error) {
+	uploader, ok := s.storageService.(DirectUploader)
+	if !ok {
+		return dto.UploadSlotResponse{}, fmt.Errorf("%w: direct uploads need the s3 or supabase storage driver", http_error.BAD_REQUEST_ERROR)
+	}
+	filename := filepath.Base(strings.TrimSpace(req.Filename))
+	if filename == "" || filename == "." || filename == "/" {
+		return dto.UploadSlotResponse{}, fmt.Errorf("%w: filename is required", http_error.BAD_REQUEST_ERROR)
+	}
+	rule, err := s.ruleService.ForAccount(ctx, accountId, req.Context, filename)
+	if err != nil {
+		return dto.UploadSlotResponse{}, err
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	if req.Size <= 0 || req.Size > rule.MaxBytes {
+		return dto.UploadSlotResponse{}, http_error.FILE_TOO_LARGE
+	}
+	if !ruleAllowsExt(rule, ext) {
+		return dto.UploadSlotResponse{}, http_error.INVALID_FILE_TYPE
+	}
+	if err := s.quotaService.Check(ctx, accountId, req.Size, 1); err != nil {
+		return dto.UploadSlotResponse{}, err
+	}
+
+	slotId := uuid.New()
+	ttl := s.storageConfig.GetDirectUploadTTL()
+	slot, err := s.slotRepo.Create(ctx, entity.UploadSlot{
+		Id:         slotId,
+		AccountId:  accountId,
+		Context:    rule.Context,
+		Filename:   filename,
+		Size:       req.Size,
+		StorageKey: directUploadPrefix + slotId.String() + ext,
+		ExpiresAt:  time.Now().Add(ttl),
+	})
+	if err != nil {
+		return dto.UploadSlotResponse{}, err
+	}
+	uploadURL, err := uploader.PresignUpload(ctx, slot.StorageKey, slot.Size, ttl)
+	if err != nil {
+		s.slotRepo.Delete(ctx, slot.Id)
+		return dto.UploadSlotResponse{}, err
+	}
+	return dto.UploadSlotResponse{
+		Id:        slot.Id,
+		Context:   slot.Context,
+		Filename:  slot.Filename,
+		Size:      slot.Size,
+		Method:    http.MethodPut,
+		UploadURL: uploadURL,
+		ExpiresAt: slot.ExpiresAt,
+	}, nil
+}
+
+// Complete checks the size of the uploaded object and stores it like a multipart upload,
+// sniffing its content against the rule; the object is streamed from storage, never read
+// into memory whole. The slot is locked meanwhile, so a repeated completion waits and
+// then returns the same file. A file that is refused ends the slot; a storage failure
+// keeps it for a retry.
+func (s *directUploadService) Complete(ctx context.Context, accountId uuid.UUID, slotId uuid.UUID) (*entity.File, error) {
+	var slot entity.UploadSlot
+	var file *entity.File
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		slot, err = s.slotRepo.LockById(ctx, slotId)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && slot.AccountId != accountId) {
+			return http_error.NOT_FOUND_ERROR
+		}
+		if err != nil {
+			return err
+		}
+		if slot.FileId != nil {
+			file, err = s.uploadService.GetFileByID(ctx, *slot.FileId, accountId)
+			return err
+		}
+		if time.Now().After(slot.ExpiresAt) {
+			return http_error.UPLOAD_EXPIRED
+		}
+
+		if err := s.checkSize(ctx, slot); err != nil {
+			return err
+		}
+		if file, err = s.uploadService.UploadObject(ctx, slot.StorageKey, slot.Size, slot.Filename, slot.Context, slot.AccountId); err != nil {
+			return err
+		}
+		return s.slotRepo.SetFile(ctx, slot.Id, file.Id)
+	})
+	if err != nil {
+		if slot.Id != uuid.Nil && slot.FileId == nil && refusesSlot(err) {
+			if removeErr := s.remove(ctx, slot); removeErr != nil {
+				log.Printf("[DIRECT] removing refused upload %s failed: %v", slot.Id, removeErr)
+			}
+		}
+		return nil, err
+	}
+	if slot.FileId == nil {
+		if err := s.storageService.DeleteFile(ctx, slot.StorageKey); err != nil {
+			log.Printf("[DIRECT] removing uploaded object of %s failed: %v", slot.Id, err)
+		}
+	}
+	return file, nil
+}
+
+// checkSize makes sure the object uploaded to the slot has the announced size, without
+// reading it: it opens the object at its last byte and expects exactly one byte there.
+func (s *directUploadService) checkSize(ctx context.Context, slot entity.UploadSlot) error {
+	tail, err := s.storageService.OpenFile(ctx, slot.StorageKey, slot.Size-1)
+	if errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		return fmt.Errorf("%w: nothing was uploaded to the slot yet", http_error.BAD_REQUEST_ERROR)
+	}
+	if err != nil {
+		return err
+	}
+	defer tail.Close()
+	n, err := io.Copy(io.Discard, io.LimitReader(tail, 2))
+	if err != nil {
+		return fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+	switch {
+	case n == 0:
+		return fmt.Errorf("%w: fewer than %d bytes were uploaded", http_error.INVALID_DATA_PAYLOAD, slot.Size)
+	case n > 1:
+		return fmt.Errorf("%w: more than %d bytes were uploaded", http_error.INVALID_DATA_PAYLOAD, slot.Size)
+	}
+	return nil
+}
+
+// CleanupExpired removes slots past their expiry together with anything uploaded to
+// them and returns how many it removed. Files of completed slots are kept.
+func (s *directUploadService) CleanupExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		expired, err := s.slotRepo.ListExpired(ctx, time.Now(), directUploadCleanupBatchSize)
+		if err != nil {
+			return removed, err
+		}
+		for _, slot := range expired {
+			if err := s.remove(ctx, slot); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(expired) < directUploadCleanupBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// remove deletes the uploaded object, if the slot was never completed, and the slot.
+func (s *directUploadService) remove(ctx context.Context, slot entity.UploadSlot) error {
+	if slot.FileId == nil {
+		if err := s.storageService.DeleteFile(ctx, slot.StorageKey); err != nil && !errors.Is(err, http_error.NOT_FOUND_ERROR) {
+			return err
+		}
+	}
+	return s.slotRepo.Delete(ctx, slot.Id)
+}
+
+// refusesSlot tells the errors that end a slot from those a retry may get past, such as
+// a storage failure, an upload not sent yet or a full quota.
+func refusesSlot(err error) bool {
+	return errors.Is(err, http_error.FILE_TOO_LARGE) ||
+		errors.Is(err, http_error.INVALID_FILE_TYPE) ||
+		errors.Is(err, http_error.INVALID_DATA_PAYLOAD) ||
+		errors.Is(err, http_error.INVALID_UPLOAD_CONTEXT_ERROR) ||
+		errors.Is(err, http_error.FORBIDDEN_ERROR) ||
+		errors.Is(err, http_error.UPLOAD_EXPIRED)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/utils"
+)
+
+func TestDirectUploadCheckSize(t *testing.T) {
+	storage := NewMemoryStorageService()
+	ctx := context.Background()
+	storage.UploadFile(ctx, strings.NewReader("0123456789"), "direct/a.txt", "text/plain")
+	s := &directUploadService{storageService: storage}
+
+	tests := []struct {
+		name string
+		key  string
+		size int64
+		want error
+	}{
+		{"exact size", "direct/a.txt", 10, nil},
+		{"fewer bytes", "direct/a.txt", 11, http_error.INVALID_DATA_PAYLOAD},
+		{"more bytes", "direct/a.txt", 9, http_error.INVALID_DATA_PAYLOAD},
+		{"nothing uploaded", "direct/b.txt", 10, http_error.BAD_REQUEST_ERROR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkSize(ctx, entity.UploadSlot{StorageKey: tt.key, Size: tt.size})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("checkSize = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestObjectReaderDetectsZipInPlace sniffs an Office file through ranged reads of storage.
+func TestObjectReaderDetectsZipInPlace(t *testing.T) {
+	storage := NewMemoryStorageService()
+	ctx := context.Background()
+	var sheet bytes.Buffer
+	writer, err := utils.NewXLSXWriter(&sheet, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.WriteRow([]interface{}{"id", "name"})
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	storage.UploadFile(ctx, bytes.NewReader(sheet.Bytes()), "direct/sheet.xlsx", utils.MimeXlsx)
+
+	reader := &objectReader{ctx: ctx, storage: storage, key: "direct/sheet.xlsx", size: int64(sheet.Len())}
+	got, err := utils.DetectFileType(reader, reader.size)
+	if err != nil || got != utils.MimeXlsx {
+		t.Errorf("DetectFileType = %s, %v; want %s", got, err, utils.MimeXlsx)
+	}
+
+	shrunk := &objectReader{ctx: ctx, storage: storage, key: "direct/sheet.xlsx", size: int64(sheet.Len()) + 10}
+	if _, err := shrunk.ReadAt(make([]byte, 20), int64(sheet.Len())-10); err == nil {
+		t.Error("ReadAt past the end of a shorter object succeeded")
+	}
+}
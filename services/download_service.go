@@ -0,0 +1,280 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"abdanhafidz.com/go-boilerplate/utils"
+	"github.com/google/uuid"
+)
+
+// defaultArchiveName names a ZIP download the request did not name.
+const defaultArchiveName = "files"
+
+// DownloadService streams file contents through the API instead of a storage URL, for
+// every storage driver, and keeps an audit trail of the downloads. Several files can be
+// downloaded at once as a ZIP: PrepareArchive selects and checks them before anything is
+// sent, and WriteArchive streams the archive. A nil accountId acts as an admin on the
+// files of every account.
+type DownloadService interface {
+	Open(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, io.ReadSeekCloser, error)
+	Record(ctx context.Context, download entity.FileDownload) error
+	List(ctx context.Context, fileId uuid.UUID, pagination entity.Pagination) ([]entity.FileDownload, int64, error)
+	PrepareArchive(ctx context.Context, accountId *uuid.UUID, req dto.FileArchiveRequest) (string, []entity.File, error)
+	WriteArchive(ctx context.Context, w io.Writer, files []entity.File, download entity.FileDownload) error
+}
+
+type downloadService struct {
+	storageConfig  config.StorageConfig
+	storageService StorageService
+	fileRepo       repositories.FileRepository
+	downloadRepo   repositories.FileDownloadRepository
+}
+
+func NewDownloadService(storageConfig config.StorageConfig, storageService StorageService, fileRepo repositories.FileRepository, downloadRepo repositories.FileDownloadRepository) DownloadService {
+	return &downloadService{
+		storageConfig:  storageConfig,
+		storageService: storageService,
+		fileRepo:       fileRepo,
+		downloadRepo:   downloadRepo,
+	}
+}
+
+// Open returns a file with a reader of its content that seeks without fetching it, so
+// a ranged request only reads its range from storage. Trashed files are not found, and
+// a file not scanned clean cannot be downloaded, like it gets no URL.
+func (s *downloadService) Open(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, io.ReadSeekCloser, error) {
+	file, err := s.fileRepo.FindByID(ctx, fileId)
+	if err != nil {
+		return entity.File{}, nil, err
+	}
+	if accountId != nil && file.AccountId != *accountId {
+		return entity.File{}, nil, http_error.NOT_FOUND_ERROR
+	}
+	if file.ScanStatus != entity.ScanStatusClean {
+		return entity.File{}, nil, http_error.FILE_NOT_CLEAN
+	}
+	if file.StorageKey == "" {
+		return entity.File{}, nil, fmt.Errorf("%w: file %s has no stored content", http_error.NOT_FOUND_ERROR, file.Id)
+	}
+	return *file, &objectReader{ctx: ctx, storage: s.storageService, key: file.StorageKey, size: file.Size}, nil
+}
+
+func (s *downloadService) Record(ctx context.Context, download entity.FileDownload) error {
+	_, err := s.downloadRepo.Create(ctx, download)
+	return err
+}
+
+// List returns the downloads of a file newest first, also when it is in the trash.
+func (s *downloadService) List(ctx context.Context, fileId uuid.UUID, pagination entity.Pagination) ([]entity.FileDownload, int64, error) {
+	if _, err := s.fileRepo.FindByIDWithTrashed(ctx, fileId); err != nil {
+		return nil, 0, err
+	}
+	return s.downloadRepo.ListByFile(ctx, fileId, pagination)
+}
+
+// PrepareArchive returns the name of the archive and its files, either those listed, in
+// their order, or those matching the filter, oldest first. A listed file that is missing,
+// trashed or of another account fails the request as not found, and one not scanned clean
+// as FILE_NOT_CLEAN; a filter simply leaves such files out. Too many files or too many
+// bytes together fail with ARCHIVE_TOO_LARGE before anything is streamed.
+func (s *downloadService) PrepareArchive(ctx context.Context, accountId *uuid.UUID, req dto.FileArchiveRequest) (string, []entity.File, error) {
+	name := archiveEntryName(strings.TrimSpace(req.Name))
+	if name == "" {
+		name = defaultArchiveName
+	}
+	name += ".zip"
+
+	filtered := req.Context != "" || req.AccountId != nil || req.From != nil || req.To != nil
+	if len(req.FileIds) > 0 && filtered {
+		return "", nil, fmt.Errorf("%w: select files either by file_ids or by a filter", http_error.BAD_REQUEST_ERROR)
+	}
+	if len(req.FileIds) == 0 && !filtered {
+		return "", nil, fmt.Errorf("%w: select files by file_ids or a filter", http_error.BAD_REQUEST_ERROR)
+	}
+	if accountId != nil && req.AccountId != nil && *req.AccountId != *accountId {
+		return "", nil, http_error.FORBIDDEN_ERROR
+	}
+
+	maxFiles := s.storageConfig.GetArchiveMaxFiles()
+	var files []entity.File
+	var err error
+	if len(req.FileIds) > 0 {
+		files, err = s.archiveFilesByIds(ctx, accountId, req.FileIds, maxFiles)
+	} else {
+		files, err = s.archiveFilesByFilter(ctx, accountId, req, maxFiles)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	var total int64
+	for _, file := range files {
+		total += file.Size
+	}
+	if maxBytes := s.storageConfig.GetArchiveMaxBytes(); total > maxBytes {
+		return "", nil, fmt.Errorf("%w: the files hold %d bytes, at most %d can be archived", http_error.ARCHIVE_TOO_LARGE, total, maxBytes)
+	}
+	return name, files, nil
+}
+
+func (s *downloadService) archiveFilesByIds(ctx context.Context, accountId *uuid.UUID, ids []uuid.UUID, maxFiles int) ([]entity.File, error) {
+	seen := map[uuid.UUID]bool{}
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > maxFiles {
+		return nil, fmt.Errorf("%w: %d files were selected, at most %d can be archived", http_error.ARCHIVE_TOO_LARGE, len(unique), maxFiles)
+	}
+
+	found, err := s.fileRepo.FindByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[uuid.UUID]entity.File, len(found))
+	for _, file := range found {
+		byId[file.Id] = file
+	}
+	files := make([]entity.File, 0, len(unique))
+	for _, id := range unique {
+		file, ok := byId[id]
+		if !ok || (accountId != nil && file.AccountId != *accountId) {
+			return nil, fmt.Errorf("%w: file %s", http_error.NOT_FOUND_ERROR, id)
+		}
+		if file.ScanStatus != entity.ScanStatusClean {
+			return nil, fmt.Errorf("%w: file %s", http_error.FILE_NOT_CLEAN, id)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (s *downloadService) archiveFilesByFilter(ctx context.Context, accountId *uuid.UUID, req dto.FileArchiveRequest, maxFiles int) ([]entity.File, error) {
+	filter := dto.FileFilter{
+		AccountId:  req.AccountId,
+		Context:    req.Context,
+		ScanStatus: entity.ScanStatusClean,
+		Period:     dto.Period{From: req.From, To: req.To},
+	}
+	if accountId != nil {
+		filter.AccountId = accountId
+	}
+	files, total, err := s.fileRepo.List(ctx, filter, entity.Pagination{Limit: maxFiles, SortBy: "created_at", Order: "asc"})
+	if err != nil {
+		return nil, err
+	}
+	if total > int64(maxFiles) {
+		return nil, fmt.Errorf("%w: %d files match, at most %d can be archived", http_error.ARCHIVE_TOO_LARGE, total, maxFiles)
+	}
+	return files, nil
+}
+
+// WriteArchive streams a ZIP of the files to w, one stored object at a time, so neither
+// the archive nor a whole file is held in memory. Entries keep the display or original
+// name of their file, numbered when names collide. Each file written is recorded as a
+// download from the template; a failed record is logged and does not stop the archive.
+func (s *downloadService) WriteArchive(ctx context.Context, w io.Writer, files []entity.File, download entity.FileDownload) error {
+	archive := zip.NewWriter(w)
+	names := archiveEntryNames(files)
+	for i, file := range files {
+		method := zip.Deflate
+		if isCompressedMime(file.MimeType) {
+			method = zip.Store
+		}
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: names[i], Method: method, Modified: file.CreatedAt})
+		if err != nil {
+			return err
+		}
+		body, err := s.storageService.OpenFile(ctx, file.StorageKey, 0)
+		if err != nil {
+			return fmt.Errorf("archive file %s: %w", file.Id, err)
+		}
+		written, err := io.Copy(entry, body)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("archive file %s: %w", file.Id, err)
+		}
+
+		download.Id = uuid.Nil
+		download.FileId = file.Id
+		download.Bytes = written
+		if err := s.Record(ctx, download); err != nil {
+			log.Printf("[DOWNLOAD] recording archived download of %s failed: %v", file.Id, err)
+		}
+	}
+	return archive.Close()
+}
+
+// archiveEntryNames names the entries of an archive after their files. A name already
+// taken, compared without case as file systems often do, gets " (2)", " (3)" and so on
+// before its extension.
+func archiveEntryNames(files []entity.File) []string {
+	taken := map[string]bool{}
+	names := make([]string, len(files))
+	for i, file := range files {
+		name := archiveEntryName(file.DisplayName)
+		if name == "" {
+			name = archiveEntryName(file.OriginalName)
+		}
+		if name == "" {
+			name = file.Id.String() + path.Ext(file.StoredName)
+		}
+		ext := path.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		candidate := name
+		for n := 2; taken[strings.ToLower(candidate)]; n++ {
+			candidate = fmt.Sprintf("%s (%d)%s", base, n, ext)
+		}
+		taken[strings.ToLower(candidate)] = true
+		names[i] = candidate
+	}
+	return names
+}
+
+// archiveEntryName makes a name safe for an archive entry: no folders, so an entry
+// cannot escape where it is extracted, and no control characters.
+func archiveEntryName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == '\\':
+			return '_'
+		case r < 0x20 || r == 0x7F:
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if strings.Trim(name, ".") == "" {
+		return ""
+	}
+	return name
+}
+
+// isCompressedMime tells content that deflating would not shrink, which is stored as is.
+func isCompressedMime(mimeType string) bool {
+	switch {
+	case strings.HasPrefix(mimeType, "image/") && mimeType != "image/svg+xml" && mimeType != "image/bmp",
+		strings.HasPrefix(mimeType, "video/"),
+		strings.HasPrefix(mimeType, "audio/"):
+		return true
+	}
+	switch mimeType {
+	case utils.MimeZip, utils.MimeDocx, utils.MimeXlsx, utils.MimePptx, "application/pdf", "application/x-gzip", "application/x-rar-compressed", "application/x-7z-compressed":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+)
+
+// fakeArchiveFileRepo serves files from memory to PrepareArchive. Methods the tests do not
+// need are left to the embedded interface and panic when called.
+type fakeArchiveFileRepo struct {
+	repositories.FileRepository
+	files []entity.File
+}
+
+func (r *fakeArchiveFileRepo) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.File, error) {
+	var found []entity.File
+	for _, file := range r.files {
+		for _, id := range ids {
+			if file.Id == id {
+				found = append(found, file)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeArchiveFileRepo) List(ctx context.Context, filter dto.FileFilter, pagination entity.Pagination) ([]entity.File, int64, error) {
+	var matched []entity.File
+	for _, file := range r.files {
+		if filter.Context == file.Context && file.ScanStatus == filter.ScanStatus {
+			matched = append(matched, file)
+		}
+	}
+	total := int64(len(matched))
+	return matched[:min(len(matched), pagination.Limit)], total, nil
+}
+
+type fakeArchiveConfig struct {
+	config.StorageConfig
+	maxFiles int
+	maxBytes int64
+}
+
+func (c fakeArchiveConfig) GetArchiveMaxFiles() int   { return c.maxFiles }
+func (c fakeArchiveConfig) GetArchiveMaxBytes() int64 { return c.maxBytes }
+
+func TestArchiveEntryNames(t *testing.T) {
+	id := uuid.MustParse("7b1c6d1e-0000-4000-8000-000000000001")
+	tests := []struct {
+		name  string
+		files []entity.File
+		want  []string
+	}{
+		{
+			name:  "display name before original name",
+			files: []entity.File{{DisplayName: "Report.pdf", OriginalName: "scan.pdf"}, {OriginalName: "scan.pdf"}},
+			want:  []string{"Report.pdf", "scan.pdf"},
+		},
+		{
+			name:  "folders collapsed",
+			files: []entity.File{{OriginalName: "../x"}, {OriginalName: `dir\sub/file.txt`}},
+			want:  []string{".._x", "dir_sub_file.txt"},
+		},
+		{
+			name:  "control characters stripped",
+			files: []entity.File{{OriginalName: "a\x00b\nc\x7f.txt"}},
+			want:  []string{"abc.txt"},
+		},
+		{
+			name:  "collisions numbered without case",
+			files: []entity.File{{OriginalName: "a.txt"}, {OriginalName: "A.TXT"}, {OriginalName: "a.txt"}, {OriginalName: "a (2).txt"}},
+			want:  []string{"a.txt", "A (2).TXT", "a (3).txt", "a (2) (2).txt"},
+		},
+		{
+			name:  "names of dots fall back to the id",
+			files: []entity.File{{Id: id, OriginalName: "..", StoredName: "stored.png"}, {Id: id, DisplayName: "...", OriginalName: " . ", StoredName: "stored"}},
+			want:  []string{id.String() + ".png", id.String()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := archiveEntryNames(tt.files)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("entry %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPrepareArchiveCaps(t *testing.T) {
+	owner := uuid.New()
+	var files []entity.File
+	for i := 0; i < 3; i++ {
+		files = append(files, entity.File{Id: uuid.New(), AccountId: owner, Context: "docs", Size: 40, ScanStatus: entity.ScanStatusClean})
+	}
+	repo := &fakeArchiveFileRepo{files: files}
+	ids := []uuid.UUID{files[0].Id, files[1].Id, files[2].Id}
+
+	tests := []struct {
+		name     string
+		maxFiles int
+		maxBytes int64
+		req      dto.FileArchiveRequest
+		want     error
+	}{
+		{"within the caps", 3, 120, dto.FileArchiveRequest{FileIds: ids}, nil},
+		{"too many listed files", 2, 1000, dto.FileArchiveRequest{FileIds: ids}, http_error.ARCHIVE_TOO_LARGE},
+		{"repeated ids counted once", 2, 1000, dto.FileArchiveRequest{FileIds: []uuid.UUID{ids[0], ids[0], ids[1]}}, nil},
+		{"too many filtered files", 2, 1000, dto.FileArchiveRequest{Context: "docs"}, http_error.ARCHIVE_TOO_LARGE},
+		{"too many bytes", 3, 119, dto.FileArchiveRequest{FileIds: ids}, http_error.ARCHIVE_TOO_LARGE},
+		{"too many filtered bytes", 3, 119, dto.FileArchiveRequest{Context: "docs"}, http_error.ARCHIVE_TOO_LARGE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDownloadService(fakeArchiveConfig{maxFiles: tt.maxFiles, maxBytes: tt.maxBytes}, nil, repo, nil)
+			name, _, err := s.PrepareArchive(context.Background(), &owner, tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("PrepareArchive: err = %v, want %v", err, tt.want)
+			}
+			if err == nil && name != defaultArchiveName+".zip" {
+				t.Errorf("name = %q, want %s.zip", name, defaultArchiveName)
+			}
+		})
+	}
+}
@@ -3,46 +3,50 @@ package services
 import (
 	"context"
 	"errors"
-	"time"
+	"fmt"
 
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
 	entity "abdanhafidz.com/go-boilerplate/models/entity"
-	http_error "abdanhafidz.com/go-boilerplate/models/error"
-	"abdanhafidz.com/go-boilerplate/repositories"
 
 	"gorm.io/gorm"
 )
 
 type EmailVerificationService interface {
-	CreateToken(ctx context.Context, email string, token uint, due time.Time) (entity.EmailVerification, error)
-	VerifyToken(ctx context.Context, email string, token uint) error
-	DeleteByToken(ctx context.Context, token uint) error
+	CreateToken(ctx context.Context, email string) (dto.OTPIssuedResponse, error)
+	VerifyToken(ctx context.Context, email string, token string) error
+	RevokeToken(ctx context.Context, email string) error
 }
 
 type emailVerificationService struct {
-	accountService        AccountService
-	emailVerificationRepo repositories.EmailVerificationRepository
+	accountService AccountService
+	otpService     OTPService
+	mailService    MailService
 }
 
-func NewEmailVerificationService(accountService AccountService, emailVerificationRepo repositories.EmailVerificationRepository) EmailVerificationService {
-	return &emailVerificationService{accountService: accountService, emailVerificationRepo: emailVerificationRepo}
+func NewEmailVerificationService(accountService AccountService, otpService OTPService, mailService MailService) EmailVerificationService {
+	return &emailVerificationService{accountService: accountService, otpService: otpService, mailService: mailService}
 }
 
-func (s *emailVerificationService) CreateToken(ctx context.Context, email string, token uint, due time.Time) (entity.EmailVerification, error) {
+func (s *emailVerificationService) CreateToken(ctx context.Context, email string) (dto.OTPIssuedResponse, error) {
 	acc, err := s.accountService.GetByEmail(ctx, email)
-	
 	if err != nil {
-		return entity.EmailVerification{}, err
+		return dto.OTPIssuedResponse{}, err
 	}
 
-	if due.IsZero() {
-		due = time.Now().Add(15 * time.Minute)
+	code, otp, err := s.otpService.Issue(ctx, acc.Id, entity.OTPPurposeEmailVerification)
+	if err != nil {
+		return dto.OTPIssuedResponse{}, err
+	}
+
+	body := fmt.Sprintf("Your email verification code is %s.\nIt expires at %s.", code, otp.ExpiredAt.Format("15:04 MST"))
+	if err := s.mailService.Send(ctx, acc.Email, "Email Verification Code", body); err != nil {
+		return dto.OTPIssuedResponse{}, err
 	}
 
-	ev := entity.EmailVerification{AccountId: acc.Id, Token: token, IsExpired: false, CreatedAt: time.Now(), ExpiredAt: due}
-	return s.emailVerificationRepo.Create(ctx, ev)
+	return dto.OTPIssuedResponse{Email: acc.Email, Purpose: otp.Purpose, ExpiredAt: otp.ExpiredAt}, nil
 }
 
-func (s *emailVerificationService) VerifyToken(ctx context.Context, email string, token uint) error {
+func (s *emailVerificationService) VerifyToken(ctx context.Context, email string, token string) error {
 	acc, err := s.accountService.GetByEmail(ctx, email)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.New("account not found")
@@ -51,27 +55,19 @@ func (s *emailVerificationService) VerifyToken(ctx context.Context, email string
 		return err
 	}
 
-	ev, err := s.emailVerificationRepo.GetByAccountAndToken(ctx, acc.Id, token)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return http_error.INVALID_OTP
-	}
-	if err != nil {
+	if err := s.otpService.Verify(ctx, acc.Id, entity.OTPPurposeEmailVerification, token); err != nil {
 		return err
 	}
 
-	if ev.ExpiredAt.Before(time.Now()) {
-		_ = s.emailVerificationRepo.MarkExpired(ctx, ev.Id)
-		_ = s.emailVerificationRepo.DeleteByToken(ctx, ev.Token)
-		return http_error.EXPIRED_TOKEN
-	}
-
 	acc.IsEmailVerified = true
-	if _, err := s.accountService.Update(ctx, acc); err != nil {
-		return err
-	}
-	return s.emailVerificationRepo.MarkExpired(ctx, ev.Id)
+	_, err = s.accountService.Update(ctx, acc)
+	return err
 }
 
-func (s *emailVerificationService) DeleteByToken(ctx context.Context, token uint) error {
-	return s.emailVerificationRepo.DeleteByToken(ctx, token)
+func (s *emailVerificationService) RevokeToken(ctx context.Context, email string) error {
+	acc, err := s.accountService.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	return s.otpService.Revoke(ctx, acc.Id, entity.OTPPurposeEmailVerification)
 }
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EntitlementService grants product access on PAID payments and gates resources behind
+// it. It is registered as a PaymentListener.
+type EntitlementService interface {
+	PaymentListener
+	List(ctx context.Context, accountId uuid.UUID) ([]entity.Entitlement, error)
+	Require(ctx context.Context, accountId uuid.UUID, productCode string) (entity.Entitlement, dto.CheckoutResponse, error)
+}
+
+type entitlementService struct {
+	productService  ProductService
+	checkoutService CheckoutService
+	productRepo     repositories.ProductRepository
+	entitlementRepo repositories.EntitlementRepository
+}
+
+func NewEntitlementService(productService ProductService, checkoutService CheckoutService, productRepo repositories.ProductRepository, entitlementRepo repositories.EntitlementRepository) EntitlementService {
+	return &entitlementService{
+		productService:  productService,
+		checkoutService: checkoutService,
+		productRepo:     productRepo,
+		entitlementRepo: entitlementRepo,
+	}
+}
+
+// OnPaymentPaid grants the product of a checkout payment. Payments without a price, such
+// as free-amount payments, grant nothing, and subscription invoices are left to
+// SubscriptionService.
+func (s *entitlementService) OnPaymentPaid(ctx context.Context, payment entity.Payment) error {
+	if payment.ProductId == nil || payment.PriceId == nil || payment.SubscriptionId != nil {
+		return nil
+	}
+	price, err := s.productRepo.GetPriceById(ctx, *payment.PriceId)
+	if err != nil {
+		return err
+	}
+
+	startsAt := time.Now()
+	if payment.PaidAt != nil {
+		startsAt = *payment.PaidAt
+	}
+	var expiresAt *time.Time
+	if price.EntitlementDays > 0 {
+		end := startsAt.AddDate(0, 0, price.EntitlementDays)
+		expiresAt = &end
+	}
+
+	_, created, err := s.entitlementRepo.Create(ctx, entity.Entitlement{
+		AccountId: payment.AccountId,
+		ProductId: *payment.ProductId,
+		PaymentId: &payment.Id,
+		Source:    entity.EntitlementSourcePayment,
+		Status:    entity.EntitlementStatusActive,
+		StartsAt:  startsAt,
+		ExpiresAt: expiresAt,
+	})
+	if err == nil && created {
+		log.Printf("[ENTITLEMENT] granted product %s to %s by %s", payment.ProductId, payment.AccountId, payment.ExternalId)
+	}
+	return err
+}
+
+// OnPaymentRefunded revokes what the payment granted once it is refunded in full; a
+// partial refund keeps the access.
+func (s *entitlementService) OnPaymentRefunded(ctx context.Context, payment entity.Payment, refund entity.Refund) error {
+	if payment.Status != entity.PaymentStatusRefunded {
+		return nil
+	}
+	revoked, err := s.entitlementRepo.RevokeByPaymentId(ctx, payment.Id, time.Now())
+	if err == nil && revoked > 0 {
+		log.Printf("[ENTITLEMENT] revoked %d entitlement(s) of %s after refund %s", revoked, payment.ExternalId, refund.Id)
+	}
+	return err
+}
+
+func (s *entitlementService) List(ctx context.Context, accountId uuid.UUID) ([]entity.Entitlement, error) {
+	return s.entitlementRepo.ListByAccountId(ctx, accountId)
+}
+
+// Require returns the account's active entitlement to the product. Without one it
+// answers PAYMENT_REQUIRED together with a ready checkout for the product.
+func (s *entitlementService) Require(ctx context.Context, accountId uuid.UUID, productCode string) (entity.Entitlement, dto.CheckoutResponse, error) {
+	product, err := s.productService.GetByCode(ctx, productCode)
+	if err != nil {
+		return entity.Entitlement{}, dto.CheckoutResponse{}, err
+	}
+
+	entitlement, err := s.entitlementRepo.GetActive(ctx, accountId, product.Id, time.Now())
+	if err == nil {
+		return entitlement, dto.CheckoutResponse{}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Entitlement{}, dto.CheckoutResponse{}, err
+	}
+
+	checkout, err := s.checkoutService.Checkout(ctx, accountId, productCode, dto.CheckoutRequest{})
+	if err != nil {
+		return entity.Entitlement{}, dto.CheckoutResponse{}, err
+	}
+	return entity.Entitlement{}, checkout, http_error.PAYMENT_REQUIRED
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+)
+
+// protectedFileContexts holds files other records point at, such as receipt PDFs; they
+// can be renamed and tagged but not deleted.
+var protectedFileContexts = map[string]bool{"receipt": true}
+
+// FileService manages uploaded files after the upload: listing, metadata, the trash and
+// deletion. A nil accountId acts as an admin on the files of every account.
+type FileService interface {
+	List(ctx context.Context, filter dto.FileFilter, pagination entity.Pagination) ([]entity.File, int64, error)
+	Get(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error)
+	UpdateMetadata(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID, req dto.UpdateFileRequest) (entity.File, error)
+	Trash(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error)
+	Restore(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error)
+	Delete(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) error
+	CheckServable(ctx context.Context, storageKey string) error
+}
+
+type fileService struct {
+	transactor     repositories.Transactor
+	storageService StorageService
+	blobService    BlobService
+	quotaService   QuotaService
+	storageConfig  config.StorageConfig
+	fileRepo       repositories.FileRepository
+}
+
+func NewFileService(transactor repositories.Transactor, storageService StorageService, blobService BlobService, quotaService QuotaService, storageConfig config.StorageConfig, fileRepo repositories.FileRepository) FileService {
+	return &fileService{
+		transactor:     transactor,
+		storageService: storageService,
+		blobService:    blobService,
+		quotaService:   quotaService,
+		storageConfig:  storageConfig,
+		fileRepo:       fileRepo,
+	}
+}
+
+func (s *fileService) List(ctx context.Context, filter dto.FileFilter, pagination entity.Pagination) ([]entity.File, int64, error) {
+	files, total, err := s.fileRepo.List(ctx, filter, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range files {
+		if err := s.sign(ctx, &files[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return files, total, nil
+}
+
+// Get returns a file, also when it is in the trash.
+func (s *fileService) Get(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error) {
+	file, err := s.find(ctx, accountId, fileId)
+	if err != nil {
+		return entity.File{}, err
+	}
+	return file, s.sign(ctx, &file)
+}
+
+func (s *fileService) UpdateMetadata(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID, req dto.UpdateFileRequest) (entity.File, error) {
+	if _, err := s.find(ctx, accountId, fileId); err != nil {
+		return entity.File{}, err
+	}
+	if req.DisplayName != nil {
+		displayName := strings.TrimSpace(*req.DisplayName)
+		req.DisplayName = &displayName
+	}
+	if req.Tags != nil {
+		tags := normalizeTags(*req.Tags)
+		req.Tags = &tags
+	}
+	if err := s.fileRepo.UpdateMetadata(ctx, fileId, req.DisplayName, req.Tags); err != nil {
+		return entity.File{}, err
+	}
+	return s.Get(ctx, accountId, fileId)
+}
+
+// Trash hides a file from listings and downloads until it is restored or deleted.
+func (s *fileService) Trash(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error) {
+	file, err := s.find(ctx, accountId, fileId)
+	if err != nil {
+		return entity.File{}, err
+	}
+	if protectedFileContexts[file.Context] {
+		return entity.File{}, http_error.FORBIDDEN_ERROR
+	}
+	if file.DeletedAt.Valid {
+		return file, nil
+	}
+	if err := s.fileRepo.Trash(ctx, fileId); err != nil {
+		return entity.File{}, err
+	}
+	return s.Get(ctx, accountId, fileId)
+}
+
+func (s *fileService) Restore(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error) {
+	file, err := s.find(ctx, accountId, fileId)
+	if err != nil {
+		return entity.File{}, err
+	}
+	if file.DeletedAt.Valid {
+		if err := s.fileRepo.Restore(ctx, fileId); err != nil {
+			return entity.File{}, err
+		}
+	}
+	return s.Get(ctx, accountId, fileId)
+}
+
+// Delete releases the blobs of the file and its variants, gives its room back to the
+// quota of the owner and removes the row in one transaction, so a failed attempt releases
+// nothing and can simply be repeated. An object is only deleted from storage once the
+// transaction commits and no other file shares it.
+func (s *fileService) Delete(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) error {
+	file, err := s.find(ctx, accountId, fileId)
+	if err != nil {
+		return err
+	}
+	if protectedFileContexts[file.Context] {
+		return http_error.FORBIDDEN_ERROR
+	}
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, variant := range file.Variants {
+			if err := s.blobService.Release(ctx, variant.StorageKey); err != nil {
+				return err
+			}
+		}
+		if err := s.blobService.Release(ctx, file.StorageKey); err != nil {
+			return err
+		}
+		if err := s.quotaService.Record(ctx, file.AccountId, -file.Size, -1); err != nil {
+			return err
+		}
+		return s.fileRepo.Delete(ctx, fileId)
+	})
+}
+
+// CheckServable fails with FILE_NOT_CLEAN when files are stored under storageKey but none
+// of them was scanned clean, so content waiting for its scan, or quarantined, is never
+// served by a guessed key. Keys no file uses are servable.
+func (s *fileService) CheckServable(ctx context.Context, storageKey string) error {
+	statuses, err := s.fileRepo.ScanStatusesByKey(ctx, storageKey)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status == entity.ScanStatusClean {
+			return nil
+		}
+	}
+	if len(statuses) > 0 {
+		return http_error.FILE_NOT_CLEAN
+	}
+	return nil
+}
+
+// find loads a file, trashed or not, that the account owns; any file for an admin.
+func (s *fileService) find(ctx context.Context, accountId *uuid.UUID, fileId uuid.UUID) (entity.File, error) {
+	file, err := s.fileRepo.FindByIDWithTrashed(ctx, fileId)
+	if err != nil {
+		return entity.File{}, err
+	}
+	if accountId != nil && file.AccountId != *accountId {
+		return entity.File{}, http_error.NOT_FOUND_ERROR
+	}
+	return *file, nil
+}
+
+func (s *fileService) sign(ctx context.Context, file *entity.File) error {
+	if file.DeletedAt.Valid {
+		return nil
+	}
+	return signPrivateFile(ctx, s.storageService, s.storageConfig.GetSignedURLTTL(), file)
+}
+
+// normalizeTags trims and lowercases tags, dropping empty and repeated ones.
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -3,9 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
 	entity "abdanhafidz.com/go-boilerplate/models/entity"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 	"abdanhafidz.com/go-boilerplate/repositories"
@@ -14,56 +17,63 @@ import (
 )
 
 type ForgotPasswordService interface {
-	Request(ctx context.Context, email string, token uint, due time.Time) (entity.ForgotPassword, error)
-	Reset(ctx context.Context, token uint, newPassword string) error
+	Request(ctx context.Context, email string) (dto.OTPIssuedResponse, error)
+	Reset(ctx context.Context, email string, token string, newPassword string) error
 }
 
 type forgotPasswordService struct {
-	jwtService         JWTService
-	accountRepo        repositories.AccountRepository
-	forgotPasswordRepo repositories.ForgotPasswordRepository
+	jwtService  JWTService
+	accountRepo repositories.AccountRepository
+	otpService  OTPService
+	mailService MailService
+	otpConfig   config.OTPConfig
 }
 
-func NewForgotPasswordService(jwtService JWTService, accountRepo repositories.AccountRepository, forgotPasswordRepo repositories.ForgotPasswordRepository) ForgotPasswordService {
+func NewForgotPasswordService(jwtService JWTService, accountRepo repositories.AccountRepository, otpService OTPService, mailService MailService, otpConfig config.OTPConfig) ForgotPasswordService {
 	return &forgotPasswordService{
-		jwtService:         jwtService,
-		accountRepo:        accountRepo,
-		forgotPasswordRepo: forgotPasswordRepo}
+		jwtService:  jwtService,
+		accountRepo: accountRepo,
+		otpService:  otpService,
+		mailService: mailService,
+		otpConfig:   otpConfig}
 }
 
-func (s *forgotPasswordService) Request(ctx context.Context, email string, token uint, due time.Time) (entity.ForgotPassword, error) {
+// Request mails a reset code to the account of email. An unknown email gets the same
+// answer without a code being issued, so the endpoint does not tell which emails are
+// registered.
+func (s *forgotPasswordService) Request(ctx context.Context, email string) (dto.OTPIssuedResponse, error) {
 	acc, err := s.accountRepo.GetAccountByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.OTPIssuedResponse{Email: email, Purpose: entity.OTPPurposeForgotPassword, ExpiredAt: time.Now().Add(s.otpConfig.GetTTL())}, nil
+	}
+	if err != nil {
+		return dto.OTPIssuedResponse{}, err
+	}
+
+	code, otp, err := s.otpService.Issue(ctx, acc.Id, entity.OTPPurposeForgotPassword)
 	if err != nil {
-		return entity.ForgotPassword{}, err
+		return dto.OTPIssuedResponse{}, err
 	}
-	if due.IsZero() {
-		due = time.Now().Add(15 * time.Minute)
+
+	body := fmt.Sprintf("Your password reset code is %s.\nIt expires at %s. Ignore this email if you did not request a reset.", code, otp.ExpiredAt.Format("15:04 MST"))
+	if err := s.mailService.Send(ctx, acc.Email, "Password Reset Code", body); err != nil {
+		return dto.OTPIssuedResponse{}, err
 	}
-	rec := entity.ForgotPassword{AccountId: acc.Id, Token: token, IsExpired: false, CreatedAt: time.Now(), ExpiredAt: due}
-	return s.forgotPasswordRepo.Create(ctx, rec)
+
+	return dto.OTPIssuedResponse{Email: acc.Email, Purpose: otp.Purpose, ExpiredAt: otp.ExpiredAt}, nil
 }
 
-func (s *forgotPasswordService) Reset(ctx context.Context, token uint, newPassword string) error {
+func (s *forgotPasswordService) Reset(ctx context.Context, email string, token string, newPassword string) error {
 	if strings.TrimSpace(newPassword) == "" {
 		return http_error.BAD_REQUEST_ERROR
 	}
 
-	rec, err := s.forgotPasswordRepo.GetByToken(ctx, token)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return http_error.INVALID_OTP
-	}
-
+	acc, err := s.accountRepo.GetAccountByEmail(ctx, email)
 	if err != nil {
-		return err
-	}
-
-	if rec.ExpiredAt.Before(time.Now()) {
-		_ = s.forgotPasswordRepo.MarkExpired(ctx, rec.Id)
-		return http_error.EXPIRED_TOKEN
+		return http_error.INVALID_OTP
 	}
 
-	acc, err := s.accountRepo.GetAccountById(ctx, rec.AccountId)
-	if err != nil {
+	if err := s.otpService.Verify(ctx, acc.Id, entity.OTPPurposeForgotPassword, token); err != nil {
 		return err
 	}
 
@@ -75,9 +85,6 @@ func (s *forgotPasswordService) Reset(ctx context.Context, token uint, newPasswo
 
 	acc.Password = string(bytes)
 
-	if _, err := s.accountRepo.UpdateAccount(ctx, acc); err != nil {
-		return err
-	}
-
-	return s.forgotPasswordRepo.MarkExpired(ctx, rec.Id)
+	_, err = s.accountRepo.UpdateAccount(ctx, acc)
+	return err
 }
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+)
+
+const ledgerCheckLimit = 100
+
+// Ledger accounts money moves between. Discounts and refunds are contra revenue, so
+// net revenue is sales revenue less both.
+var (
+	ledgerGatewayClearing  = entity.LedgerAccount{Code: "gateway_clearing", Name: "Gateway clearing", Type: entity.LedgerAccountTypeAsset}
+	ledgerSalesRevenue     = entity.LedgerAccount{Code: "sales_revenue", Name: "Sales revenue", Type: entity.LedgerAccountTypeRevenue}
+	ledgerVoucherDiscounts = entity.LedgerAccount{Code: "voucher_discounts", Name: "Voucher discounts", Type: entity.LedgerAccountTypeContraRevenue}
+	ledgerSalesRefunds     = entity.LedgerAccount{Code: "sales_refunds", Name: "Sales refunds", Type: entity.LedgerAccountTypeContraRevenue}
+	ledgerGatewayFees      = entity.LedgerAccount{Code: "gateway_fees", Name: "Gateway fees", Type: entity.LedgerAccountTypeExpense}
+)
+
+// LedgerService books every money movement as a balanced journal entry. It is registered
+// as a PaymentListener, so entries are written in the transaction of the payment change
+// they record.
+type LedgerService interface {
+	PaymentListener
+	Balances(ctx context.Context, period dto.Period) ([]dto.LedgerBalance, error)
+	ListEntries(ctx context.Context, pagination entity.Pagination, period dto.Period, kind string) ([]entity.JournalEntry, int64, error)
+	Check(ctx context.Context) (dto.LedgerCheckReport, error)
+	ExportCSV(ctx context.Context, period dto.Period, w io.Writer) error
+}
+
+type ledgerService struct {
+	ledgerConfig config.LedgerConfig
+	ledgerRepo   repositories.LedgerRepository
+}
+
+func NewLedgerService(ledgerConfig config.LedgerConfig, ledgerRepo repositories.LedgerRepository) LedgerService {
+	return &ledgerService{
+		ledgerConfig: ledgerConfig,
+		ledgerRepo:   ledgerRepo,
+	}
+}
+
+// ledgerLine is one side of an entry before its account is resolved.
+type ledgerLine struct {
+	account   entity.LedgerAccount
+	direction string
+	amount    float64
+}
+
+// OnPaymentPaid books the sale: the collected amount and any voucher discount on the
+// debit side, the list price as revenue. The estimated gateway fee follows as its own
+// entry.
+func (s *ledgerService) OnPaymentPaid(ctx context.Context, payment entity.Payment) error {
+	postedAt := time.Now()
+	if payment.PaidAt != nil {
+		postedAt = *payment.PaidAt
+	}
+	amount := roundCents(payment.Amount)
+	discount := roundCents(payment.DiscountAmount)
+
+	err := s.post(ctx, entity.JournalEntry{
+		Reference:   "payment:" + payment.Id.String(),
+		Kind:        entity.JournalKindPayment,
+		Description: payment.Description,
+		PaymentId:   &payment.Id,
+		AccountId:   &payment.AccountId,
+		Currency:    payment.Currency,
+		PostedAt:    postedAt,
+	}, []ledgerLine{
+		{ledgerGatewayClearing, entity.LedgerDebit, amount},
+		{ledgerVoucherDiscounts, entity.LedgerDebit, discount},
+		{ledgerSalesRevenue, entity.LedgerCredit, amount + discount},
+	})
+	if err != nil {
+		return err
+	}
+
+	fee := s.gatewayFee(amount)
+	if fee <= 0 {
+		return nil
+	}
+	return s.post(ctx, entity.JournalEntry{
+		Reference:   "fee:" + payment.Id.String(),
+		Kind:        entity.JournalKindFee,
+		Description: "Gateway fee for " + payment.ExternalId,
+		PaymentId:   &payment.Id,
+		AccountId:   &payment.AccountId,
+		Currency:    payment.Currency,
+		PostedAt:    postedAt,
+	}, []ledgerLine{
+		{ledgerGatewayFees, entity.LedgerDebit, fee},
+		{ledgerGatewayClearing, entity.LedgerCredit, fee},
+	})
+}
+
+// OnPaymentRefunded books the money paid back out of the gateway balance.
+func (s *ledgerService) OnPaymentRefunded(ctx context.Context, payment entity.Payment, refund entity.Refund) error {
+	postedAt := time.Now()
+	if refund.CompletedAt != nil {
+		postedAt = *refund.CompletedAt
+	}
+	amount := roundCents(refund.Amount)
+	return s.post(ctx, entity.JournalEntry{
+		Reference:   "refund:" + refund.Id.String(),
+		Kind:        entity.JournalKindRefund,
+		Description: refund.Reason,
+		PaymentId:   &payment.Id,
+		RefundId:    &refund.Id,
+		AccountId:   &payment.AccountId,
+		Currency:    payment.Currency,
+		PostedAt:    postedAt,
+	}, []ledgerLine{
+		{ledgerSalesRefunds, entity.LedgerDebit, amount},
+		{ledgerGatewayClearing, entity.LedgerCredit, amount},
+	})
+}
+
+func (s *ledgerService) Balances(ctx context.Context, period dto.Period) ([]dto.LedgerBalance, error) {
+	return s.ledgerRepo.Balances(ctx, period)
+}
+
+func (s *ledgerService) ListEntries(ctx context.Context, pagination entity.Pagination, period dto.Period, kind string) ([]entity.JournalEntry, int64, error) {
+	return s.ledgerRepo.ListEntries(ctx, pagination, period, kind)
+}
+
+// Check verifies that every entry balances and that debits equal credits per currency.
+func (s *ledgerService) Check(ctx context.Context) (dto.LedgerCheckReport, error) {
+	report := dto.LedgerCheckReport{CheckedAt: time.Now(), Balanced: true}
+	totals, err := s.ledgerRepo.CurrencyTotals(ctx)
+	if err != nil {
+		return dto.LedgerCheckReport{}, err
+	}
+	unbalanced, err := s.ledgerRepo.UnbalancedEntries(ctx, ledgerCheckLimit)
+	if err != nil {
+		return dto.LedgerCheckReport{}, err
+	}
+	report.Totals = totals
+	report.UnbalancedEntries = unbalanced
+	for _, total := range totals {
+		if total.Difference != 0 {
+			report.Balanced = false
+		}
+	}
+	if len(unbalanced) > 0 {
+		report.Balanced = false
+	}
+	return report, nil
+}
+
+// ExportCSV writes the postings of the period, one row per posting, as they are read.
+// References and descriptions carry user input and are escaped like the payment export.
+func (s *ledgerService) ExportCSV(ctx context.Context, period dto.Period, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"posted_at", "reference", "kind", "description", "payment_id", "refund_id", "account_code", "account_name", "debit", "credit", "currency"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	err := s.ledgerRepo.ExportPostings(ctx, period, func(row dto.LedgerExportRow) error {
+		debit, credit := "", ""
+		amount := strconv.FormatFloat(row.Amount, 'f', 2, 64)
+		if row.Direction == entity.LedgerDebit {
+			debit = amount
+		} else {
+			credit = amount
+		}
+		return writer.Write([]string{
+			row.PostedAt.Format(time.RFC3339),
+			spreadsheetText(row.Reference),
+			row.Kind,
+			spreadsheetText(row.Description),
+			optionalUUID(row.PaymentId),
+			optionalUUID(row.RefundId),
+			row.AccountCode,
+			row.AccountName,
+			debit,
+			credit,
+			row.Currency,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// post resolves the accounts of the lines and appends the entry, skipping zero lines. An
+// entry that does not balance is refused, which rolls back the payment change with it.
+func (s *ledgerService) post(ctx context.Context, entry entity.JournalEntry, lines []ledgerLine) error {
+	var debit, credit float64
+	for _, line := range lines {
+		if line.amount < 0 {
+			return fmt.Errorf("%w: negative ledger amount on %s", http_error.INTERNAL_SERVER_ERROR, entry.Reference)
+		}
+		if line.direction == entity.LedgerDebit {
+			debit += line.amount
+		} else {
+			credit += line.amount
+		}
+	}
+	if roundCents(debit) != roundCents(credit) {
+		return fmt.Errorf("%w: ledger entry %s does not balance", http_error.INTERNAL_SERVER_ERROR, entry.Reference)
+	}
+	if debit == 0 {
+		return nil
+	}
+
+	for _, line := range lines {
+		if line.amount == 0 {
+			continue
+		}
+		account, err := s.ledgerRepo.EnsureAccount(ctx, line.account)
+		if err != nil {
+			return err
+		}
+		entry.Postings = append(entry.Postings, entity.LedgerPosting{
+			LedgerAccountId: account.Id,
+			Direction:       line.direction,
+			Amount:          line.amount,
+			Currency:        entry.Currency,
+		})
+	}
+	_, _, err := s.ledgerRepo.CreateEntry(ctx, entry)
+	return err
+}
+
+// gatewayFee estimates the gateway fee of a collected amount; it never exceeds it.
+func (s *ledgerService) gatewayFee(amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	fee := roundCents(amount*s.ledgerConfig.GetFeePercent()/100 + s.ledgerConfig.GetFeeFixed())
+	return math.Min(fee, amount)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func optionalUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
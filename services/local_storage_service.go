@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+)
+
+// LocalStorageService is the local backend as the app serves it: files under
+// config.PrivateStoragePrefix are only served with a valid signature.
+type LocalStorageService interface {
+	StorageService
+	FilePath(key string) (string, error)
+	VerifySignature(key string, expires string, signature string) error
+}
+
+// localStorageService keeps files in a directory the app serves itself.
+type localStorageService struct {
+	root          string
+	baseURL       string
+	signingSecret []byte
+}
+
+// NewLocalStorageService creates the root directory when missing and checks it can be
+// written to.
+func NewLocalStorageService(root string, baseURL string, signingSecret string) (LocalStorageService, error) {
+	if signingSecret == "" {
+		return nil, errors.New("local storage needs STORAGE_SIGNING_SECRET or SALT to sign private links")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("local storage root %s: %w", root, err)
+	}
+	probe, err := os.CreateTemp(root, ".probe-*")
+	if err != nil {
+		return nil, fmt.Errorf("local storage root %s is not writable: %w", root, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return &localStorageService{root: root, baseURL: strings.TrimRight(baseURL, "/"), signingSecret: []byte(signingSecret)}, nil
+}
+
+// UploadFile writes to a temporary file first, so a reader never sees half a file.
+func (s *localStorageService) UploadFile(ctx context.Context, file io.Reader, destinationPath string, contentType string) (string, error) {
+	target, key, err := s.resolve(destinationPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	return s.link(key), nil
+}
+
+func (s *localStorageService) DownloadFile(ctx context.Context, path string) ([]byte, error) {
+	target, _, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", http_error.NOT_FOUND_ERROR, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: download %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	return data, nil
+}
+
+func (s *localStorageService) OpenFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	target, _, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", http_error.NOT_FOUND_ERROR, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: open %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w: open %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	return file, nil
+}
+
+// DeleteFile removes the file; a file that is already gone is not an error.
+func (s *localStorageService) DeleteFile(ctx context.Context, path string) error {
+	target, _, err := s.resolve(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: delete %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	return nil
+}
+
+// SignedURL links to the file with an HMAC of its key and expiry, checked by
+// VerifySignature when the link is followed.
+func (s *localStorageService) SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	_, key, err := s.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiresIn).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.signature(key, expires)}}
+	return s.link(key) + "?" + query.Encode(), nil
+}
+
+// FilePath returns where an existing file is on disk.
+func (s *localStorageService) FilePath(key string) (string, error) {
+	target, _, err := s.resolve(key)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("%w: %s", http_error.NOT_FOUND_ERROR, key)
+	}
+	return target, nil
+}
+
+// VerifySignature accepts a link made by SignedURL that has not expired yet.
+func (s *localStorageService) VerifySignature(key string, expires string, signature string) error {
+	_, key, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return http_error.INVALID_SIGNED_URL
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(key, expires))) {
+		return http_error.INVALID_SIGNED_URL
+	}
+	return nil
+}
+
+// IsPrivateKey tells whether a key belongs to a private file.
+func IsPrivateKey(key string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(path.Clean("/"+key), "/"), config.PrivateStoragePrefix)
+}
+
+func (s *localStorageService) signature(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.signingSecret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *localStorageService) link(key string) string {
+	return s.baseURL + (&url.URL{Path: "/" + key}).EscapedPath()
+}
+
+// resolve maps a key to a path under the root, refusing keys that would leave it.
+func (s *localStorageService) resolve(key string) (string, string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || strings.HasPrefix(cleaned, ".") {
+		return "", "", fmt.Errorf("%w: invalid storage key %q", http_error.BAD_REQUEST_ERROR, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), cleaned, nil
+}
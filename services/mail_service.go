@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	"github.com/google/uuid"
+)
+
+type MailService interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+	SendWithAttachment(ctx context.Context, to string, subject string, body string, attachment dto.MailAttachment) error
+}
+
+type mailService struct {
+	cfg config.MailConfig
+}
+
+func NewMailService(cfg config.MailConfig) MailService {
+	return &mailService{cfg: cfg}
+}
+
+func (s *mailService) Send(ctx context.Context, to string, subject string, body string) error {
+	if !s.cfg.IsConfigured() {
+		// Without SMTP settings (local development) the message is dropped. The body is not
+		// logged, as it may carry a one-time code.
+		log.Printf("[MAIL] SMTP is not configured, dropped message to %s: %s", to, subject)
+		return nil
+	}
+
+	var msg strings.Builder
+	s.writeHeaders(&msg, to, subject)
+	msg.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
+	msg.WriteString(body)
+	return s.deliver(to, msg.String())
+}
+
+// SendWithAttachment sends a plain text message with one file attached.
+func (s *mailService) SendWithAttachment(ctx context.Context, to string, subject string, body string, attachment dto.MailAttachment) error {
+	if !s.cfg.IsConfigured() {
+		log.Printf("[MAIL] SMTP is not configured, dropped message to %s with %s (%d bytes): %s", to, attachment.Name, len(attachment.Content), subject)
+		return nil
+	}
+
+	boundary := uuid.NewString()
+	var msg strings.Builder
+	s.writeHeaders(&msg, to, subject)
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", boundary)
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	msg.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
+	msg.WriteString(body)
+	fmt.Fprintf(&msg, "\r\n--%s\r\n", boundary)
+	fmt.Fprintf(&msg, "Content-Type: %s; name=\"%s\"\r\n", attachment.ContentType, attachment.Name)
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(&msg, "Content-Disposition: attachment; filename=\"%s\"\r\n\r\n", attachment.Name)
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+	return s.deliver(to, msg.String())
+}
+
+func (s *mailService) writeHeaders(msg *strings.Builder, to string, subject string) {
+	fmt.Fprintf(msg, "From: %s\r\n", s.cfg.GetSender())
+	fmt.Fprintf(msg, "To: %s\r\n", to)
+	fmt.Fprintf(msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+}
+
+func (s *mailService) deliver(to string, msg string) error {
+	var auth smtp.Auth
+	if s.cfg.GetUsername() != "" {
+		auth = smtp.PlainAuth("", s.cfg.GetUsername(), s.cfg.GetPassword(), s.cfg.GetHost())
+	}
+
+	addr := net.JoinHostPort(s.cfg.GetHost(), s.cfg.GetPort())
+	if err := smtp.SendMail(addr, auth, s.cfg.GetSender(), []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
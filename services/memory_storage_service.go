@@ -0,0 +1,64 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+)
+
+// memoryStorageService keeps files in memory. It is meant for development and tests:
+// everything is lost on restart.
+type memoryStorageService struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+func NewMemoryStorageService() StorageService {
+	return &memoryStorageService{files: map[string][]byte{}}
+}
+
+func (s *memoryStorageService) UploadFile(ctx context.Context, file io.Reader, destinationPath string, contentType string) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	s.mu.Lock()
+	s.files[destinationPath] = data
+	s.mu.Unlock()
+	return "memory://" + destinationPath, nil
+}
+
+func (s *memoryStorageService) DownloadFile(ctx context.Context, path string) ([]byte, error) {
+	s.mu.RLock()
+	data, ok := s.files[path]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", http_error.NOT_FOUND_ERROR, path)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *memoryStorageService) OpenFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	data, err := s.DownloadFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data[min(offset, int64(len(data))):])), nil
+}
+
+// SignedURL only marks the expiry; memory URLs are not reachable over HTTP anyway.
+func (s *memoryStorageService) SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	return fmt.Sprintf("memory://%s?expires=%d", path, time.Now().Add(expiresIn).Unix()), nil
+}
+
+func (s *memoryStorageService) DeleteFile(ctx context.Context, path string) error {
+	s.mu.Lock()
+	delete(s.files, path)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OTPService interface {
+	Issue(ctx context.Context, accountId uuid.UUID, purpose string) (string, entity.OneTimePassword, error)
+	Verify(ctx context.Context, accountId uuid.UUID, purpose string, code string) error
+	Revoke(ctx context.Context, accountId uuid.UUID, purpose string) error
+}
+
+type otpService struct {
+	otpRepo repositories.OTPRepository
+	cfg     config.OTPConfig
+}
+
+func NewOTPService(otpRepo repositories.OTPRepository, cfg config.OTPConfig) OTPService {
+	return &otpService{otpRepo: otpRepo, cfg: cfg}
+}
+
+// Issue generates a fresh code for the account and purpose, invalidating any code issued
+// before it. Only the HMAC of the code is stored; the plain code is returned once so the
+// caller can deliver it.
+func (s *otpService) Issue(ctx context.Context, accountId uuid.UUID, purpose string) (string, entity.OneTimePassword, error) {
+	code, err := s.generateCode()
+	if err != nil {
+		return "", entity.OneTimePassword{}, err
+	}
+
+	if err := s.otpRepo.ExpireActiveByAccountAndPurpose(ctx, accountId, purpose); err != nil {
+		return "", entity.OneTimePassword{}, err
+	}
+
+	now := time.Now()
+	otp, err := s.otpRepo.Create(ctx, entity.OneTimePassword{
+		AccountId: accountId,
+		Purpose:   purpose,
+		TokenHash: s.hash(accountId, purpose, code),
+		CreatedAt: now,
+		ExpiredAt: now.Add(s.cfg.GetTTL()),
+	})
+	if err != nil {
+		return "", entity.OneTimePassword{}, err
+	}
+	return code, otp, nil
+}
+
+// Verify checks the code against the active code for the account and purpose and consumes
+// it on success. Every wrong guess counts towards the attempt cap; once the cap is reached
+// the code is burned and a new one has to be requested. The cap is enforced by the
+// updates themselves, as otp may be stale by the time a parallel guess is counted.
+func (s *otpService) Verify(ctx context.Context, accountId uuid.UUID, purpose string, code string) error {
+	otp, err := s.otpRepo.GetActiveByAccountAndPurpose(ctx, accountId, purpose)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http_error.INVALID_OTP
+	}
+	if err != nil {
+		return err
+	}
+
+	if otp.ExpiredAt.Before(time.Now()) {
+		_ = s.otpRepo.MarkExpired(ctx, otp.Id)
+		return http_error.EXPIRED_TOKEN
+	}
+
+	if otp.Attempts >= s.cfg.GetMaxAttempts() {
+		_ = s.otpRepo.MarkExpired(ctx, otp.Id)
+		return http_error.OTP_ATTEMPTS_EXCEEDED
+	}
+
+	expected, err := hex.DecodeString(otp.TokenHash)
+	if err != nil {
+		return http_error.INTERNAL_SERVER_ERROR
+	}
+	given, _ := hex.DecodeString(s.hash(accountId, purpose, code))
+
+	if !hmac.Equal(expected, given) {
+		counted, err := s.otpRepo.IncrementAttempts(ctx, otp.Id, s.cfg.GetMaxAttempts())
+		if err != nil {
+			return err
+		}
+		if !counted || otp.Attempts+1 >= s.cfg.GetMaxAttempts() {
+			_ = s.otpRepo.MarkExpired(ctx, otp.Id)
+			return http_error.OTP_ATTEMPTS_EXCEEDED
+		}
+		return http_error.INVALID_OTP
+	}
+
+	consumed, err := s.otpRepo.Consume(ctx, otp.Id, s.cfg.GetMaxAttempts())
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return http_error.INVALID_OTP
+	}
+	return nil
+}
+
+func (s *otpService) Revoke(ctx context.Context, accountId uuid.UUID, purpose string) error {
+	return s.otpRepo.ExpireActiveByAccountAndPurpose(ctx, accountId, purpose)
+}
+
+func (s *otpService) generateCode() (string, error) {
+	digits := s.cfg.GetDigits()
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("generate otp: %w", err)
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// hash binds the code to its account and purpose so a stored hash is useless for any
+// other account or flow.
+func (s *otpService) hash(accountId uuid.UUID, purpose string, code string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.GetSecretKey()))
+	mac.Write([]byte(accountId.String()))
+	mac.Write([]byte{0})
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
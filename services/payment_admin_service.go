@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"abdanhafidz.com/go-boilerplate/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	PaymentExportCSV  = "csv"
+	PaymentExportXLSX = "xlsx"
+
+	// paymentExportFlushEvery is how many rows are written between flushes, so the client
+	// receives the export while it is produced.
+	paymentExportFlushEvery = 500
+)
+
+var paymentExportHeader = []string{"id", "external_id", "invoice_id", "account_id", "account_email", "product_code", "description", "currency", "amount", "discount_amount", "refunded_amount", "status", "created_at", "paid_at"}
+
+// PaymentAdminService is the read side of the payments console: filtered lists, a
+// detailed view with the provider's callbacks, and streamed exports.
+type PaymentAdminService interface {
+	List(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination) ([]entity.Payment, int64, error)
+	Get(ctx context.Context, paymentId uuid.UUID) (dto.AdminPaymentDetail, error)
+	Export(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination, format string, w io.Writer) error
+}
+
+type paymentAdminService struct {
+	paymentRepo  repositories.PaymentRepository
+	callbackRepo repositories.PaymentCallbackRepository
+}
+
+func NewPaymentAdminService(paymentRepo repositories.PaymentRepository, callbackRepo repositories.PaymentCallbackRepository) PaymentAdminService {
+	return &paymentAdminService{
+		paymentRepo:  paymentRepo,
+		callbackRepo: callbackRepo,
+	}
+}
+
+func (s *paymentAdminService) List(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination) ([]entity.Payment, int64, error) {
+	return s.paymentRepo.AdminList(ctx, filter, pagination)
+}
+
+// Get returns a payment with the callbacks about its invoice, its external id and its
+// refunds, in the order they arrived.
+func (s *paymentAdminService) Get(ctx context.Context, paymentId uuid.UUID) (dto.AdminPaymentDetail, error) {
+	payment, err := s.paymentRepo.GetDetailById(ctx, paymentId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.AdminPaymentDetail{}, http_error.NOT_FOUND_ERROR
+	}
+	if err != nil {
+		return dto.AdminPaymentDetail{}, err
+	}
+
+	references := []string{payment.ExternalId}
+	if payment.InvoiceId != "" {
+		references = append(references, payment.InvoiceId)
+	}
+	for _, refund := range payment.Refunds {
+		references = append(references, refund.Id.String())
+	}
+	callbacks, err := s.callbackRepo.ListByReferenceIds(ctx, references)
+	if err != nil {
+		return dto.AdminPaymentDetail{}, err
+	}
+	return dto.AdminPaymentDetail{Payment: payment, Callbacks: callbacks}, nil
+}
+
+// Export writes every payment matching the filter as CSV or XLSX while reading them.
+func (s *paymentAdminService) Export(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination, format string, w io.Writer) error {
+	switch format {
+	case PaymentExportCSV:
+		return s.exportCSV(ctx, filter, pagination, w)
+	case PaymentExportXLSX:
+		return s.exportXLSX(ctx, filter, pagination, w)
+	default:
+		return http_error.BAD_REQUEST_ERROR
+	}
+}
+
+func (s *paymentAdminService) exportCSV(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(paymentExportHeader); err != nil {
+		return err
+	}
+	written := 0
+	err := s.paymentRepo.AdminExport(ctx, filter, pagination, func(row dto.PaymentExportRow) error {
+		paidAt := ""
+		if row.PaidAt != nil {
+			paidAt = row.PaidAt.Format(time.RFC3339)
+		}
+		err := writer.Write([]string{
+			row.Id.String(),
+			spreadsheetText(row.ExternalId),
+			spreadsheetText(row.InvoiceId),
+			row.AccountId.String(),
+			spreadsheetText(row.AccountEmail),
+			spreadsheetText(row.ProductCode),
+			spreadsheetText(row.Description),
+			row.Currency,
+			strconv.FormatFloat(row.Amount, 'f', 2, 64),
+			strconv.FormatFloat(row.DiscountAmount, 'f', 2, 64),
+			strconv.FormatFloat(row.RefundedAmount, 'f', 2, 64),
+			row.Status,
+			row.CreatedAt.Format(time.RFC3339),
+			paidAt,
+		})
+		if err != nil {
+			return err
+		}
+		if written++; written%paymentExportFlushEvery == 0 {
+			writer.Flush()
+			flushResponse(w)
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// spreadsheetText escapes free text for an export, prefixing a quote to a value a
+// spreadsheet would otherwise read as a formula.
+func spreadsheetText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (s *paymentAdminService) exportXLSX(ctx context.Context, filter dto.AdminPaymentFilter, pagination entity.Pagination, w io.Writer) error {
+	writer, err := utils.NewXLSXWriter(w, "Payments")
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(paymentExportHeader))
+	for i, name := range paymentExportHeader {
+		header[i] = name
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return err
+	}
+	written := 0
+	err = s.paymentRepo.AdminExport(ctx, filter, pagination, func(row dto.PaymentExportRow) error {
+		var paidAt interface{}
+		if row.PaidAt != nil {
+			paidAt = *row.PaidAt
+		}
+		err := writer.WriteRow([]interface{}{
+			row.Id.String(),
+			spreadsheetText(row.ExternalId),
+			spreadsheetText(row.InvoiceId),
+			row.AccountId.String(),
+			spreadsheetText(row.AccountEmail),
+			spreadsheetText(row.ProductCode),
+			spreadsheetText(row.Description),
+			row.Currency,
+			row.Amount,
+			row.DiscountAmount,
+			row.RefundedAmount,
+			row.Status,
+			row.CreatedAt,
+			paidAt,
+		})
+		if err != nil {
+			return err
+		}
+		if written++; written%paymentExportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			flushResponse(w)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func flushResponse(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import "testing"
+
+func TestSpreadsheetText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Premium plan", "Premium plan"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+	for _, tt := range tests {
+		if got := spreadsheetText(tt.value); got != tt.want {
+			t.Errorf("spreadsheetText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentCallbackService interface {
+	VerifyToken(token string) error
+	Handle(ctx context.Context, webhookId string, payload []byte) (entity.PaymentCallback, error)
+	Replay(ctx context.Context, callbackId uuid.UUID) (entity.PaymentCallback, error)
+}
+
+type paymentCallbackService struct {
+	paymentGatewayConfig config.PaymentGatewayConfig
+	paymentCallbackRepo  repositories.PaymentCallbackRepository
+	paymentService       PaymentService
+	refundService        RefundService
+}
+
+func NewPaymentCallbackService(paymentGatewayConfig config.PaymentGatewayConfig, paymentCallbackRepo repositories.PaymentCallbackRepository, paymentService PaymentService, refundService RefundService) PaymentCallbackService {
+	return &paymentCallbackService{
+		paymentGatewayConfig: paymentGatewayConfig,
+		paymentCallbackRepo:  paymentCallbackRepo,
+		paymentService:       paymentService,
+		refundService:        refundService,
+	}
+}
+
+// parsedCallback is a stored callback body resolved to its provider type.
+type parsedCallback struct {
+	eventType      string
+	eventId        string
+	referenceId    string
+	providerStatus string
+	invoice        *dto.XenditInvoiceCallback
+	virtualAccount *dto.XenditVirtualAccountCallback
+	eWallet        *dto.XenditEWalletCallback
+	refund         *dto.XenditRefundCallback
+}
+
+// VerifyToken compares the x-callback-token header with the configured token in constant
+// time. An unset token rejects everything rather than accepting everything.
+func (s *paymentCallbackService) VerifyToken(token string) error {
+	expected := s.paymentGatewayConfig.GetCallbackToken()
+	if expected == "" || token == "" {
+		return http_error.UNAUTHORIZED
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(token)) != 1 {
+		return http_error.UNAUTHORIZED
+	}
+	return nil
+}
+
+// Handle persists the raw callback and processes it once. A delivery whose event was
+// already processed is acknowledged without touching the payment again; a delivery of a
+// previously failed event is processed again.
+func (s *paymentCallbackService) Handle(ctx context.Context, webhookId string, payload []byte) (entity.PaymentCallback, error) {
+	parsed, err := parseCallback(webhookId, payload)
+	if err != nil {
+		return entity.PaymentCallback{}, err
+	}
+
+	callback, err := s.paymentCallbackRepo.Create(ctx, entity.PaymentCallback{
+		EventId:          parsed.eventId,
+		EventType:        parsed.eventType,
+		ReferenceId:      parsed.referenceId,
+		ProviderStatus:   parsed.providerStatus,
+		Payload:          string(payload),
+		ProcessingStatus: entity.CallbackStatusReceived,
+		ReceivedAt:       time.Now(),
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		callback, err = s.paymentCallbackRepo.GetByEventId(ctx, parsed.eventId)
+		if err != nil {
+			return entity.PaymentCallback{}, err
+		}
+		if callback.ProcessingStatus == entity.CallbackStatusProcessed || callback.ProcessingStatus == entity.CallbackStatusIgnored {
+			log.Printf("[PAYMENT][CALLBACK] duplicate delivery of %s ignored", callback.EventId)
+			return callback, nil
+		}
+	} else if err != nil {
+		return entity.PaymentCallback{}, err
+	}
+
+	return s.process(ctx, callback, parsed)
+}
+
+// Replay processes a stored callback again regardless of its previous outcome. Payment
+// transitions are idempotent, so replaying a processed callback is safe.
+func (s *paymentCallbackService) Replay(ctx context.Context, callbackId uuid.UUID) (entity.PaymentCallback, error) {
+	callback, err := s.paymentCallbackRepo.GetById(ctx, callbackId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.PaymentCallback{}, http_error.NOT_FOUND_ERROR
+	} else if err != nil {
+		return entity.PaymentCallback{}, err
+	}
+
+	parsed, err := parseCallback("", []byte(callback.Payload))
+	if err != nil {
+		return entity.PaymentCallback{}, err
+	}
+	return s.process(ctx, callback, parsed)
+}
+
+func (s *paymentCallbackService) process(ctx context.Context, callback entity.PaymentCallback, parsed parsedCallback) (entity.PaymentCallback, error) {
+	handled, procErr := s.apply(ctx, parsed)
+
+	now := time.Now()
+	callback.Attempts++
+	callback.ProcessedAt = &now
+	switch {
+	case procErr != nil:
+		callback.ProcessingStatus = entity.CallbackStatusFailed
+		callback.Error = procErr.Error()
+	case !handled:
+		callback.ProcessingStatus = entity.CallbackStatusIgnored
+		callback.Error = ""
+	default:
+		callback.ProcessingStatus = entity.CallbackStatusProcessed
+		callback.Error = ""
+	}
+
+	callback, err := s.paymentCallbackRepo.Update(ctx, callback)
+	if err != nil {
+		return entity.PaymentCallback{}, err
+	}
+	if procErr != nil {
+		// Surfacing the failure makes Xendit retry the delivery.
+		return callback, fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, procErr)
+	}
+	return callback, nil
+}
+
+// apply performs the payment transition a callback asks for. It reports false for events
+// that carry no transition we act on.
+func (s *paymentCallbackService) apply(ctx context.Context, parsed parsedCallback) (bool, error) {
+	switch parsed.eventType {
+	case entity.PaymentCallbackTypeInvoice:
+		switch parsed.providerStatus {
+		case "PAID", "SETTLED":
+			return true, s.paymentService.ConfirmPayment(ctx, parsed.invoice.Id, parsed.invoice.ExternalId)
+		case "EXPIRED":
+			return true, s.paymentService.ExpirePayment(ctx, parsed.invoice.Id, parsed.invoice.ExternalId)
+		}
+	case entity.PaymentCallbackTypeVirtualAccount:
+		return true, s.paymentService.ConfirmPaymentByExternalId(ctx, parsed.virtualAccount.ExternalId)
+	case entity.PaymentCallbackTypeEWallet:
+		switch parsed.providerStatus {
+		case "SUCCEEDED":
+			return true, s.paymentService.ConfirmPaymentByExternalId(ctx, parsed.eWallet.Data.ReferenceId)
+		case "FAILED", "VOIDED":
+			return true, s.paymentService.CancelPaymentByExternalId(ctx, parsed.eWallet.Data.ReferenceId)
+		}
+	case entity.PaymentCallbackTypeRefund:
+		data := parsed.refund.Data
+		return true, s.refundService.ApplyProviderStatus(ctx, data.ReferenceId, data.Id, data.Status, data.FailureCode)
+	}
+	return false, nil
+}
+
+// parseCallback detects the callback type from its shape and decodes the typed payload.
+// The event id is the webhook-id header when Xendit sends one; otherwise it is derived
+// from the object id and status, which Xendit only reports once per transition.
+func parseCallback(webhookId string, payload []byte) (parsedCallback, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return parsedCallback{}, http_error.BAD_REQUEST_ERROR
+	}
+
+	var parsed parsedCallback
+	switch {
+	case hasKey(probe, "event") && strings.HasPrefix(rawString(probe["event"]), "ewallet."):
+		var cb dto.XenditEWalletCallback
+		if err := json.Unmarshal(payload, &cb); err != nil {
+			return parsedCallback{}, http_error.BAD_REQUEST_ERROR
+		}
+		parsed = parsedCallback{
+			eventType:      entity.PaymentCallbackTypeEWallet,
+			referenceId:    cb.Data.ReferenceId,
+			providerStatus: cb.Data.Status,
+			eventId:        fmt.Sprintf("%s:%s:%s", cb.Event, cb.Data.Id, cb.Data.Status),
+			eWallet:        &cb,
+		}
+	case hasKey(probe, "event") && strings.HasPrefix(rawString(probe["event"]), "refund."):
+		var cb dto.XenditRefundCallback
+		if err := json.Unmarshal(payload, &cb); err != nil {
+			return parsedCallback{}, http_error.BAD_REQUEST_ERROR
+		}
+		parsed = parsedCallback{
+			eventType:      entity.PaymentCallbackTypeRefund,
+			referenceId:    cb.Data.ReferenceId,
+			providerStatus: cb.Data.Status,
+			eventId:        fmt.Sprintf("%s:%s:%s", cb.Event, cb.Data.Id, cb.Data.Status),
+			refund:         &cb,
+		}
+	case hasKey(probe, "callback_virtual_account_id"):
+		var cb dto.XenditVirtualAccountCallback
+		if err := json.Unmarshal(payload, &cb); err != nil {
+			return parsedCallback{}, http_error.BAD_REQUEST_ERROR
+		}
+		parsed = parsedCallback{
+			eventType:      entity.PaymentCallbackTypeVirtualAccount,
+			referenceId:    cb.ExternalId,
+			providerStatus: "PAID",
+			eventId:        fmt.Sprintf("va:%s", cb.PaymentId),
+			virtualAccount: &cb,
+		}
+	case hasKey(probe, "id") && hasKey(probe, "status"):
+		var cb dto.XenditInvoiceCallback
+		if err := json.Unmarshal(payload, &cb); err != nil {
+			return parsedCallback{}, http_error.BAD_REQUEST_ERROR
+		}
+		parsed = parsedCallback{
+			eventType:      entity.PaymentCallbackTypeInvoice,
+			referenceId:    cb.Id,
+			providerStatus: cb.Status,
+			eventId:        fmt.Sprintf("invoice:%s:%s", cb.Id, cb.Status),
+			invoice:        &cb,
+		}
+	default:
+		parsed = parsedCallback{
+			eventType: entity.PaymentCallbackTypeUnknown,
+			eventId:   "unknown:" + uuid.NewString(),
+		}
+	}
+
+	if webhookId != "" {
+		parsed.eventId = webhookId
+	}
+	return parsed, nil
+}
+
+func hasKey(m map[string]json.RawMessage, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func rawString(raw json.RawMessage) string {
+	var s string
+	_ = json.Unmarshal(raw, &s)
+	return s
+}
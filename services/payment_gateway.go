@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+)
+
+// PaymentGateway is the provider PaymentService bills through. Callbacks from the
+// provider always arrive through PaymentCallbackService.
+type PaymentGateway interface {
+	Name() string
+	CreateInvoice(ctx context.Context, req dto.GatewayInvoiceRequest) (dto.GatewayInvoice, error)
+	GetInvoice(ctx context.Context, invoiceId string) (dto.GatewayInvoice, error)
+	CreateRefund(ctx context.Context, req dto.GatewayRefundRequest) (dto.GatewayRefund, error)
+}
+
+// NewPaymentGateway builds the gateway selected by PAYMENT_GATEWAY.
+func NewPaymentGateway(paymentGatewayConfig config.PaymentGatewayConfig, xenditConfig config.XenditConfig) PaymentGateway {
+	if paymentGatewayConfig.GetProvider() == config.PaymentGatewaySimulator {
+		return NewSimulatorPaymentGateway(paymentGatewayConfig)
+	}
+	return NewXenditPaymentGateway(xenditConfig)
+}
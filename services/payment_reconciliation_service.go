@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// reconcileBatchSize is how many pending payments are checked per page.
+const reconcileBatchSize = 100
+
+type PaymentReconciliationService interface {
+	Run(ctx context.Context) (entity.PaymentReconciliation, error)
+	List(ctx context.Context, pagination entity.Pagination) ([]entity.PaymentReconciliation, error)
+	Get(ctx context.Context, id uuid.UUID) (entity.PaymentReconciliation, error)
+}
+
+type paymentReconciliationService struct {
+	jobConfig          config.JobConfig
+	paymentGateway     PaymentGateway
+	paymentService     PaymentService
+	paymentRepo        repositories.PaymentRepository
+	reconciliationRepo repositories.PaymentReconciliationRepository
+	running            sync.Mutex
+}
+
+func NewPaymentReconciliationService(jobConfig config.JobConfig, paymentGateway PaymentGateway, paymentService PaymentService, paymentRepo repositories.PaymentRepository, reconciliationRepo repositories.PaymentReconciliationRepository) PaymentReconciliationService {
+	return &paymentReconciliationService{
+		jobConfig:          jobConfig,
+		paymentGateway:     paymentGateway,
+		paymentService:     paymentService,
+		paymentRepo:        paymentRepo,
+		reconciliationRepo: reconciliationRepo,
+	}
+}
+
+// Run checks every payment pending longer than PAYMENT_RECONCILE_AFTER against the
+// gateway and applies the transitions whose callbacks were missed. Transitions go through
+// PaymentService, so a callback racing the run is harmless. Anything that cannot be
+// applied safely, such as an amount mismatch, is only recorded for an admin.
+func (s *paymentReconciliationService) Run(ctx context.Context) (entity.PaymentReconciliation, error) {
+	if !s.running.TryLock() {
+		return entity.PaymentReconciliation{}, http_error.RECONCILIATION_RUNNING
+	}
+	defer s.running.Unlock()
+
+	run, err := s.reconciliationRepo.Create(ctx, entity.PaymentReconciliation{StartedAt: time.Now()})
+	if err != nil {
+		return entity.PaymentReconciliation{}, err
+	}
+
+	cutoff := run.StartedAt.Add(-s.jobConfig.GetReconcileAfter())
+	var cursor entity.Payment
+	for {
+		page, err := s.paymentRepo.ListPendingCreatedBefore(ctx, cutoff, cursor, reconcileBatchSize)
+		if err != nil {
+			run.Error = err.Error()
+			break
+		}
+		for _, payment := range page {
+			run.Checked++
+			discrepancy, found := s.check(ctx, payment)
+			if !found {
+				continue
+			}
+			if discrepancy.Resolved {
+				run.Applied++
+			}
+			discrepancy.ReconciliationId = run.Id
+			if _, err := s.reconciliationRepo.CreateDiscrepancy(ctx, discrepancy); err != nil {
+				log.Printf("[PAYMENT][RECONCILE] record discrepancy of %s: %v", payment.ExternalId, err)
+			}
+			run.DiscrepancyCount++
+		}
+		if len(page) < reconcileBatchSize {
+			break
+		}
+		cursor = page[len(page)-1]
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if run, err = s.reconciliationRepo.Update(ctx, run); err != nil {
+		return entity.PaymentReconciliation{}, err
+	}
+	log.Printf("[PAYMENT][RECONCILE] checked %d, applied %d, discrepancies %d", run.Checked, run.Applied, run.DiscrepancyCount)
+	return run, nil
+}
+
+// check compares one pending payment with its gateway invoice. It reports false when the
+// two agree.
+func (s *paymentReconciliationService) check(ctx context.Context, payment entity.Payment) (entity.PaymentDiscrepancy, bool) {
+	discrepancy := entity.PaymentDiscrepancy{
+		PaymentId:   payment.Id,
+		LocalStatus: payment.Status,
+		LocalAmount: payment.Amount,
+	}
+
+	if payment.InvoiceId == "" {
+		discrepancy.Kind = entity.DiscrepancyMissingInvoice
+		discrepancy.Detail = "payment has no gateway invoice"
+		return discrepancy, true
+	}
+
+	invoice, err := s.paymentGateway.GetInvoice(ctx, payment.InvoiceId)
+	if errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		discrepancy.Kind = entity.DiscrepancyInvoiceNotFound
+		discrepancy.Detail = fmt.Sprintf("invoice %s is unknown to %s", payment.InvoiceId, s.paymentGateway.Name())
+		return discrepancy, true
+	} else if err != nil {
+		discrepancy.Kind = entity.DiscrepancyGatewayError
+		discrepancy.Detail = err.Error()
+		return discrepancy, true
+	}
+	discrepancy.GatewayStatus = invoice.Status
+	discrepancy.GatewayAmount = invoice.Amount
+
+	if !sameAmount(invoice.Amount, payment.Amount) {
+		discrepancy.Kind = entity.DiscrepancyAmountMismatch
+		discrepancy.Detail = fmt.Sprintf("gateway bills %.2f, payment is %.2f", invoice.Amount, payment.Amount)
+		return discrepancy, true
+	}
+
+	switch invoice.Status {
+	case entity.PaymentStatusPaid:
+		err = s.paymentService.ConfirmPayment(ctx, payment.InvoiceId, payment.ExternalId)
+	case entity.PaymentStatusExpired:
+		err = s.paymentService.ExpirePayment(ctx, payment.InvoiceId, payment.ExternalId)
+	default:
+		return entity.PaymentDiscrepancy{}, false
+	}
+
+	discrepancy.Kind = entity.DiscrepancyStatusMismatch
+	if err != nil {
+		discrepancy.Detail = err.Error()
+		return discrepancy, true
+	}
+	discrepancy.Resolved = true
+	discrepancy.Detail = fmt.Sprintf("applied missed transition %s -> %s", payment.Status, invoice.Status)
+	return discrepancy, true
+}
+
+func (s *paymentReconciliationService) List(ctx context.Context, pagination entity.Pagination) ([]entity.PaymentReconciliation, error) {
+	return s.reconciliationRepo.List(ctx, pagination)
+}
+
+func (s *paymentReconciliationService) Get(ctx context.Context, id uuid.UUID) (entity.PaymentReconciliation, error) {
+	run, err := s.reconciliationRepo.GetById(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.PaymentReconciliation{}, http_error.NOT_FOUND_ERROR
+	}
+	return run, err
+}
+
+// sameAmount compares currency amounts to the cent.
+func sameAmount(a float64, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
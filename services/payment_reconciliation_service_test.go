@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+)
+
+func (r *fakePaymentRepo) ListPendingCreatedBefore(ctx context.Context, before time.Time, after entity.Payment, limit int) ([]entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []entity.Payment
+	for _, payment := range r.payments {
+		if payment.Status != entity.PaymentStatusPending || !payment.CreatedAt.Before(before) {
+			continue
+		}
+		if payment.CreatedAt.Before(after.CreatedAt) || (payment.CreatedAt.Equal(after.CreatedAt) && payment.Id.String() <= after.Id.String()) {
+			continue
+		}
+		list = append(list, payment)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].Id.String() < list[j].Id.String()
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+type fakeReconciliationRepo struct {
+	repositories.PaymentReconciliationRepository
+	runs          []entity.PaymentReconciliation
+	discrepancies []entity.PaymentDiscrepancy
+}
+
+func (r *fakeReconciliationRepo) Create(ctx context.Context, run entity.PaymentReconciliation) (entity.PaymentReconciliation, error) {
+	run.Id = uuid.New()
+	r.runs = append(r.runs, run)
+	return run, nil
+}
+
+func (r *fakeReconciliationRepo) Update(ctx context.Context, run entity.PaymentReconciliation) (entity.PaymentReconciliation, error) {
+	return run, nil
+}
+
+func (r *fakeReconciliationRepo) CreateDiscrepancy(ctx context.Context, discrepancy entity.PaymentDiscrepancy) (entity.PaymentDiscrepancy, error) {
+	r.discrepancies = append(r.discrepancies, discrepancy)
+	return discrepancy, nil
+}
+
+type fakeJobConfig struct {
+	config.JobConfig
+}
+
+func (fakeJobConfig) GetReconcileAfter() time.Duration { return time.Hour }
+
+func newTestReconciliation(gateway *fakePaymentGateway, repo *fakePaymentRepo, runs *fakeReconciliationRepo) PaymentReconciliationService {
+	paymentService := newTestPaymentService(gateway, repo)
+	return NewPaymentReconciliationService(fakeJobConfig{}, gateway, paymentService, repo, runs)
+}
+
+// addStalePayment stores a payment pending for two hours whose invoice the gateway
+// reports in gatewayStatus with gatewayAmount. An empty gatewayStatus leaves the invoice
+// unknown to the gateway.
+func addStalePayment(repo *fakePaymentRepo, gateway *fakePaymentGateway, invoiceId string, gatewayStatus string, gatewayAmount float64) entity.Payment {
+	payment, _ := repo.Create(context.Background(), entity.Payment{
+		ExternalId: "PAY-" + uuid.NewString(),
+		InvoiceId:  invoiceId,
+		Amount:     1000,
+		Currency:   "IDR",
+		Status:     entity.PaymentStatusPending,
+		CreatedAt:  time.Now().Add(-2 * time.Hour),
+	})
+	if invoiceId != "" && gatewayStatus != "" {
+		gateway.setInvoice(dto.GatewayInvoice{Id: invoiceId, ExternalId: payment.ExternalId, Amount: gatewayAmount, Status: gatewayStatus})
+	}
+	return payment
+}
+
+func TestReconciliationRun(t *testing.T) {
+	repo := newFakePaymentRepo()
+	gateway := newFakePaymentGateway()
+	runs := &fakeReconciliationRepo{}
+
+	paid := addStalePayment(repo, gateway, "inv-paid", entity.PaymentStatusPaid, 1000)
+	expired := addStalePayment(repo, gateway, "inv-expired", entity.PaymentStatusExpired, 1000)
+	pending := addStalePayment(repo, gateway, "inv-pending", entity.PaymentStatusPending, 1000)
+	mismatch := addStalePayment(repo, gateway, "inv-mismatch", entity.PaymentStatusPaid, 900)
+	noInvoice := addStalePayment(repo, gateway, "", "", 0)
+	unknown := addStalePayment(repo, gateway, "inv-unknown", "", 0)
+	recent, _ := repo.Create(context.Background(), entity.Payment{ExternalId: "PAY-recent", InvoiceId: "inv-recent", Amount: 1000, Status: entity.PaymentStatusPending})
+	gateway.setInvoice(dto.GatewayInvoice{Id: "inv-recent", Amount: 1000, Status: entity.PaymentStatusPaid})
+
+	run, err := newTestReconciliation(gateway, repo, runs).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Checked != 6 || run.Applied != 2 || run.DiscrepancyCount != 5 || run.FinishedAt == nil {
+		t.Errorf("run = checked %d, applied %d, discrepancies %d, finished %v; want 6, 2, 5 and finished",
+			run.Checked, run.Applied, run.DiscrepancyCount, run.FinishedAt)
+	}
+
+	wantStatus := map[uuid.UUID]string{
+		paid.Id:      entity.PaymentStatusPaid,
+		expired.Id:   entity.PaymentStatusExpired,
+		pending.Id:   entity.PaymentStatusPending,
+		mismatch.Id:  entity.PaymentStatusPending,
+		noInvoice.Id: entity.PaymentStatusPending,
+		unknown.Id:   entity.PaymentStatusPending,
+		recent.Id:    entity.PaymentStatusPending,
+	}
+	for id, want := range wantStatus {
+		if got, _ := repo.GetById(context.Background(), id); got.Status != want {
+			t.Errorf("payment %s status = %s, want %s", got.InvoiceId, got.Status, want)
+		}
+	}
+
+	wantKinds := map[uuid.UUID]struct {
+		kind     string
+		resolved bool
+	}{
+		paid.Id:      {entity.DiscrepancyStatusMismatch, true},
+		expired.Id:   {entity.DiscrepancyStatusMismatch, true},
+		mismatch.Id:  {entity.DiscrepancyAmountMismatch, false},
+		noInvoice.Id: {entity.DiscrepancyMissingInvoice, false},
+		unknown.Id:   {entity.DiscrepancyInvoiceNotFound, false},
+	}
+	if len(runs.discrepancies) != len(wantKinds) {
+		t.Fatalf("recorded %d discrepancies, want %d", len(runs.discrepancies), len(wantKinds))
+	}
+	for _, discrepancy := range runs.discrepancies {
+		want, ok := wantKinds[discrepancy.PaymentId]
+		if !ok {
+			t.Errorf("unexpected discrepancy %+v", discrepancy)
+			continue
+		}
+		if discrepancy.Kind != want.kind || discrepancy.Resolved != want.resolved || discrepancy.ReconciliationId != run.Id {
+			t.Errorf("discrepancy = %s resolved %v, want %s resolved %v", discrepancy.Kind, discrepancy.Resolved, want.kind, want.resolved)
+		}
+	}
+}
+
+func TestReconciliationRunPagesThroughAllPayments(t *testing.T) {
+	repo := newFakePaymentRepo()
+	gateway := newFakePaymentGateway()
+	total := reconcileBatchSize*2 + 17
+	for i := 0; i < total; i++ {
+		addStalePayment(repo, gateway, fmt.Sprintf("inv-%d", i), entity.PaymentStatusPaid, 1000)
+	}
+
+	run, err := newTestReconciliation(gateway, repo, &fakeReconciliationRepo{}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Checked != total || run.Applied != total {
+		t.Errorf("checked %d, applied %d; want %d each", run.Checked, run.Applied, total)
+	}
+}
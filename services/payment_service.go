@@ -2,40 +2,319 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
 	"github.com/google/uuid"
-	"github.com/xendit/xendit-go/v7"
+	"gorm.io/gorm"
 )
 
 type PaymentService interface {
-	PaySomething(ctx context.Context, accountId uuid.UUID, amount float64)
-	ConfirmPayment(ctx context.Context, paymentId string) error
-	CancelPayment(ctx context.Context, paymentId string) error
-	ExpirePayment(ctx context.Context, paymentId string) error
+	CreatePayment(ctx context.Context, accountId uuid.UUID, req dto.CreatePaymentRequest) (entity.Payment, error)
+	Charge(ctx context.Context, accountId uuid.UUID, payment entity.Payment) (entity.Payment, error)
+	GetPayment(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID) (entity.Payment, error)
+	ConfirmPayment(ctx context.Context, invoiceId string, externalId string) error
+	CancelPayment(ctx context.Context, invoiceId string, externalId string) error
+	ExpirePayment(ctx context.Context, invoiceId string, externalId string) error
+	ConfirmPaymentByExternalId(ctx context.Context, externalId string) error
+	CancelPaymentByExternalId(ctx context.Context, externalId string) error
+	ListPayments(ctx context.Context, accountId uuid.UUID) ([]entity.Payment, error)
+	ApplyRefund(ctx context.Context, paymentId uuid.UUID, refund entity.Refund) (entity.Payment, error)
+	AddListener(listener PaymentListener)
+}
+
+// PaymentListener reacts to settled payment changes, such as granting access once a
+// payment is PAID. Listeners run inside the transaction of the change, so an error rolls
+// the change back and the provider retries the callback.
+type PaymentListener interface {
+	OnPaymentPaid(ctx context.Context, payment entity.Payment) error
+	OnPaymentRefunded(ctx context.Context, payment entity.Payment, refund entity.Refund) error
 }
 
 type paymentService struct {
-	xenditClient *xendit.APIClient
+	transactor           repositories.Transactor
+	listeners            []PaymentListener
+	paymentGateway       PaymentGateway
+	paymentGatewayConfig config.PaymentGatewayConfig
+	paymentRepo          repositories.PaymentRepository
+	accountRepo          repositories.AccountRepository
 }
 
-func NewPaymentService(xenditClient *xendit.APIClient) PaymentService {
+func NewPaymentService(transactor repositories.Transactor, paymentGateway PaymentGateway, paymentGatewayConfig config.PaymentGatewayConfig, paymentRepo repositories.PaymentRepository, accountRepo repositories.AccountRepository) PaymentService {
 	return &paymentService{
-		xenditClient: xenditClient,
+		transactor:           transactor,
+		paymentGateway:       paymentGateway,
+		paymentGatewayConfig: paymentGatewayConfig,
+		paymentRepo:          paymentRepo,
+		accountRepo:          accountRepo,
+	}
+}
+
+// paymentTransitions lists, per status, the statuses a payment may move to.
+// Statuses without an entry are final.
+var paymentTransitions = map[string][]string{
+	entity.PaymentStatusPending:           {entity.PaymentStatusPaid, entity.PaymentStatusFailed, entity.PaymentStatusExpired},
+	entity.PaymentStatusPaid:              {entity.PaymentStatusPartiallyRefunded, entity.PaymentStatusRefunded},
+	entity.PaymentStatusPartiallyRefunded: {entity.PaymentStatusRefunded},
+}
+
+func canTransitionPayment(from string, to string) bool {
+	for _, next := range paymentTransitions[from] {
+		if next == to {
+			return true
+		}
 	}
+	return false
 }
 
-func (s *paymentService) PaySomething(ctx context.Context, accountId uuid.UUID, amount float64) {
+// paymentPassed reports whether a payment in status current has already gone through
+// status to, e.g. a refunded payment has passed PAID.
+func paymentPassed(current string, to string) bool {
+	seen := map[string]bool{to: true}
+	queue := []string{to}
+	for len(queue) > 0 {
+		status := queue[0]
+		queue = queue[1:]
+		if status == current {
+			return true
+		}
+		for _, next := range paymentTransitions[status] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// AddListener registers a listener for paid and refunded payments. It is meant to be
+// called while wiring the application, before requests are served.
+func (s *paymentService) AddListener(listener PaymentListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *paymentService) CreatePayment(ctx context.Context, accountId uuid.UUID, req dto.CreatePaymentRequest) (entity.Payment, error) {
+	return s.Charge(ctx, accountId, entity.Payment{
+		Amount:      req.Amount,
+		Description: req.Description,
+	})
+}
+
+// Charge creates a payment prepared by the caller, such as a product checkout, and issues
+// its invoice. Amount and Description must be set; Id may be preset so the caller can refer
+// to the payment before it exists. A payment fully covered by DiscountAmount is PAID at once.
+func (s *paymentService) Charge(ctx context.Context, accountId uuid.UUID, payment entity.Payment) (entity.Payment, error) {
+	if payment.Amount < 0 || (payment.Amount == 0 && payment.DiscountAmount <= 0) {
+		return entity.Payment{}, http_error.BAD_REQUEST_ERROR
+	}
+
+	acc, err := s.accountRepo.GetAccountById(ctx, accountId)
+	if err != nil {
+		return entity.Payment{}, http_error.UNAUTHORIZED
+	}
+
+	payment.AccountId = acc.Id
+	payment.ExternalId = "PAY-" + uuid.NewString()
+	payment.Status = entity.PaymentStatusPending
+	if payment.Currency == "" {
+		payment.Currency = "IDR"
+	}
+
+	// The payment row exists before the invoice so a fast callback always finds it.
+	payment, err = s.paymentRepo.Create(ctx, payment)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	if payment.Amount == 0 {
+		// Nothing to collect, so there is no invoice to wait for.
+		if err := s.confirm(ctx, payment); err != nil {
+			return entity.Payment{}, err
+		}
+		return s.paymentRepo.GetById(ctx, payment.Id)
+	}
 
+	inv, err := s.paymentGateway.CreateInvoice(ctx, dto.GatewayInvoiceRequest{
+		ExternalId:  payment.ExternalId,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Description: payment.Description,
+		PayerEmail:  acc.Email,
+		Duration:    s.paymentGatewayConfig.GetInvoiceDuration(),
+	})
+	if err != nil {
+		log.Printf("[PAYMENT] create %s invoice for %s failed: %v", s.paymentGateway.Name(), payment.ExternalId, err)
+		if _, err := s.paymentRepo.UpdateStatus(ctx, payment.Id, entity.PaymentStatusPending, entity.PaymentStatusFailed, nil); err != nil {
+			log.Printf("[PAYMENT] mark %s failed: %v", payment.ExternalId, err)
+		}
+		return entity.Payment{}, http_error.PAYMENT_FAILED
+	}
+
+	// Only the invoice fields are written: a callback or an instant settle may already
+	// have moved the payment on.
+	if err := s.paymentRepo.SetInvoice(ctx, payment.Id, inv.Id, inv.URL, inv.ExpiresAt); err != nil {
+		return entity.Payment{}, err
+	}
+	return s.paymentRepo.GetById(ctx, payment.Id)
 }
 
-func (s *paymentService) ConfirmPayment(ctx context.Context, paymentId string) error {
-	return nil
+func (s *paymentService) GetPayment(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID) (entity.Payment, error) {
+	payment, err := s.paymentRepo.GetById(ctx, paymentId)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+	if payment.AccountId != accountId {
+		return entity.Payment{}, http_error.NOT_FOUND_ERROR
+	}
+	return payment, nil
 }
 
-func (s *paymentService) CancelPayment(ctx context.Context, paymentId string) error {
-	return nil
+func (s *paymentService) ConfirmPayment(ctx context.Context, invoiceId string, externalId string) error {
+	payment, err := s.findInvoicePayment(ctx, invoiceId, externalId)
+	if err != nil {
+		return err
+	}
+	return s.confirm(ctx, payment)
 }
 
-func (s *paymentService) ExpirePayment(ctx context.Context, paymentId string) error {
-	return nil
+func (s *paymentService) CancelPayment(ctx context.Context, invoiceId string, externalId string) error {
+	payment, err := s.findInvoicePayment(ctx, invoiceId, externalId)
+	if err != nil {
+		return err
+	}
+	_, err = s.transition(ctx, payment, entity.PaymentStatusFailed, nil)
+	return err
+}
+
+func (s *paymentService) ExpirePayment(ctx context.Context, invoiceId string, externalId string) error {
+	payment, err := s.findInvoicePayment(ctx, invoiceId, externalId)
+	if err != nil {
+		return err
+	}
+	_, err = s.transition(ctx, payment, entity.PaymentStatusExpired, nil)
+	return err
+}
+
+func (s *paymentService) ConfirmPaymentByExternalId(ctx context.Context, externalId string) error {
+	payment, err := s.findPayment(s.paymentRepo.GetByExternalId(ctx, externalId))
+	if err != nil {
+		return err
+	}
+	return s.confirm(ctx, payment)
+}
+
+func (s *paymentService) CancelPaymentByExternalId(ctx context.Context, externalId string) error {
+	payment, err := s.findPayment(s.paymentRepo.GetByExternalId(ctx, externalId))
+	if err != nil {
+		return err
+	}
+	_, err = s.transition(ctx, payment, entity.PaymentStatusFailed, nil)
+	return err
+}
+
+func (s *paymentService) ListPayments(ctx context.Context, accountId uuid.UUID) ([]entity.Payment, error) {
+	return s.paymentRepo.ListByAccountId(ctx, accountId)
+}
+
+// ApplyRefund books a settled refund against its payment and notifies listeners, within
+// the caller's transaction when there is one.
+func (s *paymentService) ApplyRefund(ctx context.Context, paymentId uuid.UUID, refund entity.Refund) (entity.Payment, error) {
+	var payment entity.Payment
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		applied, err := s.paymentRepo.AddRefundedAmount(ctx, paymentId, refund.Amount)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			return http_error.REFUND_AMOUNT_EXCEEDED
+		}
+		payment, err = s.paymentRepo.GetById(ctx, paymentId)
+		if err != nil {
+			return err
+		}
+		for _, listener := range s.listeners {
+			if err := listener.OnPaymentRefunded(ctx, payment, refund); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return payment, err
+}
+
+func (s *paymentService) findPayment(payment entity.Payment, err error) (entity.Payment, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Payment{}, http_error.NOT_FOUND_ERROR
+	}
+	return payment, err
+}
+
+// findInvoicePayment finds the payment an invoice was issued for. The external id covers
+// a callback that arrives before Charge saved the invoice id.
+func (s *paymentService) findInvoicePayment(ctx context.Context, invoiceId string, externalId string) (entity.Payment, error) {
+	err := gorm.ErrRecordNotFound
+	var payment entity.Payment
+	if invoiceId != "" {
+		payment, err = s.paymentRepo.GetByInvoiceId(ctx, invoiceId)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) && externalId != "" {
+		payment, err = s.paymentRepo.GetByExternalId(ctx, externalId)
+	}
+	return s.findPayment(payment, err)
+}
+
+// confirm marks the payment PAID and, when that actually changed it, lets the listeners
+// act in the same transaction.
+func (s *paymentService) confirm(ctx context.Context, payment entity.Payment) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		applied, err := s.transition(ctx, payment, entity.PaymentStatusPaid, map[string]interface{}{"paid_at": &now})
+		if err != nil || !applied {
+			return err
+		}
+		payment.Status = entity.PaymentStatusPaid
+		payment.PaidAt = &now
+		for _, listener := range s.listeners {
+			if err := listener.OnPaymentPaid(ctx, payment); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// transition applies a status change through the state machine and reports whether this
+// call made it. Re-applying a status the payment already reached or went past is a no-op,
+// so repeated provider notifications are harmless.
+func (s *paymentService) transition(ctx context.Context, payment entity.Payment, to string, fields map[string]interface{}) (bool, error) {
+	if paymentPassed(payment.Status, to) {
+		return false, nil
+	}
+	if !canTransitionPayment(payment.Status, to) {
+		return false, fmt.Errorf("%w: %s -> %s", http_error.INVALID_PAYMENT_TRANSITION, payment.Status, to)
+	}
+
+	updated, err := s.paymentRepo.UpdateStatus(ctx, payment.Id, payment.Status, to, fields)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		// Someone else moved the payment first; re-check against the fresh status.
+		current, err := s.paymentRepo.GetById(ctx, payment.Id)
+		if err != nil {
+			return false, err
+		}
+		if !paymentPassed(current.Status, to) {
+			return false, fmt.Errorf("%w: %s -> %s", http_error.INVALID_PAYMENT_TRANSITION, current.Status, to)
+		}
+	}
+	return updated, nil
 }
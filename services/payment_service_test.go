@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeTransactor runs the function without a transaction.
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (fakeTransactor) AfterCommit(ctx context.Context, fn func(ctx context.Context)) { fn(ctx) }
+
+func (fakeTransactor) AfterRollback(ctx context.Context, fn func(ctx context.Context)) {}
+
+// fakePaymentRepo keeps payments in memory. Methods the tests do not need are left to the
+// embedded interface and panic when called.
+type fakePaymentRepo struct {
+	repositories.PaymentRepository
+	mu       sync.Mutex
+	payments map[uuid.UUID]entity.Payment
+}
+
+func newFakePaymentRepo() *fakePaymentRepo {
+	return &fakePaymentRepo{payments: map[uuid.UUID]entity.Payment{}}
+}
+
+func (r *fakePaymentRepo) Create(ctx context.Context, payment entity.Payment) (entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if payment.Id == uuid.Nil {
+		payment.Id = uuid.New()
+	}
+	if payment.CreatedAt.IsZero() {
+		payment.CreatedAt = time.Now()
+	}
+	r.payments[payment.Id] = payment
+	return payment, nil
+}
+
+func (r *fakePaymentRepo) GetById(ctx context.Context, id uuid.UUID) (entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payment, ok := r.payments[id]
+	if !ok {
+		return entity.Payment{}, gorm.ErrRecordNotFound
+	}
+	return payment, nil
+}
+
+func (r *fakePaymentRepo) find(match func(entity.Payment) bool) (entity.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, payment := range r.payments {
+		if match(payment) {
+			return payment, nil
+		}
+	}
+	return entity.Payment{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakePaymentRepo) GetByInvoiceId(ctx context.Context, invoiceId string) (entity.Payment, error) {
+	return r.find(func(p entity.Payment) bool { return p.InvoiceId == invoiceId })
+}
+
+func (r *fakePaymentRepo) GetByExternalId(ctx context.Context, externalId string) (entity.Payment, error) {
+	return r.find(func(p entity.Payment) bool { return p.ExternalId == externalId })
+}
+
+func (r *fakePaymentRepo) SetInvoice(ctx context.Context, id uuid.UUID, invoiceId string, invoiceURL string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payment := r.payments[id]
+	payment.InvoiceId = invoiceId
+	payment.InvoiceURL = invoiceURL
+	payment.ExpiresAt = &expiresAt
+	r.payments[id] = payment
+	return nil
+}
+
+func (r *fakePaymentRepo) UpdateStatus(ctx context.Context, id uuid.UUID, from string, to string, fields map[string]interface{}) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payment, ok := r.payments[id]
+	if !ok || payment.Status != from {
+		return false, nil
+	}
+	payment.Status = to
+	if paidAt, ok := fields["paid_at"].(*time.Time); ok {
+		payment.PaidAt = paidAt
+	}
+	r.payments[id] = payment
+	return true, nil
+}
+
+type fakeAccountRepo struct {
+	repositories.AccountRepository
+}
+
+func (fakeAccountRepo) GetAccountById(ctx context.Context, accountId uuid.UUID) (entity.Account, error) {
+	return entity.Account{Id: accountId, Email: "payer@example.com"}, nil
+}
+
+type fakePaymentGatewayConfig struct {
+	config.PaymentGatewayConfig
+}
+
+func (fakePaymentGatewayConfig) GetInvoiceDuration() time.Duration { return time.Hour }
+
+// fakePaymentGateway issues invoices and answers invoice lookups from memory. onCreate,
+// when set, runs before CreateInvoice returns, like a callback overtaking the response.
+type fakePaymentGateway struct {
+	mu       sync.Mutex
+	invoices map[string]dto.GatewayInvoice
+	fail     error
+	onCreate func(inv dto.GatewayInvoice)
+}
+
+func newFakePaymentGateway() *fakePaymentGateway {
+	return &fakePaymentGateway{invoices: map[string]dto.GatewayInvoice{}}
+}
+
+func (g *fakePaymentGateway) Name() string { return "fake" }
+
+func (g *fakePaymentGateway) CreateInvoice(ctx context.Context, req dto.GatewayInvoiceRequest) (dto.GatewayInvoice, error) {
+	if g.fail != nil {
+		return dto.GatewayInvoice{}, g.fail
+	}
+	inv := dto.GatewayInvoice{
+		Id:         "inv-" + req.ExternalId,
+		ExternalId: req.ExternalId,
+		URL:        "https://pay.example.com/" + req.ExternalId,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Status:     entity.PaymentStatusPending,
+		ExpiresAt:  time.Now().Add(req.Duration),
+	}
+	g.setInvoice(inv)
+	if g.onCreate != nil {
+		g.onCreate(inv)
+	}
+	return inv, nil
+}
+
+func (g *fakePaymentGateway) setInvoice(inv dto.GatewayInvoice) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invoices[inv.Id] = inv
+}
+
+func (g *fakePaymentGateway) GetInvoice(ctx context.Context, invoiceId string) (dto.GatewayInvoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	inv, ok := g.invoices[invoiceId]
+	if !ok {
+		return dto.GatewayInvoice{}, http_error.NOT_FOUND_ERROR
+	}
+	return inv, nil
+}
+
+func (g *fakePaymentGateway) CreateRefund(ctx context.Context, req dto.GatewayRefundRequest) (dto.GatewayRefund, error) {
+	return dto.GatewayRefund{Id: "ref-" + req.ReferenceId, ReferenceId: req.ReferenceId, Amount: req.Amount, Status: entity.RefundStatusPending}, nil
+}
+
+func newTestPaymentService(gateway PaymentGateway, repo repositories.PaymentRepository) PaymentService {
+	return NewPaymentService(fakeTransactor{}, gateway, fakePaymentGatewayConfig{}, repo, fakeAccountRepo{})
+}
+
+func TestChargeCreatesPendingPaymentWithInvoice(t *testing.T) {
+	repo := newFakePaymentRepo()
+	service := newTestPaymentService(newFakePaymentGateway(), repo)
+
+	payment, err := service.CreatePayment(context.Background(), uuid.New(), dto.CreatePaymentRequest{Amount: 50000, Description: "Course"})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	if payment.Status != entity.PaymentStatusPending {
+		t.Errorf("status = %s, want %s", payment.Status, entity.PaymentStatusPending)
+	}
+	if payment.Currency != "IDR" || payment.InvoiceId == "" || payment.InvoiceURL == "" || payment.ExpiresAt == nil {
+		t.Errorf("invoice not recorded: %+v", payment)
+	}
+}
+
+func TestChargeRejectsInvalidAmount(t *testing.T) {
+	service := newTestPaymentService(newFakePaymentGateway(), newFakePaymentRepo())
+	_, err := service.CreatePayment(context.Background(), uuid.New(), dto.CreatePaymentRequest{Amount: -1})
+	if !errors.Is(err, http_error.BAD_REQUEST_ERROR) {
+		t.Fatalf("err = %v, want BAD_REQUEST_ERROR", err)
+	}
+}
+
+func TestChargeMarksPaymentFailedWhenInvoiceFails(t *testing.T) {
+	repo := newFakePaymentRepo()
+	gateway := newFakePaymentGateway()
+	gateway.fail = errors.New("gateway down")
+	service := newTestPaymentService(gateway, repo)
+
+	_, err := service.CreatePayment(context.Background(), uuid.New(), dto.CreatePaymentRequest{Amount: 1000})
+	if !errors.Is(err, http_error.PAYMENT_FAILED) {
+		t.Fatalf("err = %v, want PAYMENT_FAILED", err)
+	}
+	for _, payment := range repo.payments {
+		if payment.Status != entity.PaymentStatusFailed {
+			t.Errorf("status = %s, want %s", payment.Status, entity.PaymentStatusFailed)
+		}
+	}
+}
+
+func TestChargeKeepsStatusSetBeforeInvoiceIsSaved(t *testing.T) {
+	repo := newFakePaymentRepo()
+	gateway := newFakePaymentGateway()
+	service := newTestPaymentService(gateway, repo)
+	gateway.onCreate = func(inv dto.GatewayInvoice) {
+		// The invoice id is not saved yet, so the callback is found by external id.
+		if err := service.ConfirmPayment(context.Background(), inv.Id, inv.ExternalId); err != nil {
+			t.Errorf("ConfirmPayment: %v", err)
+		}
+	}
+
+	payment, err := service.CreatePayment(context.Background(), uuid.New(), dto.CreatePaymentRequest{Amount: 1000})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	if payment.Status != entity.PaymentStatusPaid || payment.PaidAt == nil {
+		t.Errorf("status = %s, paid_at = %v; want PAID with paid_at", payment.Status, payment.PaidAt)
+	}
+	if payment.InvoiceId == "" {
+		t.Error("invoice id not saved")
+	}
+}
+
+func TestPaymentTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		apply   func(service PaymentService, invoiceId string) error
+		want    string
+		wantErr error
+	}{
+		{"pending to paid", entity.PaymentStatusPending, confirmInvoice, entity.PaymentStatusPaid, nil},
+		{"pending to expired", entity.PaymentStatusPending, expireInvoice, entity.PaymentStatusExpired, nil},
+		{"pending to failed", entity.PaymentStatusPending, cancelInvoice, entity.PaymentStatusFailed, nil},
+		{"paid again is a no-op", entity.PaymentStatusPaid, confirmInvoice, entity.PaymentStatusPaid, nil},
+		{"refunded has passed paid", entity.PaymentStatusRefunded, confirmInvoice, entity.PaymentStatusRefunded, nil},
+		{"paid cannot expire", entity.PaymentStatusPaid, expireInvoice, entity.PaymentStatusPaid, http_error.INVALID_PAYMENT_TRANSITION},
+		{"expired cannot be paid", entity.PaymentStatusExpired, confirmInvoice, entity.PaymentStatusExpired, http_error.INVALID_PAYMENT_TRANSITION},
+		{"failed cannot be paid", entity.PaymentStatusFailed, confirmInvoice, entity.PaymentStatusFailed, http_error.INVALID_PAYMENT_TRANSITION},
+		{"paid cannot fail", entity.PaymentStatusPaid, cancelInvoice, entity.PaymentStatusPaid, http_error.INVALID_PAYMENT_TRANSITION},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakePaymentRepo()
+			service := newTestPaymentService(newFakePaymentGateway(), repo)
+			payment, _ := repo.Create(context.Background(), entity.Payment{
+				ExternalId: "PAY-" + uuid.NewString(),
+				InvoiceId:  "inv-1",
+				Amount:     1000,
+				Status:     tt.from,
+			})
+
+			err := tt.apply(service, payment.InvoiceId)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want none", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			got, _ := repo.GetById(context.Background(), payment.Id)
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmUnknownInvoiceIsNotFound(t *testing.T) {
+	service := newTestPaymentService(newFakePaymentGateway(), newFakePaymentRepo())
+	err := service.ConfirmPayment(context.Background(), "inv-missing", "PAY-missing")
+	if !errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		t.Fatalf("err = %v, want NOT_FOUND_ERROR", err)
+	}
+}
+
+func confirmInvoice(service PaymentService, invoiceId string) error {
+	return service.ConfirmPayment(context.Background(), invoiceId, "")
+}
+
+func expireInvoice(service PaymentService, invoiceId string) error {
+	return service.ExpirePayment(context.Background(), invoiceId, "")
+}
+
+func cancelInvoice(service PaymentService, invoiceId string) error {
+	return service.CancelPayment(context.Background(), invoiceId, "")
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductService interface {
+	List(ctx context.Context, activeOnly bool) ([]entity.Product, error)
+	GetByCode(ctx context.Context, code string) (entity.Product, error)
+	Create(ctx context.Context, req dto.CreateProductRequest) (entity.Product, error)
+	Update(ctx context.Context, productId uuid.UUID, req dto.UpdateProductRequest) (entity.Product, error)
+	CreatePrice(ctx context.Context, productId uuid.UUID, req dto.CreatePriceRequest) (entity.Price, error)
+	UpdatePrice(ctx context.Context, priceId uuid.UUID, req dto.UpdatePriceRequest) (entity.Price, error)
+}
+
+type productService struct {
+	productRepo repositories.ProductRepository
+}
+
+func NewProductService(productRepo repositories.ProductRepository) ProductService {
+	return &productService{productRepo: productRepo}
+}
+
+// List returns the catalog. With activeOnly, inactive products and prices are left out.
+func (s *productService) List(ctx context.Context, activeOnly bool) ([]entity.Product, error) {
+	products, err := s.productRepo.ListProducts(ctx, activeOnly)
+	if err != nil || !activeOnly {
+		return products, err
+	}
+	for i := range products {
+		products[i].Prices = activePrices(products[i].Prices)
+	}
+	return products, nil
+}
+
+// GetByCode returns an active product with its active prices.
+func (s *productService) GetByCode(ctx context.Context, code string) (entity.Product, error) {
+	product, err := s.productRepo.GetProductByCode(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !product.IsActive) {
+		return entity.Product{}, http_error.NOT_FOUND_ERROR
+	} else if err != nil {
+		return entity.Product{}, err
+	}
+	product.Prices = activePrices(product.Prices)
+	return product, nil
+}
+
+func (s *productService) Create(ctx context.Context, req dto.CreateProductRequest) (entity.Product, error) {
+	product, err := s.productRepo.CreateProduct(ctx, entity.Product{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    true,
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return entity.Product{}, http_error.DUPLICATE_DATA
+	}
+	return product, err
+}
+
+func (s *productService) Update(ctx context.Context, productId uuid.UUID, req dto.UpdateProductRequest) (entity.Product, error) {
+	product, err := s.productRepo.GetProductById(ctx, productId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Product{}, http_error.NOT_FOUND_ERROR
+	} else if err != nil {
+		return entity.Product{}, err
+	}
+
+	if req.Name != "" {
+		product.Name = req.Name
+	}
+	if req.Description != "" {
+		product.Description = req.Description
+	}
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+	return s.productRepo.UpdateProduct(ctx, product)
+}
+
+func (s *productService) CreatePrice(ctx context.Context, productId uuid.UUID, req dto.CreatePriceRequest) (entity.Price, error) {
+	if _, err := s.productRepo.GetProductById(ctx, productId); errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Price{}, http_error.NOT_FOUND_ERROR
+	} else if err != nil {
+		return entity.Price{}, err
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = "IDR"
+	}
+	return s.productRepo.CreatePrice(ctx, entity.Price{
+		ProductId:       productId,
+		Amount:          req.Amount,
+		Currency:        currency,
+		EntitlementDays: req.EntitlementDays,
+		IntervalMonths:  req.IntervalMonths,
+		IsActive:        true,
+	})
+}
+
+// UpdatePrice only toggles availability; a price that was paid stays as it was so past
+// payments keep describing what was bought.
+func (s *productService) UpdatePrice(ctx context.Context, priceId uuid.UUID, req dto.UpdatePriceRequest) (entity.Price, error) {
+	price, err := s.productRepo.GetPriceById(ctx, priceId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Price{}, http_error.NOT_FOUND_ERROR
+	} else if err != nil {
+		return entity.Price{}, err
+	}
+	price.IsActive = *req.IsActive
+	return s.productRepo.UpdatePrice(ctx, price)
+}
+
+func activePrices(prices []entity.Price) []entity.Price {
+	active := make([]entity.Price, 0, len(prices))
+	for _, price := range prices {
+		if price.IsActive {
+			active = append(active, price)
+		}
+	}
+	return active
+}
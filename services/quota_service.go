@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuotaService limits the total bytes and number of files an account stores. The
+// limits come from QuotaConfig, overridden for a role and then for the account itself.
+// Trashed files count until they are deleted for good.
+type QuotaService interface {
+	Usage(ctx context.Context, accountId uuid.UUID) (dto.StorageUsageResponse, error)
+	Check(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error
+	Reserve(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error
+	Record(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error
+	ListOverrides(ctx context.Context) ([]entity.StorageQuota, error)
+	SetOverride(ctx context.Context, scope string, subject string, req dto.StorageQuotaRequest) (entity.StorageQuota, error)
+	DeleteOverride(ctx context.Context, scope string, subject string) error
+}
+
+type quotaService struct {
+	quotaConfig config.QuotaConfig
+	quotaRepo   repositories.QuotaRepository
+	accountRepo repositories.AccountRepository
+}
+
+func NewQuotaService(quotaConfig config.QuotaConfig, quotaRepo repositories.QuotaRepository, accountRepo repositories.AccountRepository) QuotaService {
+	return &quotaService{
+		quotaConfig: quotaConfig,
+		quotaRepo:   quotaRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+func (s *quotaService) Usage(ctx context.Context, accountId uuid.UUID) (dto.StorageUsageResponse, error) {
+	maxBytes, maxFiles, err := s.limits(ctx, accountId)
+	if err != nil {
+		return dto.StorageUsageResponse{}, err
+	}
+	if err := s.quotaRepo.EnsureUsage(ctx, accountId); err != nil {
+		return dto.StorageUsageResponse{}, err
+	}
+	usage, err := s.quotaRepo.GetUsage(ctx, accountId)
+	if err != nil {
+		return dto.StorageUsageResponse{}, err
+	}
+	return dto.StorageUsageResponse{
+		AccountId: accountId,
+		Bytes:     usage.Bytes,
+		Files:     usage.Files,
+		MaxBytes:  maxBytes,
+		MaxFiles:  maxFiles,
+	}, nil
+}
+
+// Check tells whether the account has room for bytes and files without taking it, to
+// refuse a large upload before it is sent. Only Reserve is authoritative.
+func (s *quotaService) Check(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error {
+	usage, err := s.Usage(ctx, accountId)
+	if err != nil {
+		return err
+	}
+	if exceeds(usage.Bytes+bytes, usage.MaxBytes) || exceeds(usage.Files+files, usage.MaxFiles) {
+		return http_error.QUOTA_EXCEEDED
+	}
+	return nil
+}
+
+// Reserve takes room for bytes and files, or fails with QUOTA_EXCEEDED taking nothing.
+// It is meant to run in the transaction that stores the upload: the reservation is
+// undone if that fails, and other uploads of the account wait until it ends.
+func (s *quotaService) Reserve(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error {
+	maxBytes, maxFiles, err := s.limits(ctx, accountId)
+	if err != nil {
+		return err
+	}
+	if err := s.quotaRepo.EnsureUsage(ctx, accountId); err != nil {
+		return err
+	}
+	reserved, err := s.quotaRepo.AddUsage(ctx, accountId, bytes, files, maxBytes, maxFiles)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return http_error.QUOTA_EXCEEDED
+	}
+	return nil
+}
+
+// Record changes the usage without any limit, for files the app stores itself and for
+// deletions, which pass negative amounts.
+func (s *quotaService) Record(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error {
+	if err := s.quotaRepo.EnsureUsage(ctx, accountId); err != nil {
+		return err
+	}
+	_, err := s.quotaRepo.AddUsage(ctx, accountId, bytes, files, 0, 0)
+	return err
+}
+
+func (s *quotaService) ListOverrides(ctx context.Context) ([]entity.StorageQuota, error) {
+	return s.quotaRepo.ListQuotas(ctx)
+}
+
+// SetOverride creates or replaces the limits of a role or an account. The account must
+// exist; any role name is accepted, as roles are plain strings.
+func (s *quotaService) SetOverride(ctx context.Context, scope string, subject string, req dto.StorageQuotaRequest) (entity.StorageQuota, error) {
+	subject, err := s.overrideSubject(ctx, scope, subject)
+	if err != nil {
+		return entity.StorageQuota{}, err
+	}
+	now := time.Now()
+	return s.quotaRepo.SaveQuota(ctx, entity.StorageQuota{
+		Id:        uuid.New(),
+		Scope:     scope,
+		Subject:   subject,
+		MaxBytes:  req.MaxBytes,
+		MaxFiles:  req.MaxFiles,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *quotaService) DeleteOverride(ctx context.Context, scope string, subject string) error {
+	subject, err := s.overrideSubject(ctx, scope, subject)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.quotaRepo.DeleteQuota(ctx, scope, subject)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return http_error.NOT_FOUND_ERROR
+	}
+	return nil
+}
+
+// limits resolves the limits of an account: its own override, then its role's, then the
+// default, limit by limit.
+func (s *quotaService) limits(ctx context.Context, accountId uuid.UUID) (int64, int64, error) {
+	maxBytes, maxFiles := s.quotaConfig.GetMaxBytes(), s.quotaConfig.GetMaxFiles()
+	account, err := s.accountRepo.GetAccountById(ctx, accountId)
+	if err != nil {
+		return 0, 0, http_error.UNAUTHORIZED
+	}
+	for _, key := range [][2]string{
+		{entity.QuotaScopeRole, strings.ToLower(account.Role)},
+		{entity.QuotaScopeAccount, accountId.String()},
+	} {
+		quota, err := s.quotaRepo.GetQuota(ctx, key[0], key[1])
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if quota.MaxBytes != nil {
+			maxBytes = *quota.MaxBytes
+		}
+		if quota.MaxFiles != nil {
+			maxFiles = *quota.MaxFiles
+		}
+	}
+	return maxBytes, maxFiles, nil
+}
+
+func (s *quotaService) overrideSubject(ctx context.Context, scope string, subject string) (string, error) {
+	switch scope {
+	case entity.QuotaScopeRole:
+		subject = strings.ToLower(strings.TrimSpace(subject))
+		if subject == "" {
+			return "", http_error.BAD_REQUEST_ERROR
+		}
+		return subject, nil
+	case entity.QuotaScopeAccount:
+		accountId, err := uuid.Parse(subject)
+		if err != nil {
+			return "", http_error.BAD_REQUEST_ERROR
+		}
+		if _, err := s.accountRepo.GetAccountById(ctx, accountId); err != nil {
+			return "", http_error.NOT_FOUND_ERROR
+		}
+		return accountId.String(), nil
+	default:
+		return "", http_error.BAD_REQUEST_ERROR
+	}
+}
+
+// exceeds reports whether value is over limit, a zero limit being none.
+func exceeds(value int64, limit int64) bool {
+	return limit > 0 && value > limit
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"abdanhafidz.com/go-boilerplate/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const receiptBatchSize = 50
+
+// ReceiptService numbers a receipt for every PAID payment and renders it to a PDF kept in
+// storage. It is registered as a PaymentListener; rendering happens outside the payment
+// transaction, in RenderPending or on the first download.
+type ReceiptService interface {
+	PaymentListener
+	Get(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID) (entity.Receipt, error)
+	Download(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID) (entity.Receipt, []byte, error)
+	RenderPending(ctx context.Context) (int, error)
+}
+
+type receiptService struct {
+	receiptConfig  config.ReceiptConfig
+	uploadService  UploadService
+	storageService StorageService
+	mailService    MailService
+	receiptRepo    repositories.ReceiptRepository
+	fileRepo       repositories.FileRepository
+	rendering      sync.Mutex
+}
+
+func NewReceiptService(receiptConfig config.ReceiptConfig, uploadService UploadService, storageService StorageService, mailService MailService, receiptRepo repositories.ReceiptRepository, fileRepo repositories.FileRepository) ReceiptService {
+	return &receiptService{
+		receiptConfig:  receiptConfig,
+		uploadService:  uploadService,
+		storageService: storageService,
+		mailService:    mailService,
+		receiptRepo:    receiptRepo,
+		fileRepo:       fileRepo,
+	}
+}
+
+// OnPaymentPaid takes the next number of the year for the payment. It runs inside the
+// transaction that marks the payment PAID, so a rollback also returns the number.
+func (s *receiptService) OnPaymentPaid(ctx context.Context, payment entity.Payment) error {
+	_, err := s.receiptRepo.GetByPaymentId(ctx, payment.Id)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	issuedAt := time.Now()
+	if payment.PaidAt != nil {
+		issuedAt = *payment.PaidAt
+	}
+	number, err := s.receiptRepo.NextNumber(ctx, issuedAt.Year())
+	if err != nil {
+		return err
+	}
+	_, err = s.receiptRepo.Create(ctx, entity.Receipt{
+		PaymentId: payment.Id,
+		AccountId: payment.AccountId,
+		Number:    fmt.Sprintf("RCP-%d-%06d", issuedAt.Year(), number),
+		IssuedAt:  issuedAt,
+	})
+	return err
+}
+
+// OnPaymentRefunded keeps the receipt as issued; refunds are tracked on the payment.
+func (s *receiptService) OnPaymentRefunded(ctx context.Context, payment entity.Payment, refund entity.Refund) error {
+	return nil
+}
+
+func (s *receiptService) Get(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID) (entity.Receipt, error) {
+	receipt, err := s.receiptRepo.GetByPaymentId(ctx, paymentId)
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+	if receipt.AccountId != accountId {
+		return entity.Receipt{}, http_error.NOT_FOUND_ERROR
+	}
+	return receipt, nil
+}
+
+// Download returns the receipt PDF of a payment owned by the account, rendering it first
+// when the background job has not got to it yet.
+func (s *receiptService) Download(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID) (entity.Receipt, []byte, error) {
+	receipt, err := s.Get(ctx, accountId, paymentId)
+	if err != nil {
+		return entity.Receipt{}, nil, err
+	}
+	if receipt.FileId == nil {
+		return s.render(ctx, receipt.Id)
+	}
+	file, err := s.fileRepo.FindByID(ctx, *receipt.FileId)
+	if err != nil {
+		return entity.Receipt{}, nil, err
+	}
+	content, err := s.storageService.DownloadFile(ctx, file.StorageKey)
+	if err != nil {
+		return entity.Receipt{}, nil, err
+	}
+	return receipt, content, nil
+}
+
+// RenderPending renders receipts that have no PDF yet and returns how many it rendered.
+// A failing receipt keeps its error and is retried on the next run.
+func (s *receiptService) RenderPending(ctx context.Context) (int, error) {
+	pending, err := s.receiptRepo.ListUnrendered(ctx, receiptBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	rendered := 0
+	for _, receipt := range pending {
+		if _, _, err := s.render(ctx, receipt.Id); err != nil {
+			log.Printf("[RECEIPT] render %s failed: %v", receipt.Number, err)
+			continue
+		}
+		rendered++
+	}
+	return rendered, nil
+}
+
+// render builds, stores and links the PDF of a receipt, then emails it when enabled.
+// Rendering is serialised so the job and a download never store the same receipt twice.
+func (s *receiptService) render(ctx context.Context, receiptId uuid.UUID) (entity.Receipt, []byte, error) {
+	s.rendering.Lock()
+	defer s.rendering.Unlock()
+
+	receipt, err := s.receiptRepo.GetById(ctx, receiptId)
+	if err != nil {
+		return entity.Receipt{}, nil, err
+	}
+	content := s.document(receipt)
+	if receipt.FileId != nil {
+		return receipt, content, nil
+	}
+
+	file, err := s.uploadService.UploadRawFile(ctx, bytes.NewReader(content), receipt.Number+".pdf", "application/pdf", "receipt", receipt.AccountId)
+	if err != nil {
+		return entity.Receipt{}, nil, s.fail(ctx, receipt, err)
+	}
+	if _, err := s.receiptRepo.SetFile(ctx, receipt.Id, file.Id); err != nil {
+		return entity.Receipt{}, nil, s.fail(ctx, receipt, err)
+	}
+	receipt.FileId = &file.Id
+	receipt.File = file
+	receipt.Error = ""
+
+	if s.receiptConfig.IsEmailEnabled() && receipt.EmailedAt == nil {
+		s.email(ctx, &receipt, content)
+	}
+	return receipt, content, nil
+}
+
+// email sends the receipt to the payer. A failed email is recorded on the receipt but
+// does not fail the rendering.
+func (s *receiptService) email(ctx context.Context, receipt *entity.Receipt, content []byte) {
+	if receipt.Payment == nil || receipt.Payment.Account == nil || receipt.Payment.Account.Email == "" {
+		return
+	}
+	body := fmt.Sprintf("Thank you for your payment. Your receipt %s is attached.", receipt.Number)
+	err := s.mailService.SendWithAttachment(ctx, receipt.Payment.Account.Email, "Receipt "+receipt.Number, body, dto.MailAttachment{
+		Name:        receipt.Number + ".pdf",
+		ContentType: "application/pdf",
+		Content:     content,
+	})
+	if err != nil {
+		log.Printf("[RECEIPT] email %s failed: %v", receipt.Number, err)
+		s.receiptRepo.SetError(ctx, receipt.Id, "email: "+err.Error())
+		return
+	}
+	now := time.Now()
+	if err := s.receiptRepo.MarkEmailed(ctx, receipt.Id, now); err == nil {
+		receipt.EmailedAt = &now
+	}
+}
+
+func (s *receiptService) fail(ctx context.Context, receipt entity.Receipt, err error) error {
+	s.receiptRepo.SetError(ctx, receipt.Id, err.Error())
+	return err
+}
+
+// document lays the receipt out on a single A4 page.
+func (s *receiptService) document(receipt entity.Receipt) []byte {
+	payment := entity.Payment{}
+	if receipt.Payment != nil {
+		payment = *receipt.Payment
+	}
+	billedTo := payment.AccountId.String()
+	if payment.Account != nil && payment.Account.Email != "" {
+		billedTo = payment.Account.Email
+	}
+
+	doc := utils.NewPDFDocument()
+	doc.AddPage()
+	doc.Text(50, 70, 20, true, s.receiptConfig.GetIssuer())
+	doc.Text(50, 100, 14, false, "Payment Receipt")
+	doc.Line(50, 115, utils.PDFPageWidth-50, 115)
+
+	y := 145.0
+	row := func(label string, value string) {
+		doc.Text(50, y, 11, true, label)
+		doc.Text(200, y, 11, false, value)
+		y += 20
+	}
+	row("Receipt number", receipt.Number)
+	row("Issued at", receipt.IssuedAt.Format("02 January 2006 15:04 MST"))
+	row("Billed to", billedTo)
+	row("Payment reference", payment.ExternalId)
+	if payment.Description != "" {
+		row("Description", payment.Description)
+	}
+
+	y += 10
+	doc.Line(50, y, utils.PDFPageWidth-50, y)
+	y += 25
+	subtotal := payment.Amount + payment.DiscountAmount
+	row("Subtotal", formatMoney(payment.Currency, subtotal))
+	if payment.DiscountAmount > 0 {
+		row("Discount", "-"+formatMoney(payment.Currency, payment.DiscountAmount))
+	}
+	doc.Text(50, y, 13, true, "Total paid")
+	doc.Text(200, y, 13, true, formatMoney(payment.Currency, payment.Amount))
+
+	doc.Text(50, utils.PDFPageHeight-50, 9, false, "This receipt was issued electronically and is valid without a signature.")
+	return doc.Bytes()
+}
+
+// formatMoney prints an amount with thousand separators; IDR has no minor unit.
+func formatMoney(currency string, amount float64) string {
+	currency = strings.ToUpper(currency)
+	decimals := 2
+	if currency == "IDR" || currency == "" {
+		decimals = 0
+	}
+	text := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		grouped.WriteString("." + fraction)
+	}
+	if currency == "" {
+		return grouped.String()
+	}
+	return currency + " " + grouped.String()
+}
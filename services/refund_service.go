@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefundService interface {
+	Request(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID, req dto.CreateRefundRequest) (entity.Refund, error)
+	List(ctx context.Context, status string) ([]entity.Refund, error)
+	Approve(ctx context.Context, adminId uuid.UUID, refundId uuid.UUID, req dto.ReviewRefundRequest) (entity.Refund, error)
+	Reject(ctx context.Context, adminId uuid.UUID, refundId uuid.UUID, req dto.ReviewRefundRequest) (entity.Refund, error)
+	ApplyProviderStatus(ctx context.Context, referenceId string, providerRefundId string, status string, failureCode string) error
+}
+
+type refundService struct {
+	transactor     repositories.Transactor
+	paymentGateway PaymentGateway
+	paymentService PaymentService
+	paymentRepo    repositories.PaymentRepository
+	refundRepo     repositories.RefundRepository
+}
+
+func NewRefundService(transactor repositories.Transactor, paymentGateway PaymentGateway, paymentService PaymentService, paymentRepo repositories.PaymentRepository, refundRepo repositories.RefundRepository) RefundService {
+	return &refundService{
+		transactor:     transactor,
+		paymentGateway: paymentGateway,
+		paymentService: paymentService,
+		paymentRepo:    paymentRepo,
+		refundRepo:     refundRepo,
+	}
+}
+
+// Request records a refund request of the payment owner. Requested, in-flight and
+// settled refunds together may never exceed the payment amount; the payment row is locked
+// while they are summed, so concurrent requests are checked one after another.
+func (s *refundService) Request(ctx context.Context, accountId uuid.UUID, paymentId uuid.UUID, req dto.CreateRefundRequest) (entity.Refund, error) {
+	var refund entity.Refund
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		payment, err := s.paymentRepo.LockById(ctx, paymentId)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && payment.AccountId != accountId) {
+			return http_error.NOT_FOUND_ERROR
+		} else if err != nil {
+			return err
+		}
+		if payment.Status != entity.PaymentStatusPaid && payment.Status != entity.PaymentStatusPartiallyRefunded {
+			return http_error.REFUND_NOT_ALLOWED
+		}
+
+		outstanding, err := s.refundRepo.SumOutstandingByPaymentId(ctx, payment.Id)
+		if err != nil {
+			return err
+		}
+		refundable := payment.Amount - outstanding
+		amount := req.Amount
+		if amount == 0 {
+			amount = refundable
+		}
+		if amount <= 0 || amount > refundable {
+			return http_error.REFUND_AMOUNT_EXCEEDED
+		}
+
+		refund, err = s.refundRepo.Create(ctx, entity.Refund{
+			PaymentId: payment.Id,
+			AccountId: payment.AccountId,
+			Amount:    amount,
+			Reason:    req.Reason,
+			Status:    entity.RefundStatusRequested,
+		})
+		return err
+	})
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	return refund, nil
+}
+
+func (s *refundService) List(ctx context.Context, status string) ([]entity.Refund, error) {
+	return s.refundRepo.List(ctx, status)
+}
+
+// Approve claims the request and submits it to the gateway. The refund stays PENDING
+// until the provider reports its outcome through a refund callback.
+func (s *refundService) Approve(ctx context.Context, adminId uuid.UUID, refundId uuid.UUID, req dto.ReviewRefundRequest) (entity.Refund, error) {
+	refund, err := s.review(ctx, adminId, refundId, entity.RefundStatusPending, req.Note)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	payment, err := s.paymentRepo.GetById(ctx, refund.PaymentId)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	res, err := s.paymentGateway.CreateRefund(ctx, dto.GatewayRefundRequest{
+		ReferenceId: refund.Id.String(),
+		InvoiceId:   payment.InvoiceId,
+		Amount:      refund.Amount,
+		Currency:    payment.Currency,
+		Reason:      refund.Reason,
+	})
+	if err != nil {
+		log.Printf("[PAYMENT][REFUND] %s refund %s failed: %v", s.paymentGateway.Name(), refund.Id, err)
+		if _, err := s.refundRepo.UpdateStatus(ctx, refund.Id, entity.RefundStatusPending, entity.RefundStatusFailed,
+			map[string]interface{}{"failure_code": "GATEWAY_ERROR"}); err != nil {
+			log.Printf("[PAYMENT][REFUND] mark %s failed: %v", refund.Id, err)
+		}
+		return entity.Refund{}, http_error.PAYMENT_FAILED
+	}
+
+	// The provider's callback may already have settled the refund, so only a still
+	// pending row gets the provider id here.
+	if _, err := s.refundRepo.UpdateStatus(ctx, refund.Id, entity.RefundStatusPending, entity.RefundStatusPending,
+		map[string]interface{}{"provider_refund_id": res.Id}); err != nil {
+		return entity.Refund{}, err
+	}
+	if res.Status == entity.RefundStatusSucceeded || res.Status == entity.RefundStatusFailed {
+		if err := s.ApplyProviderStatus(ctx, refund.Id.String(), res.Id, res.Status, res.FailureCode); err != nil {
+			return entity.Refund{}, err
+		}
+	}
+	return s.refundRepo.GetById(ctx, refund.Id)
+}
+
+func (s *refundService) Reject(ctx context.Context, adminId uuid.UUID, refundId uuid.UUID, req dto.ReviewRefundRequest) (entity.Refund, error) {
+	return s.review(ctx, adminId, refundId, entity.RefundStatusRejected, req.Note)
+}
+
+// review moves a REQUESTED refund to the admin's decision. The conditional update makes
+// sure two admins cannot both act on the same request.
+func (s *refundService) review(ctx context.Context, adminId uuid.UUID, refundId uuid.UUID, to string, note string) (entity.Refund, error) {
+	refund, err := s.refundRepo.GetById(ctx, refundId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Refund{}, http_error.NOT_FOUND_ERROR
+	} else if err != nil {
+		return entity.Refund{}, err
+	}
+	if refund.Status != entity.RefundStatusRequested {
+		return entity.Refund{}, fmt.Errorf("%w: %s -> %s", http_error.INVALID_REFUND_TRANSITION, refund.Status, to)
+	}
+
+	now := time.Now()
+	updated, err := s.refundRepo.UpdateStatus(ctx, refund.Id, entity.RefundStatusRequested, to, map[string]interface{}{
+		"reviewed_by": adminId,
+		"review_note": note,
+		"reviewed_at": &now,
+	})
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	if !updated {
+		return entity.Refund{}, fmt.Errorf("%w: already reviewed", http_error.INVALID_REFUND_TRANSITION)
+	}
+
+	refund.Status = to
+	refund.ReviewedBy = &adminId
+	refund.ReviewNote = note
+	refund.ReviewedAt = &now
+	return refund, nil
+}
+
+// ApplyProviderStatus settles a PENDING refund with the provider's outcome. A succeeded
+// refund is booked against its payment in the same transaction, so a failure leaves the
+// refund PENDING and the callback can be retried. Repeated outcomes are no-ops.
+func (s *refundService) ApplyProviderStatus(ctx context.Context, referenceId string, providerRefundId string, status string, failureCode string) error {
+	var to string
+	switch status {
+	case entity.RefundStatusSucceeded:
+		to = entity.RefundStatusSucceeded
+	case entity.RefundStatusFailed, "CANCELLED":
+		to = entity.RefundStatusFailed
+	default:
+		return nil
+	}
+
+	refund, err := s.findByReference(ctx, referenceId, providerRefundId)
+	if err != nil {
+		return err
+	}
+	if refund.Status == to {
+		return nil
+	}
+	if refund.Status != entity.RefundStatusPending {
+		return fmt.Errorf("%w: %s -> %s", http_error.INVALID_REFUND_TRANSITION, refund.Status, to)
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		fields := map[string]interface{}{"completed_at": &now}
+		if providerRefundId != "" {
+			fields["provider_refund_id"] = providerRefundId
+		}
+		if failureCode != "" {
+			fields["failure_code"] = failureCode
+		}
+		updated, err := s.refundRepo.UpdateStatus(ctx, refund.Id, entity.RefundStatusPending, to, fields)
+		if err != nil || !updated {
+			return err
+		}
+		if to != entity.RefundStatusSucceeded {
+			return nil
+		}
+		refund.Status = to
+		refund.CompletedAt = &now
+		_, err = s.paymentService.ApplyRefund(ctx, refund.PaymentId, refund)
+		return err
+	})
+}
+
+// findByReference looks the refund up by the reference id we gave the provider, falling
+// back to the provider's own refund id.
+func (s *refundService) findByReference(ctx context.Context, referenceId string, providerRefundId string) (entity.Refund, error) {
+	var (
+		refund entity.Refund
+		err    = gorm.ErrRecordNotFound
+	)
+	if id, parseErr := uuid.Parse(referenceId); parseErr == nil {
+		refund, err = s.refundRepo.GetById(ctx, id)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) && providerRefundId != "" {
+		refund, err = s.refundRepo.GetByProviderRefundId(ctx, providerRefundId)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Refund{}, http_error.NOT_FOUND_ERROR
+	}
+	return refund, err
+}
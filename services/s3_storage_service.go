@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+)
+
+const (
+	s3BootTimeout = 10 * time.Second
+
+	// s3MaxPresignExpiry is the longest validity Signature Version 4 accepts.
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// s3StorageService talks to any S3-compatible API, such as AWS S3 or MinIO, signing its
+// requests with AWS Signature Version 4.
+type s3StorageService struct {
+	httpClient *http.Client
+	endpoint   *url.URL
+	region     string
+	bucket     string
+	accessKey  string
+	secretKey  string
+	publicURL  string
+	pathStyle  bool
+}
+
+// NewS3StorageService checks the settings and that the bucket can be reached with the
+// credentials.
+func NewS3StorageService(storageConfig config.StorageConfig) (StorageService, error) {
+	if storageConfig.GetS3Endpoint() == "" || storageConfig.GetS3Bucket() == "" {
+		return nil, errors.New("s3 storage needs S3_ENDPOINT and S3_BUCKET")
+	}
+	if storageConfig.GetS3AccessKey() == "" || storageConfig.GetS3SecretKey() == "" {
+		return nil, errors.New("s3 storage needs S3_ACCESS_KEY and S3_SECRET_KEY")
+	}
+	endpoint, err := url.Parse(storageConfig.GetS3Endpoint())
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint %q is not an http(s) URL", storageConfig.GetS3Endpoint())
+	}
+
+	s := &s3StorageService{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		endpoint:   endpoint,
+		region:     storageConfig.GetS3Region(),
+		bucket:     storageConfig.GetS3Bucket(),
+		accessKey:  storageConfig.GetS3AccessKey(),
+		secretKey:  storageConfig.GetS3SecretKey(),
+		publicURL:  storageConfig.GetS3PublicURL(),
+		pathStyle:  storageConfig.IsS3PathStyle(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3BootTimeout)
+	defer cancel()
+	res, err := s.do(ctx, http.MethodHead, "", nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("s3 bucket %s is not reachable: %w", s.bucket, err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("s3 bucket %s answered %s", s.bucket, res.Status)
+	}
+	return s, nil
+}
+
+// UploadFile reads the file into memory first: S3 needs the length and the payload hash
+// before the body. Upload rules keep files small enough for that.
+func (s *s3StorageService) UploadFile(ctx context.Context, file io.Reader, destinationPath string, contentType string) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	res, err := s.do(ctx, http.MethodPut, destinationPath, data, contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", http_error.UPLOAD_FAILED, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", http_error.UPLOAD_FAILED, s3ErrorMessage(res))
+	}
+	if s.publicURL != "" {
+		return s.publicURL + "/" + s3EscapePath(destinationPath), nil
+	}
+	return s.objectURL(destinationPath).String(), nil
+}
+
+func (s *s3StorageService) DownloadFile(ctx context.Context, path string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, path, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: download %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", http_error.NOT_FOUND_ERROR, path)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: download %s: %s", http_error.INTERNAL_SERVER_ERROR, path, s3ErrorMessage(res))
+	}
+	return io.ReadAll(res.Body)
+}
+
+// OpenFile asks for the range from offset on. The body streams until the HTTP client
+// timeout, five minutes.
+func (s *s3StorageService) OpenFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(path).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: open %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	payloadHash := sha256.Sum256(nil)
+	s.sign(req, hex.EncodeToString(payloadHash[:]), time.Now())
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: open %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		return res.Body, nil
+	case http.StatusOK:
+		// The range was ignored; skip to offset here.
+		if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil && err != io.EOF {
+			res.Body.Close()
+			return nil, fmt.Errorf("%w: open %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+		}
+		return res.Body, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		res.Body.Close()
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	case http.StatusNotFound:
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", http_error.NOT_FOUND_ERROR, path)
+	}
+	defer res.Body.Close()
+	return nil, fmt.Errorf("%w: open %s: %s", http_error.INTERNAL_SERVER_ERROR, path, s3ErrorMessage(res))
+}
+
+func (s *s3StorageService) DeleteFile(ctx context.Context, path string) error {
+	res, err := s.do(ctx, http.MethodDelete, path, nil, "")
+	if err != nil {
+		return fmt.Errorf("%w: delete %s: %v", http_error.INTERNAL_SERVER_ERROR, path, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: delete %s: %s", http_error.INTERNAL_SERVER_ERROR, path, s3ErrorMessage(res))
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object in the query string, so it works without the
+// bucket being public. It is always on the endpoint, never on S3_PUBLIC_URL.
+func (s *s3StorageService) SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	return s.presign(http.MethodGet, path, expiresIn, time.Now(), nil), nil
+}
+
+// PresignUpload presigns a PUT of the object that also signs its Content-Length, so S3
+// refuses a body of any other size.
+func (s *s3StorageService) PresignUpload(ctx context.Context, path string, size int64, expiresIn time.Duration) (string, error) {
+	return s.presign(http.MethodPut, path, expiresIn, time.Now(), map[string]string{"content-length": strconv.FormatInt(size, 10)}), nil
+}
+
+// objectURL addresses a key in the bucket; an empty key addresses the bucket itself.
+func (s *s3StorageService) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	objectPath := "/" + s3EscapePath(key)
+	if s.pathStyle {
+		objectPath = "/" + s3EscapePath(s.bucket) + objectPath
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path, _ = url.PathUnescape(objectPath)
+	u.RawPath = objectPath
+	return &u
+}
+
+func (s *s3StorageService) do(ctx context.Context, method string, key string, body []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	payloadHash := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(payloadHash[:]), time.Now())
+	return s.httpClient.Do(req)
+}
+
+// sign adds the Signature Version 4 headers covering host, content type and the x-amz
+// headers.
+func (s *s3StorageService) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := amzDate[:8] + "/" + s.region + "/s3/aws4_request"
+	signature := s.signature(amzDate, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// presign signs a request in its query string with an unsigned payload, as browsers
+// follow such links without any extra header. headers, lowercase, are signed as well and
+// must then be sent with the request.
+func (s *s3StorageService) presign(method string, key string, expiresIn time.Duration, now time.Time, headers map[string]string) string {
+	if expiresIn > s3MaxPresignExpiry {
+		expiresIn = s3MaxPresignExpiry
+	}
+	if expiresIn < time.Second {
+		expiresIn = time.Second
+	}
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := amzDate[:8] + "/" + s.region + "/s3/aws4_request"
+	target := s.objectURL(key)
+
+	signed := map[string]string{"host": target.Host}
+	for name, value := range headers {
+		signed[name] = value
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiresIn.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	canonicalQuery := s3CanonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		target.EscapedPath(),
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	target.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(amzDate, scope, canonicalRequest)
+	return target.String()
+}
+
+func (s *s3StorageService) signature(amzDate string, scope string, canonicalRequest string) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), amzDate[:8])
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath percent-encodes a key the way Signature Version 4 expects, keeping the
+// slashes.
+func s3EscapePath(key string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(key) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/':
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+// s3CanonicalQuery sorts and encodes query parameters the way Signature Version 4 signs
+// them.
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, s3EscapeValue(name)+"="+s3EscapeValue(query.Get(name)))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3EscapeValue percent-encodes a query value, slashes included.
+func s3EscapeValue(value string) string {
+	return strings.ReplaceAll(s3EscapePath(value), "/", "%2F")
+}
+
+// s3ErrorMessage reads the error code S3 puts in the body of a failed request.
+func s3ErrorMessage(res *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	text := string(body)
+	if start := strings.Index(text, "<Code>"); start >= 0 {
+		if end := strings.Index(text[start:], "</Code>"); end >= 0 {
+			return res.Status + " " + text[start+len("<Code>"):start+end]
+		}
+	}
+	return res.Status
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	scanBatchSize = 20
+
+	// quarantinePrefix holds infected files. It sits under the private prefix, so the
+	// local backend never serves them without a signature, and none is ever signed.
+	quarantinePrefix = config.PrivateStoragePrefix + "quarantine/"
+)
+
+// ScanService runs uploaded files through the Scanner in a background worker. Files wait
+// as PENDING without a URL; clean ones become CLEAN and infected ones are quarantined.
+type ScanService interface {
+	UploadStatus() string
+	Notify()
+	Run(ctx context.Context)
+	ScanPending(ctx context.Context) (int, error)
+}
+
+type scanService struct {
+	transactor     repositories.Transactor
+	jobConfig      config.JobConfig
+	scanner        Scanner
+	storageService StorageService
+	blobService    BlobService
+	fileRepo       repositories.FileRepository
+	wake           chan struct{}
+	scanning       sync.Mutex
+}
+
+func NewScanService(transactor repositories.Transactor, jobConfig config.JobConfig, scanner Scanner, storageService StorageService, blobService BlobService, fileRepo repositories.FileRepository) ScanService {
+	return &scanService{
+		transactor:     transactor,
+		jobConfig:      jobConfig,
+		scanner:        scanner,
+		storageService: storageService,
+		blobService:    blobService,
+		fileRepo:       fileRepo,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// UploadStatus is the status a new upload starts with: PENDING, or CLEAN when no scanner
+// is configured, so uploads keep their URL right away.
+func (s *scanService) UploadStatus() string {
+	if _, disabled := s.scanner.(noopScanner); disabled {
+		return entity.ScanStatusClean
+	}
+	return entity.ScanStatusPending
+}
+
+// Notify wakes the worker after an upload. It never blocks.
+func (s *scanService) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the worker until ctx is done. It scans at once, catching files left pending
+// by a restart, then whenever Notify is called and every scan interval.
+func (s *scanService) Run(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.jobConfig.GetScanInterval())
+		defer ticker.Stop()
+		for {
+			if _, err := s.ScanPending(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("[JOB][SCAN] ❌ %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ScanPending scans every pending file and returns how many it settled. A scanner that
+// fails stops the run, leaving the rest pending for the next one.
+func (s *scanService) ScanPending(ctx context.Context) (int, error) {
+	s.scanning.Lock()
+	defer s.scanning.Unlock()
+
+	scanned := 0
+	after := uuid.Nil
+	for {
+		pending, err := s.fileRepo.ListByScanStatus(ctx, entity.ScanStatusPending, after, scanBatchSize)
+		if err != nil {
+			return scanned, err
+		}
+		for _, file := range pending {
+			settled, err := s.scan(ctx, file)
+			if err != nil {
+				return scanned, err
+			}
+			if settled {
+				scanned++
+			}
+			after = file.Id
+		}
+		if len(pending) < scanBatchSize {
+			return scanned, nil
+		}
+	}
+}
+
+// scan streams the file from storage to the scanner. It reports false for a file whose
+// content is missing; it stays pending.
+func (s *scanService) scan(ctx context.Context, file entity.File) (bool, error) {
+	content, err := s.storageService.OpenFile(ctx, file.StorageKey, 0)
+	if errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		log.Printf("[SCAN] file %s has no content at %s", file.Id, file.StorageKey)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	result, err := s.scanner.Scan(ctx, content)
+	content.Close()
+	if err != nil {
+		return false, err
+	}
+	if !result.Infected {
+		return true, s.fileRepo.MarkClean(ctx, file.Id)
+	}
+	return true, s.quarantine(ctx, file, result.Signature)
+}
+
+// quarantine copies an infected file through storage under quarantinePrefix, keyed by
+// the file as its content may be shared, then points the row at the copy and releases
+// the blobs of the original and its variants in one transaction. Their URLs were never
+// handed out, as the file was still pending. The originals are deleted once the
+// transaction commits; should it roll back, the copy is deleted instead and the file is
+// scanned again.
+func (s *scanService) quarantine(ctx context.Context, file entity.File, signature string) error {
+	quarantineKey := quarantinePrefix + file.Id.String() + path.Ext(file.StorageKey)
+	if err := s.copyObject(ctx, file.StorageKey, quarantineKey, file.MimeType); err != nil {
+		return err
+	}
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		s.transactor.AfterRollback(ctx, func(ctx context.Context) {
+			if err := s.storageService.DeleteFile(ctx, quarantineKey); err != nil {
+				log.Printf("[SCAN] ⚠️ quarantine copy %s left behind: %v", quarantineKey, err)
+			}
+		})
+		if err := s.fileRepo.Quarantine(ctx, file.Id, quarantineKey, signature); err != nil {
+			return err
+		}
+		for _, variant := range file.Variants {
+			if err := s.blobService.Release(ctx, variant.StorageKey); err != nil {
+				return err
+			}
+		}
+		return s.blobService.Release(ctx, file.StorageKey)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[SCAN] ☣️ file %s of account %s carries %s, quarantined at %s", file.Id, file.AccountId, signature, quarantineKey)
+	return nil
+}
+
+// copyObject streams the object under from into a new object under to.
+func (s *scanService) copyObject(ctx context.Context, from string, to string, contentType string) error {
+	src, err := s.storageService.OpenFile(ctx, from, 0)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = s.storageService.UploadFile(ctx, src, to, contentType)
+	return err
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"abdanhafidz.com/go-boilerplate/config"
+)
+
+// Scanner checks file contents for malware.
+type Scanner interface {
+	Scan(ctx context.Context, content io.Reader) (ScanResult, error)
+}
+
+// ScanResult is the verdict on one file. Signature names what an infected file carries.
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// NewScanner builds the scanner chosen by SCANNER_DRIVER. Like storage, clamd is checked
+// at boot so a scanner that cannot be reached is reported before files pile up pending.
+func NewScanner(scannerConfig config.ScannerConfig) (Scanner, error) {
+	if scannerConfig.GetDriver() == config.ScannerDriverClamd {
+		return NewClamdScanner(scannerConfig.GetClamdNetwork(), scannerConfig.GetClamdAddress(), scannerConfig.GetClamdTimeout())
+	}
+	return NewNoopScanner(), nil
+}
+
+// noopScanner finds every file clean, for setups without a scanner.
+type noopScanner struct{}
+
+func NewNoopScanner() Scanner {
+	return noopScanner{}
+}
+
+func (noopScanner) Scan(ctx context.Context, content io.Reader) (ScanResult, error) {
+	return ScanResult{}, nil
+}
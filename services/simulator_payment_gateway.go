@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"github.com/google/uuid"
+)
+
+// PaymentSimulator is the local gateway. Besides billing, it lets the hosted checkout
+// page settle an invoice, which fires the same callback Xendit would send.
+type PaymentSimulator interface {
+	PaymentGateway
+	Settle(ctx context.Context, invoiceId string, outcome string) (dto.GatewayInvoice, error)
+}
+
+type simulatorPaymentGateway struct {
+	paymentGatewayConfig config.PaymentGatewayConfig
+	httpClient           *http.Client
+	mu                   sync.Mutex
+	invoices             map[string]dto.GatewayInvoice
+	refunded             map[string]float64
+}
+
+func NewSimulatorPaymentGateway(paymentGatewayConfig config.PaymentGatewayConfig) PaymentSimulator {
+	return &simulatorPaymentGateway{
+		paymentGatewayConfig: paymentGatewayConfig,
+		httpClient:           &http.Client{Timeout: 10 * time.Second},
+		invoices:             make(map[string]dto.GatewayInvoice),
+		refunded:             make(map[string]float64),
+	}
+}
+
+func (g *simulatorPaymentGateway) Name() string {
+	return config.PaymentGatewaySimulator
+}
+
+// CreateInvoice keeps the invoice in memory and points it at the simulator checkout page.
+// With PAYMENT_SIMULATOR_OUTCOME set, the invoice settles on its own after the delay.
+func (g *simulatorPaymentGateway) CreateInvoice(ctx context.Context, req dto.GatewayInvoiceRequest) (dto.GatewayInvoice, error) {
+	id := "sim_" + uuid.NewString()
+	inv := dto.GatewayInvoice{
+		Id:         id,
+		ExternalId: req.ExternalId,
+		URL:        g.paymentGatewayConfig.GetPublicURL() + "/api/v1/payment/simulator/" + id,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Status:     entity.PaymentStatusPending,
+		ExpiresAt:  time.Now().Add(req.Duration),
+	}
+
+	g.mu.Lock()
+	g.invoices[id] = inv
+	g.mu.Unlock()
+
+	if outcome := g.paymentGatewayConfig.GetSimulatorOutcome(); outcome != "" {
+		time.AfterFunc(g.paymentGatewayConfig.GetSimulatorDelay(), func() {
+			if _, err := g.Settle(context.Background(), id, outcome); err != nil {
+				log.Printf("[PAYMENT][SIMULATOR] auto settle %s as %s failed: %v", id, outcome, err)
+			}
+		})
+	}
+	return inv, nil
+}
+
+func (g *simulatorPaymentGateway) GetInvoice(ctx context.Context, invoiceId string) (dto.GatewayInvoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inv, ok := g.invoices[invoiceId]
+	if !ok {
+		return dto.GatewayInvoice{}, http_error.NOT_FOUND_ERROR
+	}
+	if inv.Status == entity.PaymentStatusPending && time.Now().After(inv.ExpiresAt) {
+		inv.Status = entity.PaymentStatusExpired
+		g.invoices[invoiceId] = inv
+	}
+	return inv, nil
+}
+
+// Settle moves a pending invoice to PAID or EXPIRED and delivers the invoice callback.
+func (g *simulatorPaymentGateway) Settle(ctx context.Context, invoiceId string, outcome string) (dto.GatewayInvoice, error) {
+	if outcome != entity.PaymentStatusPaid && outcome != entity.PaymentStatusExpired {
+		return dto.GatewayInvoice{}, http_error.BAD_REQUEST_ERROR
+	}
+
+	g.mu.Lock()
+	inv, ok := g.invoices[invoiceId]
+	if !ok {
+		g.mu.Unlock()
+		return dto.GatewayInvoice{}, http_error.NOT_FOUND_ERROR
+	}
+	if inv.Status != entity.PaymentStatusPending {
+		g.mu.Unlock()
+		return inv, http_error.INVALID_PAYMENT_TRANSITION
+	}
+	inv.Status = outcome
+	g.invoices[invoiceId] = inv
+	g.mu.Unlock()
+
+	return inv, g.sendInvoiceCallback(ctx, inv)
+}
+
+// CreateRefund accepts refunds up to the paid amount and reports them as succeeded
+// through a refund callback after PAYMENT_SIMULATOR_DELAY.
+func (g *simulatorPaymentGateway) CreateRefund(ctx context.Context, req dto.GatewayRefundRequest) (dto.GatewayRefund, error) {
+	g.mu.Lock()
+	inv, ok := g.invoices[req.InvoiceId]
+	if !ok {
+		g.mu.Unlock()
+		return dto.GatewayRefund{}, http_error.NOT_FOUND_ERROR
+	}
+	if inv.Status != entity.PaymentStatusPaid || g.refunded[inv.Id]+req.Amount > inv.Amount {
+		g.mu.Unlock()
+		return dto.GatewayRefund{}, http_error.REFUND_AMOUNT_EXCEEDED
+	}
+	g.refunded[inv.Id] += req.Amount
+	g.mu.Unlock()
+
+	res := dto.GatewayRefund{
+		Id:          "sim_rfd_" + uuid.NewString(),
+		ReferenceId: req.ReferenceId,
+		Amount:      req.Amount,
+		Status:      entity.RefundStatusPending,
+	}
+	time.AfterFunc(g.paymentGatewayConfig.GetSimulatorDelay(), func() {
+		payload := dto.XenditRefundCallback{
+			Event:   "refund.succeeded",
+			Created: time.Now().Format(time.RFC3339),
+			Data: dto.XenditRefundCallbackData{
+				Id:          res.Id,
+				InvoiceId:   inv.Id,
+				ReferenceId: req.ReferenceId,
+				Amount:      req.Amount,
+				Currency:    req.Currency,
+				Status:      entity.RefundStatusSucceeded,
+				Reason:      req.Reason,
+			},
+		}
+		if err := g.sendCallback(context.Background(), payload); err != nil {
+			log.Printf("[PAYMENT][SIMULATOR] refund callback for %s failed: %v", res.Id, err)
+		}
+	})
+	return res, nil
+}
+
+func (g *simulatorPaymentGateway) sendInvoiceCallback(ctx context.Context, inv dto.GatewayInvoice) error {
+	payload := dto.XenditInvoiceCallback{
+		Id:             inv.Id,
+		ExternalId:     inv.ExternalId,
+		Status:         inv.Status,
+		Amount:         inv.Amount,
+		Currency:       inv.Currency,
+		PaymentMethod:  "SIMULATOR",
+		PaymentChannel: "SIMULATOR",
+	}
+	if inv.Status == entity.PaymentStatusPaid {
+		now := time.Now()
+		payload.PaidAmount = inv.Amount
+		payload.PaidAt = &now
+	}
+	return g.sendCallback(ctx, payload)
+}
+
+// sendCallback posts a callback signed with the configured callback token, so it passes
+// the same verification as a real Xendit delivery.
+func (g *simulatorPaymentGateway) sendCallback(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.paymentGatewayConfig.GetCallbackURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-callback-token", g.paymentGatewayConfig.GetCallbackToken())
+	req.Header.Set("webhook-id", "sim_evt_"+uuid.NewString())
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback to %s answered %d", g.paymentGatewayConfig.GetCallbackURL(), resp.StatusCode)
+	}
+	return nil
+}
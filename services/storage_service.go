@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+)
+
+// StorageService keeps file contents under a key. UploadFile returns the URL the file is
+// reachable at; File rows keep both. SignedURL links to a private file for a limited time.
+// OpenFile streams a file from offset to its end, for downloads through the API.
+type StorageService interface {
+	UploadFile(ctx context.Context, file io.Reader, destinationPath string, contentType string) (string, error)
+	DownloadFile(ctx context.Context, path string) ([]byte, error)
+	OpenFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, path string) error
+	SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error)
+}
+
+// DirectUploader is a backend clients can upload to themselves, through a URL that lets
+// them PUT one object of the given size.
+type DirectUploader interface {
+	PresignUpload(ctx context.Context, path string, size int64, expiresIn time.Duration) (string, error)
+}
+
+// NewStorageService builds the backend chosen by STORAGE_DRIVER. It checks the backend
+// can be used, so a misconfiguration is reported at boot instead of on the first upload.
+func NewStorageService(storageConfig config.StorageConfig, supabaseConfig config.SupabaseConfig) (StorageService, error) {
+	switch storageConfig.GetDriver() {
+	case config.StorageDriverS3:
+		return NewS3StorageService(storageConfig)
+	case config.StorageDriverMemory:
+		return NewMemoryStorageService(), nil
+	case config.StorageDriverSupabase:
+		return NewSupabaseStorageService(supabaseConfig.GetURL(), supabaseConfig.GetServiceKey(), supabaseConfig.GetBucketName())
+	default:
+		return NewLocalStorageService(storageConfig.GetLocalRoot(), storageConfig.GetPublicURL()+config.LocalStorageRoute, storageConfig.GetSigningSecret())
+	}
+}
+
+// objectOpener is the part of StorageService an objectReader needs.
+type objectOpener interface {
+	OpenFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error)
+}
+
+// objectReader reads a stored object of a known size from its position on, opening it at
+// the first Read after a Seek. http.ServeContent seeks to the end to learn the size, which
+// is answered without touching storage. ReadAt opens the object at the offset asked, so
+// content can be sniffed without reading all of it.
+type objectReader struct {
+	ctx     context.Context
+	storage objectOpener
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.storage.OpenFile(r.ctx, r.key, r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if errors.Is(err, io.EOF) && r.offset < r.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("objectReader.Seek: negative position")
+	}
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+func (r *objectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+func (r *objectReader) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	body, err := r.storage.OpenFile(r.ctx, r.key, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	want := min(int64(len(p)), r.size-offset)
+	n, err := io.ReadFull(body, p[:want])
+	if err == nil && want < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// renewalBatchSize is how many subscriptions one renewal run handles; the rest are picked
+// up by the next run.
+const renewalBatchSize = 200
+
+// SubscriptionService runs plan subscriptions: it starts them from a checkout, moves their
+// period forward when an invoice is PAID, bills renewals and ends them. The subscription's
+// entitlement follows its status. It is registered as a PaymentListener.
+type SubscriptionService interface {
+	PaymentListener
+	Start(ctx context.Context, accountId uuid.UUID, price entity.Price, payment entity.Payment) (entity.Payment, error)
+	List(ctx context.Context, accountId uuid.UUID) ([]entity.Subscription, error)
+	Get(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error)
+	Cancel(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error)
+	Resume(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error)
+	AdminList(ctx context.Context, pagination entity.Pagination) ([]entity.Subscription, error)
+	RunRenewals(ctx context.Context) (dto.SubscriptionRenewalReport, error)
+}
+
+type subscriptionService struct {
+	transactor         repositories.Transactor
+	subscriptionConfig config.SubscriptionConfig
+	paymentService     PaymentService
+	subscriptionRepo   repositories.SubscriptionRepository
+	entitlementRepo    repositories.EntitlementRepository
+	productRepo        repositories.ProductRepository
+	paymentRepo        repositories.PaymentRepository
+	running            sync.Mutex
+}
+
+func NewSubscriptionService(transactor repositories.Transactor, subscriptionConfig config.SubscriptionConfig, paymentService PaymentService, subscriptionRepo repositories.SubscriptionRepository, entitlementRepo repositories.EntitlementRepository, productRepo repositories.ProductRepository, paymentRepo repositories.PaymentRepository) SubscriptionService {
+	return &subscriptionService{
+		transactor:         transactor,
+		subscriptionConfig: subscriptionConfig,
+		paymentService:     paymentService,
+		subscriptionRepo:   subscriptionRepo,
+		entitlementRepo:    entitlementRepo,
+		productRepo:        productRepo,
+		paymentRepo:        paymentRepo,
+	}
+}
+
+// Start opens a subscription to a plan price and issues its first invoice from the payment
+// prepared by the checkout. An INCOMPLETE subscription to the product, or a PAST_DUE one
+// on the same price, is billed again instead so the account can pay what it owes.
+func (s *subscriptionService) Start(ctx context.Context, accountId uuid.UUID, price entity.Price, payment entity.Payment) (entity.Payment, error) {
+	subscription, err := s.subscriptionRepo.GetOpen(ctx, accountId, price.ProductId)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		subscription, err = s.subscriptionRepo.Create(ctx, entity.Subscription{
+			AccountId: accountId,
+			ProductId: price.ProductId,
+			PriceId:   price.Id,
+			Status:    entity.SubscriptionStatusIncomplete,
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return entity.Payment{}, http_error.SUBSCRIPTION_EXISTS
+		} else if err != nil {
+			return entity.Payment{}, err
+		}
+	case err != nil:
+		return entity.Payment{}, err
+	case subscription.Status == entity.SubscriptionStatusIncomplete:
+		if subscription.PriceId != price.Id {
+			_, err := s.subscriptionRepo.UpdateWhere(ctx, subscription.Id,
+				map[string]interface{}{"status": entity.SubscriptionStatusIncomplete},
+				map[string]interface{}{"price_id": price.Id})
+			if err != nil {
+				return entity.Payment{}, err
+			}
+		}
+	case subscription.Status == entity.SubscriptionStatusPastDue && subscription.PriceId == price.Id:
+	default:
+		return entity.Payment{}, http_error.SUBSCRIPTION_EXISTS
+	}
+
+	payment.SubscriptionId = &subscription.Id
+	charged, err := s.paymentService.Charge(ctx, accountId, payment)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+	// A fully discounted invoice is PAID within Charge and has already moved the
+	// subscription on, in which case the status no longer matches and nothing changes.
+	_, err = s.subscriptionRepo.UpdateWhere(ctx, subscription.Id,
+		map[string]interface{}{"status": subscription.Status},
+		map[string]interface{}{"latest_payment_id": charged.Id})
+	return charged, err
+}
+
+// OnPaymentPaid starts the first period of a subscription, or the next one for a renewal,
+// and extends its entitlement accordingly.
+func (s *subscriptionService) OnPaymentPaid(ctx context.Context, payment entity.Payment) error {
+	if payment.SubscriptionId == nil {
+		return nil
+	}
+	subscription, err := s.subscriptionRepo.LockById(ctx, *payment.SubscriptionId)
+	if err != nil {
+		return err
+	}
+	price, err := s.productRepo.GetPriceById(ctx, subscription.PriceId)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if payment.PaidAt != nil {
+		start = *payment.PaidAt
+	}
+	switch subscription.Status {
+	case entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue:
+		if subscription.CurrentPeriodEnd != nil {
+			start = *subscription.CurrentPeriodEnd
+		}
+	case entity.SubscriptionStatusCanceled:
+		// A late payment revives the subscription unless the account has opened another one.
+		if _, err := s.subscriptionRepo.GetOpen(ctx, subscription.AccountId, subscription.ProductId); err == nil {
+			log.Printf("[SUBSCRIPTION] %s paid after cancellation while another subscription is open; refund it manually", payment.ExternalId)
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		subscription.CancelAtPeriodEnd = false
+		subscription.CanceledAt = nil
+	}
+	end := start.AddDate(0, price.IntervalMonths, 0)
+
+	subscription.Status = entity.SubscriptionStatusActive
+	subscription.CurrentPeriodStart = &start
+	subscription.CurrentPeriodEnd = &end
+	subscription.RenewalAttempts = 0
+	subscription.LatestPaymentId = &payment.Id
+	nextRenewalAt := s.renewalTime(subscription, time.Now())
+	subscription.NextRenewalAt = &nextRenewalAt
+	if _, err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return err
+	}
+
+	expiresAt := s.accessEnd(subscription)
+	log.Printf("[SUBSCRIPTION] %s active until %s by %s", subscription.Id, end.Format(time.RFC3339), payment.ExternalId)
+	return s.entitlementRepo.SaveForSubscription(ctx, entity.Entitlement{
+		AccountId:      subscription.AccountId,
+		ProductId:      subscription.ProductId,
+		SubscriptionId: &subscription.Id,
+		Source:         entity.EntitlementSourceSubscription,
+		Status:         entity.EntitlementStatusActive,
+		StartsAt:       start,
+		ExpiresAt:      &expiresAt,
+	})
+}
+
+// OnPaymentRefunded ends a subscription at once when the invoice of its current period is
+// refunded in full.
+func (s *subscriptionService) OnPaymentRefunded(ctx context.Context, payment entity.Payment, refund entity.Refund) error {
+	if payment.SubscriptionId == nil || payment.Status != entity.PaymentStatusRefunded {
+		return nil
+	}
+	subscription, err := s.subscriptionRepo.LockById(ctx, *payment.SubscriptionId)
+	if err != nil {
+		return err
+	}
+	if subscription.LatestPaymentId == nil || *subscription.LatestPaymentId != payment.Id ||
+		subscription.Status == entity.SubscriptionStatusCanceled {
+		return nil
+	}
+	return s.cancelNow(ctx, subscription, time.Now())
+}
+
+func (s *subscriptionService) List(ctx context.Context, accountId uuid.UUID) ([]entity.Subscription, error) {
+	return s.subscriptionRepo.ListByAccountId(ctx, accountId)
+}
+
+func (s *subscriptionService) Get(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error) {
+	subscription, err := s.subscriptionRepo.GetById(ctx, subscriptionId)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && subscription.AccountId != accountId) {
+		return entity.Subscription{}, http_error.NOT_FOUND_ERROR
+	}
+	return subscription, err
+}
+
+// Cancel stops a running subscription at the end of its period; access lasts until then
+// without grace. A subscription that never started is cancelled right away.
+func (s *subscriptionService) Cancel(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error) {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription, err := s.lockOwned(ctx, accountId, subscriptionId)
+		if err != nil {
+			return err
+		}
+		switch {
+		case subscription.Status == entity.SubscriptionStatusIncomplete:
+			return s.cancelNow(ctx, subscription, time.Now())
+		case subscription.CancelAtPeriodEnd || subscription.Status == entity.SubscriptionStatusCanceled:
+			return http_error.INVALID_SUBSCRIPTION_STATE
+		}
+
+		subscription.CancelAtPeriodEnd = true
+		subscription.NextRenewalAt = subscription.CurrentPeriodEnd
+		if _, err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+			return err
+		}
+		return s.entitlementRepo.SetSubscriptionExpiry(ctx, subscription.Id, s.accessEnd(subscription))
+	})
+	if err != nil {
+		return entity.Subscription{}, err
+	}
+	return s.Get(ctx, accountId, subscriptionId)
+}
+
+// Resume takes back a cancellation requested for the end of the period.
+func (s *subscriptionService) Resume(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error) {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription, err := s.lockOwned(ctx, accountId, subscriptionId)
+		if err != nil {
+			return err
+		}
+		if !subscription.CancelAtPeriodEnd || subscription.Status == entity.SubscriptionStatusCanceled {
+			return http_error.INVALID_SUBSCRIPTION_STATE
+		}
+
+		subscription.CancelAtPeriodEnd = false
+		nextRenewalAt := s.renewalTime(subscription, time.Now())
+		subscription.NextRenewalAt = &nextRenewalAt
+		if _, err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+			return err
+		}
+		return s.entitlementRepo.SetSubscriptionExpiry(ctx, subscription.Id, s.accessEnd(subscription))
+	})
+	if err != nil {
+		return entity.Subscription{}, err
+	}
+	return s.Get(ctx, accountId, subscriptionId)
+}
+
+func (s *subscriptionService) AdminList(ctx context.Context, pagination entity.Pagination) ([]entity.Subscription, error) {
+	return s.subscriptionRepo.List(ctx, pagination)
+}
+
+// RunRenewals issues renewal invoices that are due, retries unpaid ones up to
+// SUBSCRIPTION_MAX_ATTEMPTS, marks subscriptions PAST_DUE once their period ended unpaid
+// and cancels them after the grace period or at the end of a period they were cancelled
+// for. Subscriptions whose first invoice failed or expired are cancelled as well.
+func (s *subscriptionService) RunRenewals(ctx context.Context) (dto.SubscriptionRenewalReport, error) {
+	var report dto.SubscriptionRenewalReport
+	if !s.running.TryLock() {
+		return report, http_error.RENEWAL_RUNNING
+	}
+	defer s.running.Unlock()
+
+	now := time.Now()
+	abandoned, err := s.subscriptionRepo.ListAbandoned(ctx, renewalBatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, subscription := range abandoned {
+		ended, err := s.subscriptionRepo.UpdateWhere(ctx, subscription.Id,
+			map[string]interface{}{"status": entity.SubscriptionStatusIncomplete},
+			map[string]interface{}{"status": entity.SubscriptionStatusCanceled, "canceled_at": now})
+		if err != nil {
+			log.Printf("[SUBSCRIPTION] cancel abandoned %s failed: %v", subscription.Id, err)
+			report.Failed++
+		} else if ended {
+			report.Abandoned++
+		}
+	}
+
+	due, err := s.subscriptionRepo.ListDue(ctx, now, renewalBatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, subscription := range due {
+		if err := s.renew(ctx, subscription, now, &report); err != nil {
+			log.Printf("[SUBSCRIPTION] renew %s failed: %v", subscription.Id, err)
+			report.Failed++
+		}
+	}
+
+	log.Printf("[SUBSCRIPTION] renewal run: %d invoiced, %d past due, %d canceled, %d abandoned, %d failed",
+		report.Invoiced, report.PastDue, report.Canceled, report.Abandoned, report.Failed)
+	return report, nil
+}
+
+// renew moves one due subscription along. Every change is conditional on the state it was
+// read in, so a payment settling meanwhile always wins.
+func (s *subscriptionService) renew(ctx context.Context, subscription entity.Subscription, now time.Time, report *dto.SubscriptionRenewalReport) error {
+	if subscription.CurrentPeriodEnd == nil || subscription.NextRenewalAt == nil || subscription.Price == nil {
+		return nil
+	}
+	end := *subscription.CurrentPeriodEnd
+	graceEnd := end.Add(s.subscriptionConfig.GetGracePeriod())
+	guard := map[string]interface{}{
+		"status":             subscription.Status,
+		"current_period_end": end,
+		"next_renewal_at":    *subscription.NextRenewalAt,
+	}
+
+	if (subscription.CancelAtPeriodEnd && !now.Before(end)) || !now.Before(graceEnd) {
+		var ended bool
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			ended, err = s.subscriptionRepo.UpdateWhere(ctx, subscription.Id, guard,
+				map[string]interface{}{"status": entity.SubscriptionStatusCanceled, "canceled_at": now})
+			if err != nil || !ended {
+				return err
+			}
+			_, err = s.entitlementRepo.RevokeBySubscriptionId(ctx, subscription.Id, now)
+			return err
+		})
+		if err == nil && ended {
+			report.Canceled++
+		}
+		return err
+	}
+	if subscription.CancelAtPeriodEnd {
+		return nil
+	}
+
+	if subscription.Status == entity.SubscriptionStatusActive && !now.Before(end) {
+		updated, err := s.subscriptionRepo.UpdateWhere(ctx, subscription.Id, guard,
+			map[string]interface{}{"status": entity.SubscriptionStatusPastDue})
+		if err != nil || !updated {
+			return err
+		}
+		guard["status"] = entity.SubscriptionStatusPastDue
+		report.PastDue++
+	}
+
+	nextRenewalAt := now.Add(s.subscriptionConfig.GetRetryInterval())
+	if nextRenewalAt.After(graceEnd) {
+		nextRenewalAt = graceEnd
+	}
+
+	// The invoice already issued for the coming period may still be paid; check again later.
+	if subscription.LatestPaymentId != nil {
+		latest, err := s.paymentRepo.GetById(ctx, *subscription.LatestPaymentId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && latest.Status == entity.PaymentStatusPending && (latest.ExpiresAt == nil || latest.ExpiresAt.After(now)) {
+			_, err := s.subscriptionRepo.UpdateWhere(ctx, subscription.Id, guard,
+				map[string]interface{}{"next_renewal_at": nextRenewalAt})
+			return err
+		}
+	}
+
+	// Out of attempts: nothing happens until the grace period ends.
+	if subscription.RenewalAttempts >= s.subscriptionConfig.GetMaxAttempts() {
+		_, err := s.subscriptionRepo.UpdateWhere(ctx, subscription.Id, guard,
+			map[string]interface{}{"next_renewal_at": graceEnd})
+		return err
+	}
+
+	claimed, err := s.subscriptionRepo.UpdateWhere(ctx, subscription.Id, guard, map[string]interface{}{
+		"renewal_attempts": subscription.RenewalAttempts + 1,
+		"next_renewal_at":  nextRenewalAt,
+	})
+	if err != nil || !claimed {
+		return err
+	}
+	payment, err := s.paymentService.Charge(ctx, subscription.AccountId, entity.Payment{
+		Amount:         subscription.Price.Amount,
+		Currency:       subscription.Price.Currency,
+		Description:    renewalDescription(subscription, end),
+		ProductId:      &subscription.ProductId,
+		PriceId:        &subscription.PriceId,
+		SubscriptionId: &subscription.Id,
+	})
+	if err != nil {
+		return err
+	}
+	report.Invoiced++
+	_, err = s.subscriptionRepo.UpdateWhere(ctx, subscription.Id,
+		map[string]interface{}{"current_period_end": end},
+		map[string]interface{}{"latest_payment_id": payment.Id})
+	return err
+}
+
+func (s *subscriptionService) lockOwned(ctx context.Context, accountId uuid.UUID, subscriptionId uuid.UUID) (entity.Subscription, error) {
+	subscription, err := s.subscriptionRepo.LockById(ctx, subscriptionId)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && subscription.AccountId != accountId) {
+		return entity.Subscription{}, http_error.NOT_FOUND_ERROR
+	}
+	return subscription, err
+}
+
+func (s *subscriptionService) cancelNow(ctx context.Context, subscription entity.Subscription, at time.Time) error {
+	subscription.Status = entity.SubscriptionStatusCanceled
+	subscription.CanceledAt = &at
+	if _, err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return err
+	}
+	_, err := s.entitlementRepo.RevokeBySubscriptionId(ctx, subscription.Id, at)
+	return err
+}
+
+// renewalTime is when the renewal of the current period is first invoiced.
+func (s *subscriptionService) renewalTime(subscription entity.Subscription, now time.Time) time.Time {
+	if subscription.CurrentPeriodEnd == nil {
+		return now
+	}
+	if subscription.CancelAtPeriodEnd {
+		return *subscription.CurrentPeriodEnd
+	}
+	at := subscription.CurrentPeriodEnd.Add(-s.subscriptionConfig.GetRenewalLead())
+	if at.Before(now) {
+		return now
+	}
+	return at
+}
+
+// accessEnd is when the subscription's entitlement runs out if nothing more is paid.
+func (s *subscriptionService) accessEnd(subscription entity.Subscription) time.Time {
+	if subscription.CurrentPeriodEnd == nil {
+		return time.Now()
+	}
+	if subscription.CancelAtPeriodEnd {
+		return *subscription.CurrentPeriodEnd
+	}
+	return subscription.CurrentPeriodEnd.Add(s.subscriptionConfig.GetGracePeriod())
+}
+
+func renewalDescription(subscription entity.Subscription, periodStart time.Time) string {
+	name := "Subscription"
+	if subscription.Product != nil {
+		name = subscription.Product.Name
+	}
+	return name + " from " + periodStart.Format("2006-01-02")
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const tusCleanupBatchSize = 100
+
+// TusService keeps the state of tus 1.0 resumable uploads. Chunks are appended to a
+// staging file on local disk; once every byte has arrived the file goes through
+// UploadService like any other upload of its context.
+type TusService interface {
+	Create(ctx context.Context, accountId uuid.UUID, length int64, metadata string) (entity.TusUpload, error)
+	Get(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID) (entity.TusUpload, error)
+	Append(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID, offset int64, chunk io.Reader) (entity.TusUpload, error)
+	Terminate(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID) error
+	CleanupExpired(ctx context.Context) (int, error)
+}
+
+type tusService struct {
+	tusConfig     config.TusConfig
+	ruleService   UploadRuleService
+	uploadService UploadService
+	quotaService  QuotaService
+	tusRepo       repositories.TusUploadRepository
+	// locks serialises the requests of one upload; staging is local to this instance.
+	locks sync.Map
+}
+
+func NewTusService(tusConfig config.TusConfig, ruleService UploadRuleService, uploadService UploadService, quotaService QuotaService, tusRepo repositories.TusUploadRepository) TusService {
+	return &tusService{
+		tusConfig:     tusConfig,
+		ruleService:   ruleService,
+		uploadService: uploadService,
+		quotaService:  quotaService,
+		tusRepo:       tusRepo,
+	}
+}
+
+// Create checks the announced file against the rule of its context and the quota of the
+// account before accepting any byte. The metadata must carry filename and may carry
+// context; without it, the context is inferred from the filename like a multipart upload.
+func (s *tusService) Create(ctx context.Context, accountId uuid.UUID, length int64, metadata string) (entity.TusUpload, error) {
+	values, err := parseTusMetadata(metadata)
+	if err != nil {
+		return entity.TusUpload{}, err
+	}
+	filename := filepath.Base(strings.TrimSpace(values["filename"]))
+	if filename == "" || filename == "." || filename == "/" {
+		return entity.TusUpload{}, fmt.Errorf("%w: filename metadata is required", http_error.BAD_REQUEST_ERROR)
+	}
+	rule, err := s.ruleService.ForAccount(ctx, accountId, values["context"], filename)
+	if err != nil {
+		return entity.TusUpload{}, err
+	}
+	if length <= 0 {
+		return entity.TusUpload{}, fmt.Errorf("%w: upload length must be positive", http_error.BAD_REQUEST_ERROR)
+	}
+	if length > rule.MaxBytes {
+		return entity.TusUpload{}, http_error.FILE_TOO_LARGE
+	}
+	if !ruleAllowsExt(rule, strings.ToLower(filepath.Ext(filename))) {
+		return entity.TusUpload{}, http_error.INVALID_FILE_TYPE
+	}
+	if err := s.quotaService.Check(ctx, accountId, length, 1); err != nil {
+		return entity.TusUpload{}, err
+	}
+
+	upload, err := s.tusRepo.Create(ctx, entity.TusUpload{
+		Id:        uuid.New(),
+		AccountId: accountId,
+		Context:   rule.Context,
+		Filename:  filename,
+		Length:    length,
+		Metadata:  metadata,
+		ExpiresAt: time.Now().Add(s.tusConfig.GetExpiration()),
+	})
+	if err != nil {
+		return entity.TusUpload{}, err
+	}
+	staging, err := os.OpenFile(s.stagingPath(upload.Id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		s.tusRepo.Delete(ctx, upload.Id)
+		return entity.TusUpload{}, fmt.Errorf("%w: staging upload: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+	staging.Close()
+	return upload, nil
+}
+
+func (s *tusService) Get(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID) (entity.TusUpload, error) {
+	upload, err := s.tusRepo.GetById(ctx, uploadId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.TusUpload{}, http_error.NOT_FOUND_ERROR
+	}
+	if err != nil {
+		return entity.TusUpload{}, err
+	}
+	if upload.AccountId != accountId {
+		return entity.TusUpload{}, http_error.NOT_FOUND_ERROR
+	}
+	if upload.FileId == nil && time.Now().After(upload.ExpiresAt) {
+		return entity.TusUpload{}, http_error.UPLOAD_EXPIRED
+	}
+	return upload, nil
+}
+
+// Append writes a chunk at offset, which must be where the previous chunk ended. Bytes
+// that arrived before the connection dropped are kept, so the client resumes after them.
+// The chunk that completes the upload also stores the file; should that fail for a
+// storage reason, an empty PATCH at the final offset retries it.
+func (s *tusService) Append(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID, offset int64, chunk io.Reader) (entity.TusUpload, error) {
+	unlock := s.lock(uploadId)
+	defer unlock()
+
+	upload, err := s.Get(ctx, accountId, uploadId)
+	if err != nil {
+		return entity.TusUpload{}, err
+	}
+	if offset != upload.Offset {
+		return upload, http_error.UPLOAD_OFFSET_MISMATCH
+	}
+	if upload.FileId != nil {
+		return upload, nil
+	}
+
+	written, writeErr := s.write(upload, chunk)
+	if written > 0 {
+		advanced, err := s.tusRepo.AdvanceOffset(ctx, upload.Id, upload.Offset, upload.Offset+written)
+		if err != nil {
+			return upload, err
+		}
+		if !advanced {
+			return upload, http_error.UPLOAD_OFFSET_MISMATCH
+		}
+		upload.Offset += written
+	}
+	if writeErr != nil {
+		return upload, writeErr
+	}
+	if upload.Offset < upload.Length {
+		return upload, nil
+	}
+	return s.complete(ctx, upload)
+}
+
+// Terminate drops an upload and its staged bytes.
+func (s *tusService) Terminate(ctx context.Context, accountId uuid.UUID, uploadId uuid.UUID) error {
+	unlock := s.lock(uploadId)
+	defer unlock()
+
+	upload, err := s.tusRepo.GetById(ctx, uploadId)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && upload.AccountId != accountId) {
+		return http_error.NOT_FOUND_ERROR
+	}
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, upload.Id)
+}
+
+// CleanupExpired removes uploads past their expiry together with their staged bytes and
+// returns how many it removed. Files of completed uploads are kept.
+func (s *tusService) CleanupExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		expired, err := s.tusRepo.ListExpired(ctx, time.Now(), tusCleanupBatchSize)
+		if err != nil {
+			return removed, err
+		}
+		for _, upload := range expired {
+			if err := s.remove(ctx, upload.Id); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(expired) < tusCleanupBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// write appends the chunk to the staging file, refusing bytes beyond the announced
+// length. Anything left past the offset by an earlier interrupted write is cut first.
+func (s *tusService) write(upload entity.TusUpload, chunk io.Reader) (int64, error) {
+	staging, err := os.OpenFile(s.stagingPath(upload.Id), os.O_WRONLY, 0o600)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("%w: staged bytes of upload %s are gone", http_error.UPLOAD_EXPIRED, upload.Id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+	defer staging.Close()
+	if err := staging.Truncate(upload.Offset); err != nil {
+		return 0, fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+	if _, err := staging.Seek(upload.Offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+
+	remaining := upload.Length - upload.Offset
+	written, copyErr := io.Copy(staging, io.LimitReader(chunk, remaining+1))
+	if written > remaining {
+		staging.Truncate(upload.Offset)
+		return 0, http_error.FILE_TOO_LARGE
+	}
+	if err := staging.Sync(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return written, fmt.Errorf("%w: chunk interrupted: %v", http_error.BAD_REQUEST_ERROR, copyErr)
+	}
+	return written, nil
+}
+
+// complete stores the finished upload. A file the rule refuses ends the upload; a
+// storage failure keeps it for a retry.
+func (s *tusService) complete(ctx context.Context, upload entity.TusUpload) (entity.TusUpload, error) {
+	file, err := s.uploadService.UploadStagedFile(ctx, s.stagingPath(upload.Id), upload.Filename, upload.Context, upload.AccountId)
+	if err != nil {
+		if !errors.Is(err, http_error.UPLOAD_FAILED) && !errors.Is(err, http_error.INTERNAL_SERVER_ERROR) {
+			if removeErr := s.remove(ctx, upload.Id); removeErr != nil {
+				log.Printf("[TUS] removing refused upload %s failed: %v", upload.Id, removeErr)
+			}
+		}
+		return upload, err
+	}
+	if err := s.tusRepo.SetFile(ctx, upload.Id, file.Id); err != nil {
+		return upload, err
+	}
+	upload.FileId = &file.Id
+	s.locks.Delete(upload.Id)
+	if err := os.Remove(s.stagingPath(upload.Id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("[TUS] removing staged upload %s failed: %v", upload.Id, err)
+	}
+	return upload, nil
+}
+
+func (s *tusService) remove(ctx context.Context, uploadId uuid.UUID) error {
+	if err := os.Remove(s.stagingPath(uploadId)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %v", http_error.INTERNAL_SERVER_ERROR, err)
+	}
+	s.locks.Delete(uploadId)
+	return s.tusRepo.Delete(ctx, uploadId)
+}
+
+func (s *tusService) stagingPath(uploadId uuid.UUID) string {
+	return filepath.Join(s.tusConfig.GetStagingDir(), uploadId.String())
+}
+
+func (s *tusService) lock(uploadId uuid.UUID) func() {
+	value, _ := s.locks.LoadOrStore(uploadId, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// parseTusMetadata decodes an Upload-Metadata header: comma-separated pairs of a key and
+// an optional base64 value.
+func parseTusMetadata(header string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("%w: metadata %s is not base64", http_error.BAD_REQUEST_ERROR, key)
+		}
+		values[key] = string(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeTusConfig struct {
+	config.TusConfig
+	stagingDir string
+}
+
+func (c fakeTusConfig) GetStagingDir() string        { return c.stagingDir }
+func (c fakeTusConfig) GetExpiration() time.Duration { return time.Hour }
+
+// fakeTusRules accepts .txt files of up to a kilobyte in any context.
+type fakeTusRules struct {
+	UploadRuleService
+}
+
+func (fakeTusRules) ForAccount(ctx context.Context, accountId uuid.UUID, uploadContext string, filename string) (entity.UploadRule, error) {
+	return entity.UploadRule{Context: "docs", MaxBytes: 1024, AllowedExts: []string{".txt"}}, nil
+}
+
+type fakeTusQuota struct {
+	QuotaService
+}
+
+func (fakeTusQuota) Check(ctx context.Context, accountId uuid.UUID, bytes int64, files int64) error {
+	return nil
+}
+
+// fakeTusUploads stores staged files in the memory storage, failing the first failures
+// attempts as a storage outage would.
+type fakeTusUploads struct {
+	UploadService
+	storage  StorageService
+	failures int
+}
+
+func (u *fakeTusUploads) UploadStagedFile(ctx context.Context, stagedPath string, originalName string, uploadContext string, accountID uuid.UUID) (*entity.File, error) {
+	if u.failures > 0 {
+		u.failures--
+		return nil, http_error.UPLOAD_FAILED
+	}
+	staged, err := os.Open(stagedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer staged.Close()
+	if _, err := u.storage.UploadFile(ctx, staged, uploadContext+"/"+originalName, "text/plain"); err != nil {
+		return nil, err
+	}
+	return &entity.File{Id: uuid.New()}, nil
+}
+
+// fakeTusRepo keeps uploads in memory. Methods the tests do not need are left to the
+// embedded interface and panic when called.
+type fakeTusRepo struct {
+	repositories.TusUploadRepository
+	mu      sync.Mutex
+	uploads map[uuid.UUID]entity.TusUpload
+}
+
+func (r *fakeTusRepo) Create(ctx context.Context, upload entity.TusUpload) (entity.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.uploads[upload.Id] = upload
+	return upload, nil
+}
+
+func (r *fakeTusRepo) GetById(ctx context.Context, id uuid.UUID) (entity.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload, ok := r.uploads[id]
+	if !ok {
+		return entity.TusUpload{}, gorm.ErrRecordNotFound
+	}
+	return upload, nil
+}
+
+func (r *fakeTusRepo) AdvanceOffset(ctx context.Context, id uuid.UUID, from int64, to int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload, ok := r.uploads[id]
+	if !ok || upload.Offset != from {
+		return false, nil
+	}
+	upload.Offset = to
+	r.uploads[id] = upload
+	return true, nil
+}
+
+func (r *fakeTusRepo) SetFile(ctx context.Context, id uuid.UUID, fileId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload := r.uploads[id]
+	upload.FileId = &fileId
+	r.uploads[id] = upload
+	return nil
+}
+
+func (r *fakeTusRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.uploads, id)
+	return nil
+}
+
+func (r *fakeTusRepo) ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []entity.TusUpload
+	for _, upload := range r.uploads {
+		if !upload.ExpiresAt.After(now) {
+			expired = append(expired, upload)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(expired[j].ExpiresAt) })
+	return expired[:min(len(expired), limit)], nil
+}
+
+// brokenChunk sends its bytes, then fails like a dropped connection.
+type brokenChunk struct {
+	data io.Reader
+}
+
+func (c brokenChunk) Read(p []byte) (int, error) {
+	n, err := c.data.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+type tusTest struct {
+	service *tusService
+	repo    *fakeTusRepo
+	uploads *fakeTusUploads
+	storage StorageService
+	account uuid.UUID
+}
+
+func newTusTest(t *testing.T) *tusTest {
+	storage := NewMemoryStorageService()
+	repo := &fakeTusRepo{uploads: map[uuid.UUID]entity.TusUpload{}}
+	uploads := &fakeTusUploads{storage: storage}
+	service := NewTusService(fakeTusConfig{stagingDir: t.TempDir()}, fakeTusRules{}, uploads, fakeTusQuota{}, repo).(*tusService)
+	return &tusTest{service: service, repo: repo, uploads: uploads, storage: storage, account: uuid.New()}
+}
+
+func (tt *tusTest) create(t *testing.T, length int64) entity.TusUpload {
+	t.Helper()
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("notes.txt"))
+	upload, err := tt.service.Create(context.Background(), tt.account, length, metadata)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return upload
+}
+
+func (tt *tusTest) stagedSize(t *testing.T, uploadId uuid.UUID) int64 {
+	t.Helper()
+	info, err := os.Stat(tt.service.stagingPath(uploadId))
+	if err != nil {
+		t.Fatalf("stat staged bytes: %v", err)
+	}
+	return info.Size()
+}
+
+func TestTusAppendCompletes(t *testing.T) {
+	tt := newTusTest(t)
+	ctx := context.Background()
+	upload := tt.create(t, 10)
+
+	upload, err := tt.service.Append(ctx, tt.account, upload.Id, 0, strings.NewReader("hello"))
+	if err != nil || upload.Offset != 5 || upload.FileId != nil {
+		t.Fatalf("first chunk: offset %d, file %v, err %v; want offset 5 and no file", upload.Offset, upload.FileId, err)
+	}
+	upload, err = tt.service.Append(ctx, tt.account, upload.Id, 5, strings.NewReader("world"))
+	if err != nil || upload.FileId == nil {
+		t.Fatalf("last chunk: file %v, err %v; want the stored file", upload.FileId, err)
+	}
+	if data, _ := tt.storage.DownloadFile(ctx, "docs/notes.txt"); string(data) != "helloworld" {
+		t.Errorf("stored %q, want helloworld", data)
+	}
+	if _, err := os.Stat(tt.service.stagingPath(upload.Id)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("staged bytes kept after completion: %v", err)
+	}
+}
+
+func TestTusAppendOffsetMismatch(t *testing.T) {
+	tt := newTusTest(t)
+	ctx := context.Background()
+	upload := tt.create(t, 10)
+	tt.service.Append(ctx, tt.account, upload.Id, 0, strings.NewReader("abc"))
+
+	for _, offset := range []int64{0, 2, 4} {
+		if _, err := tt.service.Append(ctx, tt.account, upload.Id, offset, strings.NewReader("x")); !errors.Is(err, http_error.UPLOAD_OFFSET_MISMATCH) {
+			t.Errorf("offset %d: err = %v, want UPLOAD_OFFSET_MISMATCH", offset, err)
+		}
+	}
+	if size := tt.stagedSize(t, upload.Id); size != 3 {
+		t.Errorf("staged %d bytes, want 3", size)
+	}
+}
+
+func TestTusAppendRefusesBytesPastLength(t *testing.T) {
+	tt := newTusTest(t)
+	ctx := context.Background()
+	upload := tt.create(t, 10)
+	tt.service.Append(ctx, tt.account, upload.Id, 0, strings.NewReader("abcd"))
+
+	upload, err := tt.service.Append(ctx, tt.account, upload.Id, 4, strings.NewReader("efghijk"))
+	if !errors.Is(err, http_error.FILE_TOO_LARGE) {
+		t.Fatalf("err = %v, want FILE_TOO_LARGE", err)
+	}
+	if upload.Offset != 4 {
+		t.Errorf("offset = %d, want 4", upload.Offset)
+	}
+	if size := tt.stagedSize(t, upload.Id); size != 4 {
+		t.Errorf("staged %d bytes, want them truncated back to 4", size)
+	}
+}
+
+func TestTusAppendKeepsInterruptedChunk(t *testing.T) {
+	tt := newTusTest(t)
+	ctx := context.Background()
+	upload := tt.create(t, 10)
+
+	upload, err := tt.service.Append(ctx, tt.account, upload.Id, 0, brokenChunk{strings.NewReader("abc")})
+	if !errors.Is(err, http_error.BAD_REQUEST_ERROR) {
+		t.Fatalf("err = %v, want BAD_REQUEST_ERROR", err)
+	}
+	if upload.Offset != 3 {
+		t.Errorf("offset = %d, want the 3 bytes received", upload.Offset)
+	}
+	if stored, _ := tt.service.Get(ctx, tt.account, upload.Id); stored.Offset != 3 {
+		t.Errorf("stored offset = %d, want 3", stored.Offset)
+	}
+	if _, err := tt.service.Append(ctx, tt.account, upload.Id, 3, strings.NewReader("defghij")); err != nil {
+		t.Fatalf("resuming: %v", err)
+	}
+	if data, _ := tt.storage.DownloadFile(ctx, "docs/notes.txt"); string(data) != "abcdefghij" {
+		t.Errorf("stored %q, want abcdefghij", data)
+	}
+}
+
+func TestTusEmptyPatchRetriesCompletion(t *testing.T) {
+	tt := newTusTest(t)
+	ctx := context.Background()
+	tt.uploads.failures = 1
+	upload := tt.create(t, 5)
+
+	upload, err := tt.service.Append(ctx, tt.account, upload.Id, 0, strings.NewReader("hello"))
+	if !errors.Is(err, http_error.UPLOAD_FAILED) {
+		t.Fatalf("err = %v, want UPLOAD_FAILED", err)
+	}
+	if upload.Offset != 5 || upload.FileId != nil {
+		t.Fatalf("offset %d, file %v; want every byte kept and no file", upload.Offset, upload.FileId)
+	}
+
+	upload, err = tt.service.Append(ctx, tt.account, upload.Id, 5, strings.NewReader(""))
+	if err != nil || upload.FileId == nil {
+		t.Fatalf("retry: file %v, err %v; want the stored file", upload.FileId, err)
+	}
+	if data, _ := tt.storage.DownloadFile(ctx, "docs/notes.txt"); string(data) != "hello" {
+		t.Errorf("stored %q, want hello", data)
+	}
+}
+
+func TestTusGetExpired(t *testing.T) {
+	tt := newTusTest(t)
+	upload := tt.create(t, 10)
+	upload.ExpiresAt = time.Now().Add(-time.Second)
+	tt.repo.uploads[upload.Id] = upload
+
+	if _, err := tt.service.Get(context.Background(), tt.account, upload.Id); !errors.Is(err, http_error.UPLOAD_EXPIRED) {
+		t.Errorf("err = %v, want UPLOAD_EXPIRED", err)
+	}
+	if _, err := tt.service.Get(context.Background(), uuid.New(), upload.Id); !errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		t.Errorf("another account: err = %v, want NOT_FOUND_ERROR", err)
+	}
+}
+
+func TestTusTerminate(t *testing.T) {
+	tt := newTusTest(t)
+	upload := tt.create(t, 10)
+
+	if err := tt.service.Terminate(context.Background(), uuid.New(), upload.Id); !errors.Is(err, http_error.NOT_FOUND_ERROR) {
+		t.Errorf("another account: err = %v, want NOT_FOUND_ERROR", err)
+	}
+	if err := tt.service.Terminate(context.Background(), tt.account, upload.Id); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	if _, err := os.Stat(tt.service.stagingPath(upload.Id)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("staged bytes kept: %v", err)
+	}
+	if _, ok := tt.repo.uploads[upload.Id]; ok {
+		t.Error("upload kept")
+	}
+}
+
+func TestTusCleanupExpiredPages(t *testing.T) {
+	tt := newTusTest(t)
+	expiredCount := tusCleanupBatchSize + 5
+	for i := 0; i < expiredCount; i++ {
+		upload := tt.create(t, 10)
+		upload.ExpiresAt = time.Now().Add(-time.Duration(i+1) * time.Minute)
+		tt.repo.uploads[upload.Id] = upload
+	}
+	kept := tt.create(t, 10)
+
+	removed, err := tt.service.CleanupExpired(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupExpired: %v", err)
+	}
+	if removed != expiredCount {
+		t.Errorf("removed %d, want %d", removed, expiredCount)
+	}
+	if len(tt.repo.uploads) != 1 {
+		t.Errorf("%d uploads left, want only the live one", len(tt.repo.uploads))
+	}
+	staged, _ := os.ReadDir(tt.service.tusConfig.GetStagingDir())
+	if len(staged) != 1 || staged[0].Name() != kept.Id.String() {
+		t.Errorf("staged files left: %d, want only the live upload", len(staged))
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// uploadRuleCacheTTL bounds how long an instance keeps rules another instance changed.
+const uploadRuleCacheTTL = time.Minute
+
+var (
+	uploadContextPattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+	pathTemplatePattern  = regexp.MustCompile(`^[a-z0-9_-]+(/[a-z0-9_-]+)*$`)
+	pathTemplateReplacer = strings.NewReplacer("{context}", "x", "{year}", "x", "{month}", "x")
+)
+
+// UploadRuleService serves the upload rules from an in-memory cache, reloaded after every
+// change made through it and at least every minute.
+type UploadRuleService interface {
+	Seed(ctx context.Context) error
+	Get(ctx context.Context, uploadContext string) (entity.UploadRule, error)
+	ForAccount(ctx context.Context, accountId uuid.UUID, uploadContext string, filename string) (entity.UploadRule, error)
+	List(ctx context.Context) ([]entity.UploadRule, error)
+	Create(ctx context.Context, req dto.UploadRuleRequest) (entity.UploadRule, error)
+	Update(ctx context.Context, uploadContext string, req dto.UploadRuleRequest) (entity.UploadRule, error)
+	Delete(ctx context.Context, uploadContext string) error
+}
+
+type uploadRuleService struct {
+	uploadConfig config.UploadConfig
+	ruleRepo     repositories.UploadRuleRepository
+	accountRepo  repositories.AccountRepository
+
+	mu       sync.RWMutex
+	rules    map[string]entity.UploadRule
+	loadedAt time.Time
+}
+
+func NewUploadRuleService(uploadConfig config.UploadConfig, ruleRepo repositories.UploadRuleRepository, accountRepo repositories.AccountRepository) UploadRuleService {
+	return &uploadRuleService{uploadConfig: uploadConfig, ruleRepo: ruleRepo, accountRepo: accountRepo}
+}
+
+// Seed creates the configured rules whose context does not exist yet, leaving the rules
+// admins changed alone, and loads the cache. A seed rule that is invalid is an error.
+func (s *uploadRuleService) Seed(ctx context.Context) error {
+	for _, req := range s.uploadConfig.GetSeedRules() {
+		rule, err := buildUploadRule(req, s.uploadConfig.GetFileTypes())
+		if err != nil {
+			return fmt.Errorf("seed rule %q: %w", req.Context, err)
+		}
+		created, err := s.ruleRepo.CreateMissing(ctx, rule)
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Printf("[BOOT][UPLOAD] Upload context %s seeded", rule.Context)
+		}
+	}
+	return s.reload(ctx)
+}
+
+// Get returns the rule of a context, whoever uploads. It is meant for files the
+// application stores itself.
+func (s *uploadRuleService) Get(ctx context.Context, uploadContext string) (entity.UploadRule, error) {
+	rules, err := s.cached(ctx)
+	if err != nil {
+		return entity.UploadRule{}, err
+	}
+	rule, ok := rules[uploadContext]
+	if !ok {
+		return entity.UploadRule{}, http_error.INVALID_UPLOAD_CONTEXT_ERROR
+	}
+	return rule, nil
+}
+
+// ForAccount returns the rule an account uploads filename with. Without a context it is
+// inferred from the extension among the rules the role of the account may use; a named
+// context the role may not use is forbidden.
+func (s *uploadRuleService) ForAccount(ctx context.Context, accountId uuid.UUID, uploadContext string, filename string) (entity.UploadRule, error) {
+	account, err := s.accountRepo.GetAccountById(ctx, accountId)
+	if err != nil {
+		return entity.UploadRule{}, http_error.UNAUTHORIZED
+	}
+	if uploadContext != "" {
+		rule, err := s.Get(ctx, uploadContext)
+		if err != nil {
+			return entity.UploadRule{}, err
+		}
+		if !ruleAllowsRole(rule, account.Role) {
+			return entity.UploadRule{}, http_error.FORBIDDEN_ERROR
+		}
+		return rule, nil
+	}
+
+	rules, err := s.cached(ctx)
+	if err != nil {
+		return entity.UploadRule{}, err
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	var candidates []entity.UploadRule
+	for _, rule := range rules {
+		if rule.Priority > 0 && ruleAllowsExt(rule, ext) && ruleAllowsRole(rule, account.Role) {
+			candidates = append(candidates, rule)
+		}
+	}
+	if len(candidates) == 0 {
+		return entity.UploadRule{}, http_error.INVALID_UPLOAD_CONTEXT_ERROR
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority < candidates[j].Priority
+		}
+		return candidates[i].Context < candidates[j].Context
+	})
+	return candidates[0], nil
+}
+
+func (s *uploadRuleService) List(ctx context.Context) ([]entity.UploadRule, error) {
+	return s.ruleRepo.List(ctx)
+}
+
+func (s *uploadRuleService) Create(ctx context.Context, req dto.UploadRuleRequest) (entity.UploadRule, error) {
+	rule, err := buildUploadRule(req, s.uploadConfig.GetFileTypes())
+	if err != nil {
+		return entity.UploadRule{}, err
+	}
+	if _, err := s.ruleRepo.GetByContext(ctx, rule.Context); err == nil {
+		return entity.UploadRule{}, http_error.DUPLICATE_DATA
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.UploadRule{}, err
+	}
+	created, err := s.ruleRepo.Create(ctx, rule)
+	if err != nil {
+		return entity.UploadRule{}, err
+	}
+	return created, s.reload(ctx)
+}
+
+// Update replaces every setting of a rule. Files already stored keep their visibility
+// and key.
+func (s *uploadRuleService) Update(ctx context.Context, uploadContext string, req dto.UploadRuleRequest) (entity.UploadRule, error) {
+	req.Context = uploadContext
+	rule, err := buildUploadRule(req, s.uploadConfig.GetFileTypes())
+	if err != nil {
+		return entity.UploadRule{}, err
+	}
+	updated, err := s.ruleRepo.Update(ctx, rule)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.UploadRule{}, http_error.NOT_FOUND_ERROR
+	}
+	if err != nil {
+		return entity.UploadRule{}, err
+	}
+	return updated, s.reload(ctx)
+}
+
+// Delete removes a rule; its files stay. Contexts the application uploads to itself
+// cannot be removed.
+func (s *uploadRuleService) Delete(ctx context.Context, uploadContext string) error {
+	if protectedFileContexts[uploadContext] {
+		return http_error.FORBIDDEN_ERROR
+	}
+	deleted, err := s.ruleRepo.Delete(ctx, uploadContext)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return http_error.NOT_FOUND_ERROR
+	}
+	return s.reload(ctx)
+}
+
+// cached returns the rules, reloading them once they are older than the TTL. A reload
+// that fails keeps serving the rules already loaded.
+func (s *uploadRuleService) cached(ctx context.Context) (map[string]entity.UploadRule, error) {
+	s.mu.RLock()
+	rules, loadedAt := s.rules, s.loadedAt
+	s.mu.RUnlock()
+	if time.Since(loadedAt) < uploadRuleCacheTTL {
+		return rules, nil
+	}
+	if err := s.reload(ctx); err != nil {
+		if rules == nil {
+			return nil, err
+		}
+		log.Printf("[UPLOAD] reloading upload rules failed, keeping the cached ones: %v", err)
+		return rules, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules, nil
+}
+
+func (s *uploadRuleService) reload(ctx context.Context) error {
+	list, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	rules := make(map[string]entity.UploadRule, len(list))
+	for _, rule := range list {
+		rules[rule.Context] = rule
+	}
+	s.mu.Lock()
+	s.rules, s.loadedAt = rules, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// buildUploadRule checks a request and normalises it: lowercase extensions with their
+// dot, lowercase MIME types and roles, without repeats. Extensions must be in the file
+// types table, as uploads of any other are refused.
+func buildUploadRule(req dto.UploadRuleRequest, fileTypes map[string][]string) (entity.UploadRule, error) {
+	invalid := func(reason string) (entity.UploadRule, error) {
+		return entity.UploadRule{}, fmt.Errorf("%w: %s", http_error.BAD_REQUEST_ERROR, reason)
+	}
+	uploadContext := strings.ToLower(strings.TrimSpace(req.Context))
+	if !uploadContextPattern.MatchString(uploadContext) {
+		return invalid("context must be 1 to 50 lowercase letters, digits, - or _")
+	}
+	if req.MaxBytes <= 0 || req.MaxCount <= 0 {
+		return invalid("max_bytes and max_count must be positive")
+	}
+	if req.Priority < 0 {
+		return invalid("priority cannot be negative")
+	}
+	template := strings.Trim(strings.TrimSpace(req.PathTemplate), "/")
+	expanded := pathTemplateReplacer.Replace(template)
+	if !pathTemplatePattern.MatchString(expanded) || strings.Contains(expanded, "{") {
+		return invalid("path_template must be lowercase path segments, with {context}, {year} or {month}")
+	}
+	if strings.HasPrefix(template+"/", config.PrivateStoragePrefix) {
+		return invalid("path_template cannot start with " + config.PrivateStoragePrefix + "; set private instead")
+	}
+
+	var exts []string
+	for _, ext := range req.AllowedExts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if len(ext) < 2 || strings.ContainsAny(ext[1:], "./\\ ") {
+			return invalid("allowed_exts has an invalid extension " + ext)
+		}
+		if _, ok := fileTypes[ext]; !ok {
+			return invalid("allowed_exts has " + ext + ", which the file types table does not know")
+		}
+		if !slices.Contains(exts, ext) {
+			exts = append(exts, ext)
+		}
+	}
+	if len(exts) == 0 {
+		return invalid("allowed_exts cannot be empty")
+	}
+	var mimeTypes []string
+	for _, mimeType := range req.AllowedMimeTypes {
+		mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+		if !strings.Contains(mimeType, "/") {
+			return invalid("allowed_mime_types has an invalid type " + mimeType)
+		}
+		if !slices.Contains(mimeTypes, mimeType) {
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+	}
+	var roles []string
+	for _, role := range req.Roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role != "" && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	now := time.Now()
+	return entity.UploadRule{
+		Id:               uuid.New(),
+		Context:          uploadContext,
+		MaxBytes:         req.MaxBytes,
+		MaxCount:         req.MaxCount,
+		AllowedExts:      exts,
+		AllowedMimeTypes: mimeTypes,
+		PathTemplate:     template,
+		Private:          req.Private,
+		ProcessImages:    req.ProcessImages,
+		Roles:            roles,
+		Priority:         req.Priority,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}, nil
+}
+
+func ruleAllowsExt(rule entity.UploadRule, ext string) bool {
+	return slices.Contains(rule.AllowedExts, ext)
+}
+
+// ruleAllowsMime accepts any type when the rule lists none.
+func ruleAllowsMime(rule entity.UploadRule, mimeType string) bool {
+	if len(rule.AllowedMimeTypes) == 0 {
+		return true
+	}
+	baseMime := strings.TrimSpace(strings.Split(mimeType, ";")[0])
+	return slices.Contains(rule.AllowedMimeTypes, strings.ToLower(baseMime))
+}
+
+// ruleAllowsRole accepts every role when the rule lists none.
+func ruleAllowsRole(rule entity.UploadRule, role string) bool {
+	return len(rule.Roles) == 0 || slices.Contains(rule.Roles, strings.ToLower(role))
+}
+
+// expandPathTemplate fills the placeholders of a rule's path template.
+func expandPathTemplate(rule entity.UploadRule, now time.Time) string {
+	return strings.NewReplacer(
+		"{context}", rule.Context,
+		"{year}", fmt.Sprintf("%04d", now.Year()),
+		"{month}", fmt.Sprintf("%02d", now.Month()),
+	).Replace(rule.PathTemplate)
+}
@@ -1,12 +1,13 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net/http"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	entity "abdanhafidz.com/go-boilerplate/models/entity"
 	http_error "abdanhafidz.com/go-boilerplate/models/error"
 	"abdanhafidz.com/go-boilerplate/repositories"
+	"abdanhafidz.com/go-boilerplate/utils"
 	"github.com/google/uuid"
 )
 
@@ -23,25 +25,46 @@ type UploadService interface {
 	UploadFiles(ctx context.Context, files []*multipart.FileHeader, uploadContext string, accountID uuid.UUID) ([]entity.File, error)
 	GetFileByID(ctx context.Context, fileID uuid.UUID, accountID uuid.UUID) (*entity.File, error)
 	UploadRawFile(ctx context.Context, reader io.Reader, originalName string, contentType string, uploadContext string, accountID uuid.UUID) (*entity.File, error)
+	UploadStagedFile(ctx context.Context, stagedPath string, originalName string, uploadContext string, accountID uuid.UUID) (*entity.File, error)
+	UploadObject(ctx context.Context, storageKey string, size int64, originalName string, uploadContext string, accountID uuid.UUID) (*entity.File, error)
 }
 
 type uploadService struct {
+	transactor      repositories.Transactor
 	storageProvider storageUploader
+	blobService     BlobService
+	quotaService    QuotaService
 	fileRepo        repositories.FileRepository
 	accountRepo     repositories.AccountRepository
-	cfg             config.UploadConfig
+	ruleService     UploadRuleService
+	uploadConfig    config.UploadConfig
+	storageConfig   config.StorageConfig
+	imageConfig     config.ImageConfig
+	scanQueue       fileScanQueue
 }
 
-func NewUploadService(storage storageUploader, repo repositories.FileRepository, accountRepo repositories.AccountRepository, cfg config.UploadConfig) UploadService {
-	return &uploadService{storageProvider: storage, fileRepo: repo, accountRepo: accountRepo, cfg: cfg}
+func NewUploadService(transactor repositories.Transactor, storage storageUploader, blobService BlobService, quotaService QuotaService, repo repositories.FileRepository, accountRepo repositories.AccountRepository, ruleService UploadRuleService, uploadConfig config.UploadConfig, storageConfig config.StorageConfig, imageConfig config.ImageConfig, scanQueue fileScanQueue) UploadService {
+	return &uploadService{transactor: transactor, storageProvider: storage, blobService: blobService, quotaService: quotaService, fileRepo: repo, accountRepo: accountRepo, ruleService: ruleService, uploadConfig: uploadConfig, storageConfig: storageConfig, imageConfig: imageConfig, scanQueue: scanQueue}
 }
 
 type storageUploader interface {
-	UploadFile(ctx context.Context, file io.Reader, destinationPath string, contentType string) (string, error)
+	OpenFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error)
+	SignedURL(ctx context.Context, path string, expiresIn time.Duration) (string, error)
 }
 
+// fileScanQueue hands uploads to the malware scan worker.
+type fileScanQueue interface {
+	UploadStatus() string
+	Notify()
+}
+
+// UploadFiles stores files under one upload rule. Without a context, the rule is inferred
+// from the extension of the first file.
 func (s *uploadService) UploadFiles(ctx context.Context, files []*multipart.FileHeader, uploadContext string, accountID uuid.UUID) ([]entity.File, error) {
-	config, err := s.cfg.Get(uploadContext)
+	if len(files) == 0 {
+		return nil, http_error.INVALID_DATA_PAYLOAD
+	}
+	config, err := s.ruleService.ForAccount(ctx, accountID, uploadContext, files[0].Filename)
 	if err != nil {
 		return nil, err
 	}
@@ -55,12 +78,17 @@ func (s *uploadService) UploadFiles(ctx context.Context, files []*multipart.File
 	var lastErr error
 
 	for _, fileHeader := range files {
-		fileEntity, err := s.processSingleFile(ctx, fileHeader, config, uploadContext, accountID)
+		fileEntity, err := s.processSingleFile(ctx, multipartSource(fileHeader), config, accountID)
 		if err != nil {
 			failedCount++
 			lastErr = err
 			continue
 		}
+		if err := s.signPrivate(ctx, fileEntity); err != nil {
+			failedCount++
+			lastErr = err
+			continue
+		}
 		uploadedFiles = append(uploadedFiles, *fileEntity)
 	}
 
@@ -87,77 +115,251 @@ func (s *uploadService) GetFileByID(ctx context.Context, fileID uuid.UUID, accou
 		return nil, http_error.NOT_FOUND_ERROR
 	}
 
+	if err := s.signPrivate(ctx, file); err != nil {
+		return nil, err
+	}
 	return file, nil
 }
 
-func (s *uploadService) processSingleFile(ctx context.Context, fileHeader *multipart.FileHeader, config config.UploadRule, uploadContext string, accountID uuid.UUID) (*entity.File, error) {
-	if _, err := s.accountRepo.GetAccountById(ctx, accountID); err != nil {
-		return nil, http_error.UNAUTHORIZED
+func (s *uploadService) signPrivate(ctx context.Context, file *entity.File) error {
+	return signPrivateFile(ctx, s.storageProvider, s.storageConfig.GetSignedURLTTL(), file)
+}
+
+// signPrivateFile replaces the empty paths of a private file and its variants with fresh
+// signed URLs. A file not scanned clean gets no URL at all.
+func signPrivateFile(ctx context.Context, storage storageUploader, ttl time.Duration, file *entity.File) error {
+	if file.ScanStatus != entity.ScanStatusClean {
+		file.Path, file.URLExpiresAt = "", nil
+		for i := range file.Variants {
+			file.Variants[i].Path, file.Variants[i].URLExpiresAt = "", nil
+		}
+		return nil
+	}
+	if !file.Private {
+		return nil
 	}
-	detectedMimeType, err := s.validateFile(fileHeader, config)
+	signedURL, err := storage.SignedURL(ctx, file.StorageKey, ttl)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	expiresAt := time.Now().Add(ttl)
+	file.Path = signedURL
+	file.URLExpiresAt = &expiresAt
+	for i := range file.Variants {
+		variantURL, err := storage.SignedURL(ctx, file.Variants[i].StorageKey, ttl)
+		if err != nil {
+			return err
+		}
+		file.Variants[i].Path = variantURL
+		file.Variants[i].URLExpiresAt = &expiresAt
+	}
+	return nil
+}
 
-	ext := strings.ToLower(strings.TrimSpace(filepath.Ext(fileHeader.Filename)))
-	storedFilename := s.generateStoredFilename(fileHeader.Filename, ext)
-	storagePath := s.generateStoragePath(config.PathPrefix, uploadContext, storedFilename, accountID)
-
-	src, err := fileHeader.Open()
+// UploadStagedFile validates and stores a file already on local disk, such as a finished
+// resumable upload, exactly like a multipart upload to the same context.
+func (s *uploadService) UploadStagedFile(ctx context.Context, stagedPath string, originalName string, uploadContext string, accountID uuid.UUID) (*entity.File, error) {
+	config, err := s.ruleService.ForAccount(ctx, accountID, uploadContext, originalName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(stagedPath)
 	if err != nil {
 		return nil, http_error.INTERNAL_SERVER_ERROR
 	}
-	defer src.Close()
+	source := uploadSource{
+		name: originalName,
+		size: info.Size(),
+		open: func() (io.ReadCloser, error) { return os.Open(stagedPath) },
+	}
+	fileEntity, err := s.processSingleFile(ctx, source, config, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return fileEntity, s.signPrivate(ctx, fileEntity)
+}
 
-	publicURL, err := s.storageProvider.UploadFile(ctx, src, storagePath, detectedMimeType)
+// UploadObject validates and stores an object of size bytes already in storage, such as
+// a file a client uploaded directly, exactly like a multipart upload to the same context.
+// The object is streamed from storage each time it is read, never held in memory whole.
+func (s *uploadService) UploadObject(ctx context.Context, storageKey string, size int64, originalName string, uploadContext string, accountID uuid.UUID) (*entity.File, error) {
+	config, err := s.ruleService.ForAccount(ctx, accountID, uploadContext, originalName)
 	if err != nil {
-		return nil, http_error.UPLOAD_FAILED
+		return nil, err
+	}
+	source := uploadSource{
+		name: originalName,
+		size: size,
+		open: func() (io.ReadCloser, error) {
+			return &objectReader{ctx: ctx, storage: s.storageProvider, key: storageKey, size: size}, nil
+		},
+	}
+	fileEntity, err := s.processSingleFile(ctx, source, config, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return fileEntity, s.signPrivate(ctx, fileEntity)
+}
+
+// uploadSource is a file waiting to be validated and stored.
+type uploadSource struct {
+	name string
+	size int64
+	open func() (io.ReadCloser, error)
+}
+
+func multipartSource(fileHeader *multipart.FileHeader) uploadSource {
+	return uploadSource{
+		name: fileHeader.Filename,
+		size: fileHeader.Size,
+		open: func() (io.ReadCloser, error) { return fileHeader.Open() },
+	}
+}
+
+func (s *uploadService) processSingleFile(ctx context.Context, source uploadSource, config entity.UploadRule, accountID uuid.UUID) (*entity.File, error) {
+	if _, err := s.accountRepo.GetAccountById(ctx, accountID); err != nil {
+		return nil, http_error.UNAUTHORIZED
+	}
+	detectedMimeType, err := s.validateFile(source, config)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(strings.TrimSpace(filepath.Ext(source.name)))
+	baseName := strings.TrimSuffix(source.name, filepath.Ext(source.name))
+	content, size := source.open, source.size
+	var prepared *utils.PreparedImage
+	if config.ProcessImages {
+		if prepared, err = s.prepareImage(source); err != nil {
+			return nil, err
+		}
+		content, size = bytesSource(prepared.Data), int64(len(prepared.Data))
+		if prepared.MimeType != detectedMimeType {
+			detectedMimeType, ext = prepared.MimeType, prepared.Ext
+		}
 	}
 
 	fileEntity := &entity.File{
 		Id:           uuid.New(),
-		OriginalName: fileHeader.Filename,
-		StoredName:   storedFilename,
+		OriginalName: source.name,
+		StoredName:   s.generateStoredFilename(baseName+ext, ext),
 		MimeType:     detectedMimeType,
-		Size:         fileHeader.Size,
-		Path:         publicURL,
-		Context:      uploadContext,
+		Context:      config.Context,
+		Private:      config.Private,
+		ScanStatus:   s.scanQueue.UploadStatus(),
 		AccountId:    accountID,
 		CreatedAt:    time.Now(),
 	}
 
-	if err := s.fileRepo.Create(ctx, fileEntity); err != nil {
+	prefix := expandPathTemplate(config, fileEntity.CreatedAt)
+
+	// The quota and the blob references are counted in the same transaction as the rows,
+	// so a failed upload takes up nothing. The quota comes first: an upload over it is
+	// refused before any byte is stored.
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.quotaService.Reserve(ctx, accountID, size, 1); err != nil {
+			return err
+		}
+		blob, err := s.blobService.Store(ctx, content, prefix, ext, detectedMimeType, config.Private)
+		if err != nil {
+			return err
+		}
+		fileEntity.Size, fileEntity.Sha256 = blob.Size, blob.Sha256
+		fileEntity.Path, fileEntity.StorageKey = blob.URL, blob.StorageKey
+		if prepared != nil {
+			fileEntity.Width, fileEntity.Height = prepared.Width, prepared.Height
+			if fileEntity.Variants, err = s.uploadVariants(ctx, prepared, fileEntity.Id, prefix, config.Private); err != nil {
+				return err
+			}
+		}
+		return s.fileRepo.Create(ctx, fileEntity)
+	})
+	switch {
+	case errors.Is(err, http_error.UPLOAD_FAILED):
+		return nil, http_error.UPLOAD_FAILED
+	case errors.Is(err, http_error.QUOTA_EXCEEDED), errors.Is(err, http_error.UNAUTHORIZED):
+		return nil, err
+	case err != nil:
 		return nil, http_error.INTERNAL_SERVER_ERROR
 	}
+	if fileEntity.ScanStatus == entity.ScanStatusPending {
+		s.scanQueue.Notify()
+	}
 
 	return fileEntity, nil
 }
 
-func (s *uploadService) validateFile(file *multipart.FileHeader, config config.UploadRule) (string, error) {
-	if file.Size == 0 || file.Size > config.MaxBytes {
-		return "", http_error.FILE_TOO_LARGE
-	}
-
-	src, err := file.Open()
+// prepareImage reads an image and runs it through the image pipeline. An image the
+// pipeline cannot decode is refused like a file of the wrong type.
+func (s *uploadService) prepareImage(source uploadSource) (*utils.PreparedImage, error) {
+	src, err := source.open()
 	if err != nil {
-		return "", http_error.INTERNAL_SERVER_ERROR
+		return nil, http_error.INTERNAL_SERVER_ERROR
 	}
 	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, http_error.INTERNAL_SERVER_ERROR
+	}
+	prepared, err := utils.PrepareImage(data, s.imageConfig.GetMaxDimension(), s.imageConfig.GetJPEGQuality())
+	if errors.Is(err, utils.ErrImageTooLarge) {
+		return nil, http_error.FILE_TOO_LARGE
+	}
+	if err != nil {
+		return nil, http_error.INVALID_FILE_TYPE
+	}
+	return prepared, nil
+}
 
-	buffer := make([]byte, 512)
-	_, err = src.Read(buffer)
-	if err != nil && err != io.EOF {
-		return "", http_error.INTERNAL_SERVER_ERROR
+// uploadVariants stores a thumbnail per configured size as a blob of its own. Sizes the
+// image already fits are skipped.
+func (s *uploadService) uploadVariants(ctx context.Context, prepared *utils.PreparedImage, fileID uuid.UUID, prefix string, private bool) ([]entity.FileVariant, error) {
+	var variants []entity.FileVariant
+	for _, dimension := range s.imageConfig.GetVariantSizes() {
+		thumbnail, ok, err := prepared.Thumbnail(dimension)
+		if err != nil {
+			return nil, http_error.INTERNAL_SERVER_ERROR
+		}
+		if !ok {
+			continue
+		}
+		blob, err := s.blobService.Store(ctx, bytesSource(thumbnail.Data), prefix, thumbnail.Ext, thumbnail.MimeType, private)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, entity.FileVariant{
+			Id:         uuid.New(),
+			FileId:     fileID,
+			Dimension:  dimension,
+			Width:      thumbnail.Width,
+			Height:     thumbnail.Height,
+			MimeType:   thumbnail.MimeType,
+			Size:       blob.Size,
+			Sha256:     blob.Sha256,
+			Path:       blob.URL,
+			StorageKey: blob.StorageKey,
+			CreatedAt:  time.Now(),
+		})
 	}
+	return variants, nil
+}
 
-	detectedMimeType := http.DetectContentType(buffer)
+func (s *uploadService) validateFile(file uploadSource, config entity.UploadRule) (string, error) {
+	if file.size == 0 || file.size > config.MaxBytes {
+		return "", http_error.FILE_TOO_LARGE
+	}
 
-	ext := strings.ToLower(strings.TrimSpace(filepath.Ext(file.Filename)))
-	if !config.AllowedExts[ext] {
+	ext := strings.ToLower(strings.TrimSpace(filepath.Ext(file.name)))
+	if !ruleAllowsExt(config, ext) {
 		return "", http_error.INVALID_FILE_TYPE
 	}
 
-	if !isValidMimeForExt(ext, detectedMimeType) {
+	detectedMimeType, err := detectFileType(file)
+	if err != nil {
+		return "", http_error.INTERNAL_SERVER_ERROR
+	}
+
+	if !s.isValidMimeForExt(ext, detectedMimeType) || !ruleAllowsMime(config, detectedMimeType) {
 		return "", http_error.INVALID_FILE_TYPE
 	}
 
@@ -169,34 +371,35 @@ func (s *uploadService) validateFile(file *multipart.FileHeader, config config.U
 	return detectedMimeType, nil
 }
 
-func isValidMimeForExt(ext string, mimeType string) bool {
-	baseMime := strings.Split(mimeType, ";")[0]
-	baseMime = strings.TrimSpace(baseMime)
-
-	switch ext {
-	case ".jpg", ".jpeg":
-		return baseMime == "image/jpeg"
-	case ".png":
-		return baseMime == "image/png"
-	case ".gif":
-		return baseMime == "image/gif"
-	case ".pdf":
-		return baseMime == "application/pdf"
-	case ".txt":
-		return baseMime == "text/plain"
-	case ".c", ".cpp":
-		return baseMime == "submissions/x-c++src"
-	case ".py":
-		return baseMime == "submissions/x-python3src"
-	case ".java":
-		return baseMime == "submissions/x-java8src"
-	case ".cs":
-		return baseMime == "submissions/x-csharp8src"
-	case ".js":
-		return baseMime == "submissions/x-javascriptsrc"
-	default:
-		return false
+// detectFileType reads the content of an upload to tell its type. Multipart, staged and
+// stored files are read in place; anything else is read into memory first.
+func detectFileType(file uploadSource) (string, error) {
+	src, err := file.open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	readerAt, ok := src.(io.ReaderAt)
+	if !ok {
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return "", err
+		}
+		readerAt = bytes.NewReader(data)
+	}
+	return utils.DetectFileType(readerAt, file.size)
+}
+
+// isValidMimeForExt checks the detected type against the file types table, so a file
+// cannot pass for another by its extension.
+func (s *uploadService) isValidMimeForExt(ext string, mimeType string) bool {
+	baseMime := strings.TrimSpace(strings.Split(mimeType, ";")[0])
+	for _, allowed := range s.uploadConfig.GetFileTypes()[ext] {
+		if allowed == baseMime {
+			return true
+		}
 	}
+	return false
 }
 
 func (s *uploadService) generateStoredFilename(originalName string, ext string) string {
@@ -209,20 +412,8 @@ func (s *uploadService) generateStoredFilename(originalName string, ext string)
 	return fmt.Sprintf("%s-%d-%s%s", uniqueID.String(), timestamp, sanitizedRawName, ext)
 }
 
-func (s *uploadService) generateStoragePath(prefix, contextType, filename string, accountID uuid.UUID) string {
-	switch contextType {
-	case "submission":
-		now := time.Now()
-		return fmt.Sprintf("%s/%d/%02d/%s", prefix, now.Year(), now.Month(), filename)
-	case "material":
-		return fmt.Sprintf("%s/%s/%s", prefix, accountID.String(), filename)
-	default:
-		return fmt.Sprintf("%s/%s", prefix, filename)
-	}
-}
-
 func (s *uploadService) UploadRawFile(ctx context.Context, reader io.Reader, originalName string, contentType string, uploadContext string, accountID uuid.UUID) (*entity.File, error) {
-	rule, err := s.cfg.Get(uploadContext)
+	rule, err := s.ruleService.Get(ctx, uploadContext)
 	if err != nil {
 		return nil, http_error.BAD_REQUEST_ERROR
 	}
@@ -240,10 +431,9 @@ func (s *uploadService) UploadRawFile(ctx context.Context, reader io.Reader, ori
 		}
 	}
 
-	storedFilename := s.generateStoredFilename(originalName, ext)
-	storagePath := s.generateStoragePath(rule.PathPrefix, uploadContext, storedFilename, accountID)
-
-	publicURL, err := s.storageProvider.UploadFile(ctx, reader, storagePath, contentType)
+	// Content made by the app itself, such as receipt PDFs, is small; it is read whole so
+	// it can be hashed before it is stored.
+	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
@@ -251,16 +441,27 @@ func (s *uploadService) UploadRawFile(ctx context.Context, reader io.Reader, ori
 	fileEntity := &entity.File{
 		Id:           uuid.New(),
 		OriginalName: originalName,
-		StoredName:   storedFilename,
+		StoredName:   s.generateStoredFilename(originalName, ext),
 		MimeType:     contentType,
-		Size:         0,
-		Path:         publicURL,
-		Context:      uploadContext,
+		Context:      rule.Context,
+		Private:      rule.Private,
+		ScanStatus:   entity.ScanStatusClean, // made by the app itself, such as receipt PDFs
 		AccountId:    accountID,
 		CreatedAt:    time.Now(),
 	}
-
-	if err := s.fileRepo.Create(ctx, fileEntity); err != nil {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		blob, err := s.blobService.Store(ctx, bytesSource(data), expandPathTemplate(rule, fileEntity.CreatedAt), ext, contentType, rule.Private)
+		if err != nil {
+			return err
+		}
+		fileEntity.Size, fileEntity.Sha256 = blob.Size, blob.Sha256
+		fileEntity.Path, fileEntity.StorageKey = blob.URL, blob.StorageKey
+		if err := s.quotaService.Record(ctx, accountID, blob.Size, 1); err != nil {
+			return err
+		}
+		return s.fileRepo.Create(ctx, fileEntity)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	http_error "abdanhafidz.com/go-boilerplate/models/error"
+	"abdanhafidz.com/go-boilerplate/repositories"
+	"abdanhafidz.com/go-boilerplate/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VoucherService manages discount codes and their redemptions. It listens to payments so a
+// redemption is marked redeemed once its payment is PAID.
+type VoucherService interface {
+	PaymentListener
+	Create(ctx context.Context, req dto.CreateVoucherRequest) (entity.Voucher, error)
+	Update(ctx context.Context, voucherId uuid.UUID, req dto.UpdateVoucherRequest) (entity.Voucher, error)
+	Get(ctx context.Context, voucherId uuid.UUID) (entity.Voucher, error)
+	List(ctx context.Context, pagination entity.Pagination) ([]entity.Voucher, error)
+	ListRedemptions(ctx context.Context, voucherId uuid.UUID, pagination entity.Pagination) ([]entity.VoucherRedemption, error)
+	Find(ctx context.Context, code string) (entity.Voucher, error)
+	Reserve(ctx context.Context, accountId uuid.UUID, code string, product entity.Product, price entity.Price, paymentId uuid.UUID) (entity.Voucher, float64, error)
+	Release(ctx context.Context, paymentId uuid.UUID) error
+}
+
+type voucherService struct {
+	transactor  repositories.Transactor
+	voucherRepo repositories.VoucherRepository
+	productRepo repositories.ProductRepository
+}
+
+func NewVoucherService(transactor repositories.Transactor, voucherRepo repositories.VoucherRepository, productRepo repositories.ProductRepository) VoucherService {
+	return &voucherService{
+		transactor:  transactor,
+		voucherRepo: voucherRepo,
+		productRepo: productRepo,
+	}
+}
+
+// normalizeVoucherCode checks the code format and makes lookups case-insensitive.
+func normalizeVoucherCode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if err := utils.ValidateCode(code); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(code), nil
+}
+
+func (s *voucherService) Create(ctx context.Context, req dto.CreateVoucherRequest) (entity.Voucher, error) {
+	code, err := normalizeVoucherCode(req.Code)
+	if err != nil {
+		return entity.Voucher{}, err
+	}
+	products, err := s.products(ctx, req.ProductIds)
+	if err != nil {
+		return entity.Voucher{}, err
+	}
+
+	voucher := entity.Voucher{
+		Code:           code,
+		Description:    req.Description,
+		DiscountType:   req.DiscountType,
+		DiscountValue:  req.DiscountValue,
+		MaxDiscount:    req.MaxDiscount,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		MaxRedemptions: req.MaxRedemptions,
+		MaxPerAccount:  req.MaxPerAccount,
+		IsActive:       true,
+		Products:       products,
+	}
+	if err := validateVoucher(voucher); err != nil {
+		return entity.Voucher{}, err
+	}
+
+	voucher, err = s.voucherRepo.Create(ctx, voucher)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return entity.Voucher{}, http_error.DUPLICATE_DATA
+	}
+	return voucher, err
+}
+
+func (s *voucherService) Update(ctx context.Context, voucherId uuid.UUID, req dto.UpdateVoucherRequest) (entity.Voucher, error) {
+	voucher, err := s.Get(ctx, voucherId)
+	if err != nil {
+		return entity.Voucher{}, err
+	}
+
+	if req.Description != nil {
+		voucher.Description = *req.Description
+	}
+	if req.DiscountValue != nil {
+		voucher.DiscountValue = *req.DiscountValue
+	}
+	if req.MaxDiscount != nil {
+		voucher.MaxDiscount = *req.MaxDiscount
+	}
+	if req.StartsAt != nil {
+		voucher.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		voucher.EndsAt = req.EndsAt
+	}
+	if req.MaxRedemptions != nil {
+		voucher.MaxRedemptions = *req.MaxRedemptions
+	}
+	if req.MaxPerAccount != nil {
+		voucher.MaxPerAccount = *req.MaxPerAccount
+	}
+	if req.IsActive != nil {
+		voucher.IsActive = *req.IsActive
+	}
+	var products []entity.Product
+	if req.ProductIds != nil {
+		if products, err = s.products(ctx, *req.ProductIds); err != nil {
+			return entity.Voucher{}, err
+		}
+		if products == nil {
+			products = []entity.Product{}
+		}
+	}
+	if err := validateVoucher(voucher); err != nil {
+		return entity.Voucher{}, err
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := s.voucherRepo.Update(ctx, voucher, products)
+		return err
+	})
+	if err != nil {
+		return entity.Voucher{}, err
+	}
+	return s.Get(ctx, voucherId)
+}
+
+func (s *voucherService) Get(ctx context.Context, voucherId uuid.UUID) (entity.Voucher, error) {
+	voucher, err := s.voucherRepo.GetById(ctx, voucherId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Voucher{}, http_error.NOT_FOUND_ERROR
+	}
+	return voucher, err
+}
+
+func (s *voucherService) List(ctx context.Context, pagination entity.Pagination) ([]entity.Voucher, error) {
+	return s.voucherRepo.List(ctx, pagination)
+}
+
+func (s *voucherService) ListRedemptions(ctx context.Context, voucherId uuid.UUID, pagination entity.Pagination) ([]entity.VoucherRedemption, error) {
+	if _, err := s.Get(ctx, voucherId); err != nil {
+		return nil, err
+	}
+	return s.voucherRepo.ListRedemptions(ctx, voucherId, pagination)
+}
+
+// Find looks an active voucher up by code without checking whether it applies.
+func (s *voucherService) Find(ctx context.Context, code string) (entity.Voucher, error) {
+	code, err := normalizeVoucherCode(code)
+	if err != nil {
+		return entity.Voucher{}, err
+	}
+	voucher, err := s.voucherRepo.GetByCode(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !voucher.IsActive) {
+		return entity.Voucher{}, http_error.VOUCHER_NOT_APPLICABLE
+	}
+	return voucher, err
+}
+
+// Reserve takes a slot of the voucher for a payment about to be created with paymentId and
+// returns the discount it grants on the price. The voucher row stays locked while its
+// redemptions are counted, so concurrent checkouts cannot exceed its limits.
+func (s *voucherService) Reserve(ctx context.Context, accountId uuid.UUID, code string, product entity.Product, price entity.Price, paymentId uuid.UUID) (entity.Voucher, float64, error) {
+	code, err := normalizeVoucherCode(code)
+	if err != nil {
+		return entity.Voucher{}, 0, err
+	}
+
+	var voucher entity.Voucher
+	var discount float64
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		voucher, err = s.voucherRepo.LockByCode(ctx, code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http_error.VOUCHER_NOT_APPLICABLE
+		} else if err != nil {
+			return err
+		}
+		if !voucherApplies(voucher, product.Id, time.Now()) {
+			return http_error.VOUCHER_NOT_APPLICABLE
+		}
+
+		if voucher.MaxRedemptions > 0 {
+			used, err := s.voucherRepo.CountRedemptions(ctx, voucher.Id, nil)
+			if err != nil {
+				return err
+			}
+			if used >= int64(voucher.MaxRedemptions) {
+				return http_error.VOUCHER_EXHAUSTED
+			}
+		}
+		if voucher.MaxPerAccount > 0 {
+			used, err := s.voucherRepo.CountRedemptions(ctx, voucher.Id, &accountId)
+			if err != nil {
+				return err
+			}
+			if used >= int64(voucher.MaxPerAccount) {
+				return http_error.VOUCHER_EXHAUSTED
+			}
+		}
+
+		discount = voucherDiscount(voucher, price.Amount)
+		_, err = s.voucherRepo.CreateRedemption(ctx, entity.VoucherRedemption{
+			VoucherId: voucher.Id,
+			AccountId: accountId,
+			PaymentId: paymentId,
+			Discount:  discount,
+		})
+		return err
+	})
+	if err != nil {
+		return entity.Voucher{}, 0, err
+	}
+	return voucher, discount, nil
+}
+
+// Release gives back the slot reserved for a payment that could not be created.
+func (s *voucherService) Release(ctx context.Context, paymentId uuid.UUID) error {
+	return s.voucherRepo.DeleteRedemptionByPaymentId(ctx, paymentId)
+}
+
+func (s *voucherService) OnPaymentPaid(ctx context.Context, payment entity.Payment) error {
+	if payment.VoucherId == nil {
+		return nil
+	}
+	return s.voucherRepo.MarkRedeemed(ctx, payment.Id, time.Now())
+}
+
+// OnPaymentRefunded keeps the redemption: a refunded checkout still used the voucher.
+func (s *voucherService) OnPaymentRefunded(ctx context.Context, payment entity.Payment, refund entity.Refund) error {
+	return nil
+}
+
+func (s *voucherService) products(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error) {
+	var products []entity.Product
+	for _, id := range ids {
+		product, err := s.productRepo.GetProductById(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http_error.NOT_FOUND_ERROR
+		} else if err != nil {
+			return nil, err
+		}
+		product.Prices = nil
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+func validateVoucher(voucher entity.Voucher) error {
+	if voucher.DiscountType == entity.VoucherDiscountPercentage && voucher.DiscountValue > 100 {
+		return http_error.BAD_REQUEST_ERROR
+	}
+	if voucher.StartsAt != nil && voucher.EndsAt != nil && !voucher.EndsAt.After(*voucher.StartsAt) {
+		return http_error.BAD_REQUEST_ERROR
+	}
+	return nil
+}
+
+// voucherApplies reports whether the voucher can be used on the product at the given time.
+func voucherApplies(voucher entity.Voucher, productId uuid.UUID, at time.Time) bool {
+	if !voucher.IsActive {
+		return false
+	}
+	if voucher.StartsAt != nil && at.Before(*voucher.StartsAt) {
+		return false
+	}
+	if voucher.EndsAt != nil && !at.Before(*voucher.EndsAt) {
+		return false
+	}
+	if len(voucher.Products) == 0 {
+		return true
+	}
+	for _, product := range voucher.Products {
+		if product.Id == productId {
+			return true
+		}
+	}
+	return false
+}
+
+// voucherDiscount computes the discount on an amount, rounded to cents and never more than
+// the amount itself.
+func voucherDiscount(voucher entity.Voucher, amount float64) float64 {
+	discount := voucher.DiscountValue
+	if voucher.DiscountType == entity.VoucherDiscountPercentage {
+		discount = amount * voucher.DiscountValue / 100
+		if voucher.MaxDiscount > 0 && discount > voucher.MaxDiscount {
+			discount = voucher.MaxDiscount
+		}
+	}
+	discount = math.Round(discount*100) / 100
+	return math.Min(discount, amount)
+}
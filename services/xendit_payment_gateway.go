@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+
+	"abdanhafidz.com/go-boilerplate/config"
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	"github.com/xendit/xendit-go/v7/invoice"
+	"github.com/xendit/xendit-go/v7/refund"
+)
+
+type xenditPaymentGateway struct {
+	xenditConfig config.XenditConfig
+}
+
+func NewXenditPaymentGateway(xenditConfig config.XenditConfig) PaymentGateway {
+	return &xenditPaymentGateway{xenditConfig: xenditConfig}
+}
+
+func (g *xenditPaymentGateway) Name() string {
+	return config.PaymentGatewayXendit
+}
+
+func (g *xenditPaymentGateway) CreateInvoice(ctx context.Context, req dto.GatewayInvoiceRequest) (dto.GatewayInvoice, error) {
+	invoiceReq := *invoice.NewCreateInvoiceRequest(req.ExternalId, req.Amount)
+	invoiceDuration := float32(req.Duration.Seconds())
+	invoiceReq.InvoiceDuration = &invoiceDuration
+	invoiceReq.Description = &req.Description
+	invoiceReq.Currency = &req.Currency
+	if req.PayerEmail != "" {
+		invoiceReq.PayerEmail = &req.PayerEmail
+	}
+
+	inv, _, xenditErr := g.xenditConfig.GetClient().InvoiceApi.CreateInvoice(ctx).CreateInvoiceRequest(invoiceReq).Execute()
+	if xenditErr != nil {
+		return dto.GatewayInvoice{}, xenditErr
+	}
+	return xenditInvoiceToGateway(inv), nil
+}
+
+func (g *xenditPaymentGateway) GetInvoice(ctx context.Context, invoiceId string) (dto.GatewayInvoice, error) {
+	inv, _, xenditErr := g.xenditConfig.GetClient().InvoiceApi.GetInvoiceById(ctx, invoiceId).Execute()
+	if xenditErr != nil {
+		return dto.GatewayInvoice{}, xenditErr
+	}
+	return xenditInvoiceToGateway(inv), nil
+}
+
+// CreateRefund submits the refund; its outcome arrives later as a refund callback.
+func (g *xenditPaymentGateway) CreateRefund(ctx context.Context, req dto.GatewayRefundRequest) (dto.GatewayRefund, error) {
+	refundReq := *refund.NewCreateRefund()
+	refundReq.InvoiceId = &req.InvoiceId
+	refundReq.ReferenceId = &req.ReferenceId
+	refundReq.Amount = &req.Amount
+	refundReq.Currency = &req.Currency
+	refundReq.Reason = &req.Reason
+
+	res, _, xenditErr := g.xenditConfig.GetClient().RefundApi.CreateRefund(ctx).
+		IdempotencyKey(req.ReferenceId).
+		CreateRefund(refundReq).
+		Execute()
+	if xenditErr != nil {
+		return dto.GatewayRefund{}, xenditErr
+	}
+
+	out := dto.GatewayRefund{
+		ReferenceId: req.ReferenceId,
+		Amount:      req.Amount,
+		Status:      entity.RefundStatusPending,
+	}
+	if res.Id != nil {
+		out.Id = *res.Id
+	}
+	return out, nil
+}
+
+func xenditInvoiceToGateway(inv *invoice.Invoice) dto.GatewayInvoice {
+	res := dto.GatewayInvoice{
+		ExternalId: inv.ExternalId,
+		URL:        inv.InvoiceUrl,
+		Amount:     inv.Amount,
+		ExpiresAt:  inv.ExpiryDate,
+	}
+	if inv.Id != nil {
+		res.Id = *inv.Id
+	}
+	if inv.Currency != nil {
+		res.Currency = string(*inv.Currency)
+	}
+
+	switch inv.Status {
+	case invoice.INVOICESTATUS_PAID, invoice.INVOICESTATUS_SETTLED:
+		res.Status = entity.PaymentStatusPaid
+	case invoice.INVOICESTATUS_EXPIRED:
+		res.Status = entity.PaymentStatusExpired
+	default:
+		res.Status = entity.PaymentStatusPending
+	}
+	return res
+}
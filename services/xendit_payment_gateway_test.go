@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "abdanhafidz.com/go-boilerplate/models/dto"
+	entity "abdanhafidz.com/go-boilerplate/models/entity"
+	xendit "github.com/xendit/xendit-go/v7"
+)
+
+type fakeXenditConfig struct {
+	client *xendit.APIClient
+}
+
+func (c fakeXenditConfig) GetClient() *xendit.APIClient { return c.client }
+
+// newFakeXendit points a Xendit client at handler.
+func newFakeXendit(t *testing.T, handler http.HandlerFunc) PaymentGateway {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := xendit.NewClient("xnd_test_key")
+	client.GetConfig().(*xendit.Configuration).Servers[0].URL = server.URL
+	return NewXenditPaymentGateway(fakeXenditConfig{client: client})
+}
+
+func writeXenditInvoice(w http.ResponseWriter, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":          "inv-123",
+		"external_id": "PAY-1",
+		"status":      status,
+		"amount":      50000,
+		"currency":    "IDR",
+		"invoice_url": "https://checkout.xendit.co/inv-123",
+		"expiry_date": "2030-01-02T03:04:05Z",
+	})
+}
+
+func TestXenditCreateInvoice(t *testing.T) {
+	var got map[string]interface{}
+	gateway := newFakeXendit(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/invoices/" {
+			t.Errorf("request = %s %s, want POST /v2/invoices/", r.Method, r.URL.Path)
+		}
+		if user, _, ok := r.BasicAuth(); !ok || user != "xnd_test_key" {
+			t.Errorf("basic auth user = %q, want the API key", user)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		writeXenditInvoice(w, "PENDING")
+	})
+
+	inv, err := gateway.CreateInvoice(context.Background(), dto.GatewayInvoiceRequest{
+		ExternalId:  "PAY-1",
+		Amount:      50000,
+		Currency:    "IDR",
+		Description: "Course",
+		PayerEmail:  "payer@example.com",
+		Duration:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("CreateInvoice: %v", err)
+	}
+	if got["external_id"] != "PAY-1" || got["amount"] != float64(50000) || got["invoice_duration"] != float64(3600) || got["payer_email"] != "payer@example.com" {
+		t.Errorf("request body = %v", got)
+	}
+	want := dto.GatewayInvoice{
+		Id:         "inv-123",
+		ExternalId: "PAY-1",
+		URL:        "https://checkout.xendit.co/inv-123",
+		Amount:     50000,
+		Currency:   "IDR",
+		Status:     entity.PaymentStatusPending,
+		ExpiresAt:  time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if !inv.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", inv.ExpiresAt, want.ExpiresAt)
+	}
+	inv.ExpiresAt = want.ExpiresAt
+	if inv != want {
+		t.Errorf("invoice = %+v, want %+v", inv, want)
+	}
+}
+
+func TestXenditCreateInvoiceError(t *testing.T) {
+	gateway := newFakeXendit(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error_code":"API_VALIDATION_ERROR","message":"amount is invalid"}`))
+	})
+	if _, err := gateway.CreateInvoice(context.Background(), dto.GatewayInvoiceRequest{ExternalId: "PAY-1", Amount: 1}); err == nil {
+		t.Fatal("CreateInvoice succeeded, want the API error")
+	}
+}
+
+func TestXenditGetInvoiceMapsStatus(t *testing.T) {
+	for xenditStatus, want := range map[string]string{
+		"PENDING": entity.PaymentStatusPending,
+		"PAID":    entity.PaymentStatusPaid,
+		"SETTLED": entity.PaymentStatusPaid,
+		"EXPIRED": entity.PaymentStatusExpired,
+	} {
+		gateway := newFakeXendit(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != "/v2/invoices/inv-123" {
+				t.Errorf("request = %s %s, want GET /v2/invoices/inv-123", r.Method, r.URL.Path)
+			}
+			writeXenditInvoice(w, xenditStatus)
+		})
+		inv, err := gateway.GetInvoice(context.Background(), "inv-123")
+		if err != nil {
+			t.Fatalf("GetInvoice(%s): %v", xenditStatus, err)
+		}
+		if inv.Status != want {
+			t.Errorf("status for %s = %s, want %s", xenditStatus, inv.Status, want)
+		}
+	}
+}
+
+func TestXenditCreateRefund(t *testing.T) {
+	var got map[string]interface{}
+	gateway := newFakeXendit(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/refunds" {
+			t.Errorf("request = %s %s, want POST /refunds", r.Method, r.URL.Path)
+		}
+		if key := r.Header.Get("Idempotency-Key"); key != "REF-1" {
+			t.Errorf("Idempotency-Key = %q, want REF-1", key)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"rfd-1","reference_id":"REF-1","invoice_id":"inv-123","amount":20000,"currency":"IDR","status":"PENDING"}`))
+	})
+
+	refund, err := gateway.CreateRefund(context.Background(), dto.GatewayRefundRequest{
+		ReferenceId: "REF-1",
+		InvoiceId:   "inv-123",
+		Amount:      20000,
+		Currency:    "IDR",
+		Reason:      "REQUESTED_BY_CUSTOMER",
+	})
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+	if got["invoice_id"] != "inv-123" || got["amount"] != float64(20000) || got["reference_id"] != "REF-1" {
+		t.Errorf("request body = %v", got)
+	}
+	want := dto.GatewayRefund{Id: "rfd-1", ReferenceId: "REF-1", Amount: 20000, Status: entity.RefundStatusPending}
+	if refund != want {
+		t.Errorf("refund = %+v, want %+v", refund, want)
+	}
+}
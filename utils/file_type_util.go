@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MimeOctetStream = "application/octet-stream"
+	MimeTextPlain   = "text/plain; charset=utf-8"
+	MimeZip         = "application/zip"
+	MimeOLE         = "application/x-ole-storage"
+	MimeDocx        = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeXlsx        = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MimePptx        = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+)
+
+// textSniffLen is how much of a file is checked to tell text from binary content.
+const textSniffLen = 8192
+
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// DetectFileType tells the type of content from its bytes, never from its name. Magic
+// numbers come first: those http.DetectContentType knows (images, PDF, ZIP and more),
+// the OLE2 container of legacy Office files, and the Office Open XML formats, told apart
+// by the parts of their ZIP. Anything else is text/plain when its head is UTF-8 without
+// control bytes, such as source code, and application/octet-stream otherwise. Markup is
+// reported as text/plain too, as it is stored, not rendered.
+func DetectFileType(r io.ReaderAt, size int64) (string, error) {
+	head := make([]byte, min(size, textSniffLen))
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	if bytes.HasPrefix(head, oleSignature) {
+		return MimeOLE, nil
+	}
+	detected := http.DetectContentType(head)
+	switch {
+	case detected == MimeZip:
+		return detectZipType(r, size), nil
+	case strings.HasPrefix(detected, "text/"), detected == MimeOctetStream:
+		if isText(head, int64(len(head)) < size) {
+			return MimeTextPlain, nil
+		}
+		return MimeOctetStream, nil
+	}
+	return detected, nil
+}
+
+// detectZipType names the Office Open XML format of a ZIP from the folder of its main
+// part. A ZIP that cannot be read as one is reported as a plain ZIP.
+func detectZipType(r io.ReaderAt, size int64) string {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return MimeZip
+	}
+	hasContentTypes := false
+	folders := map[string]bool{}
+	for _, file := range archive.File {
+		if file.Name == "[Content_Types].xml" {
+			hasContentTypes = true
+		}
+		if folder, _, ok := strings.Cut(file.Name, "/"); ok {
+			folders[folder] = true
+		}
+	}
+	switch {
+	case !hasContentTypes:
+		return MimeZip
+	case folders["word"]:
+		return MimeDocx
+	case folders["xl"]:
+		return MimeXlsx
+	case folders["ppt"]:
+		return MimePptx
+	}
+	return MimeZip
+}
+
+// isText reports whether head is UTF-8 text: no NUL and no control bytes but tab, line
+// breaks, form feed and escape. A rune cut by the end of a truncated head is allowed.
+func isText(head []byte, truncated bool) bool {
+	head = bytes.TrimPrefix(head, []byte{0xEF, 0xBB, 0xBF})
+	for len(head) > 0 {
+		r, width := utf8.DecodeRune(head)
+		if r == utf8.RuneError && width <= 1 {
+			return truncated && !utf8.FullRune(head)
+		}
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' && r != 0x1B {
+			return false
+		}
+		if r == 0x7F {
+			return false
+		}
+		head = head[width:]
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectFileTypeSamples(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"main.go", MimeTextPlain},
+		{"script.py", MimeTextPlain},
+		{"index.html", MimeTextPlain},
+		{"data.csv", MimeTextPlain},
+		{"bom.txt", MimeTextPlain},
+		{"empty.txt", MimeTextPlain},
+		{"blob.bin", MimeOctetStream},
+		{"pixel.png", "image/png"},
+		{"document.pdf", "application/pdf"},
+		{"legacy.doc", MimeOLE},
+		{"report.docx", MimeDocx},
+		{"sheet.xlsx", MimeXlsx},
+		{"slides.pptx", MimePptx},
+		{"archive.zip", MimeZip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			info, err := file.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DetectFileType(file, info.Size())
+			if err != nil {
+				t.Fatalf("DetectFileType: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFileType(%s) = %s, want %s", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDetectFileTypeTextHead covers the edges of the text check, which only reads the
+// first textSniffLen bytes.
+func TestDetectFileTypeTextHead(t *testing.T) {
+	cutRune := strings.Repeat("a", textSniffLen-1) + "é and more"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"rune cut by the sniffed head", cutRune, MimeTextPlain},
+		{"truncated rune at the end", "caf\xc3", MimeOctetStream},
+		{"invalid UTF-8", "caf\xe9", MimeOctetStream},
+		{"NUL byte", "key\x00value", MimeOctetStream},
+		{"escape sequences", "\x1b[31mred\x1b[0m\n", MimeTextPlain},
+		{"binary past the head", strings.Repeat("a", textSniffLen) + "\x00", MimeTextPlain},
+		{"ZIP that is not one", "PK\x03\x04 broken", MimeZip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFileType(bytes.NewReader([]byte(tt.content)), int64(len(tt.content)))
+			if err != nil {
+				t.Fatalf("DetectFileType: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFileType = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxImagePixels bounds the images PrepareImage decodes, so a small file cannot expand
+// into gigabytes of pixels.
+const MaxImagePixels = 50_000_000
+
+var ErrImageTooLarge = errors.New("image has too many pixels")
+
+// EncodedImage is an image ready to be stored.
+type EncodedImage struct {
+	Data     []byte
+	MimeType string
+	Ext      string
+	Width    int
+	Height   int
+}
+
+// PreparedImage is an uploaded image after PrepareImage, together with its pixels so
+// thumbnails need not decode it again.
+type PreparedImage struct {
+	EncodedImage
+	pixels  *image.NRGBA
+	quality int
+}
+
+// PrepareImage turns an uploaded JPEG, PNG, GIF or WebP into what is stored: turned
+// upright as its EXIF orientation says, scaled down to fit maxDimension and without
+// metadata. JPEG and PNG are always encoded again, which drops EXIF, GPS and text chunks.
+// GIF and WebP that fit are kept as they are, less the EXIF and XMP chunks of a WebP, as
+// there is no encoder for them here; larger ones become JPEG, or PNG when transparent.
+func PrepareImage(data []byte, maxDimension int, quality int) (*PreparedImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := decoded.Bounds()
+	width, height := fitWithin(bounds.Dx(), bounds.Dy(), maxDimension)
+	resized := width != bounds.Dx() || height != bounds.Dy()
+	pixels := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if resized {
+		draw.CatmullRom.Scale(pixels, pixels.Bounds(), decoded, bounds, draw.Src, nil)
+	} else {
+		draw.Draw(pixels, pixels.Bounds(), decoded, bounds.Min, draw.Src)
+	}
+	if format == "jpeg" {
+		pixels = orient(pixels, jpegOrientation(data))
+	}
+
+	prepared := &PreparedImage{pixels: pixels, quality: quality}
+	switch {
+	case format == "jpeg" || format == "png":
+		prepared.EncodedImage, err = encodeImage(pixels, format, quality)
+	case !resized && format == "gif":
+		prepared.EncodedImage = EncodedImage{Data: data, MimeType: "image/gif", Ext: ".gif", Width: width, Height: height}
+	case !resized && format == "webp":
+		prepared.EncodedImage = EncodedImage{Data: stripWebPMetadata(data), MimeType: "image/webp", Ext: ".webp", Width: width, Height: height}
+	default:
+		prepared.EncodedImage, err = encodeImage(pixels, thumbnailFormat(pixels), quality)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prepared, nil
+}
+
+// Thumbnail scales the image to fit a size by size box, as JPEG or, when transparent, as
+// PNG. It reports false when the image already fits, as the variant would be no smaller.
+func (p *PreparedImage) Thumbnail(size int) (EncodedImage, bool, error) {
+	bounds := p.pixels.Bounds()
+	if size <= 0 || (bounds.Dx() <= size && bounds.Dy() <= size) {
+		return EncodedImage{}, false, nil
+	}
+	width, height := fitWithin(bounds.Dx(), bounds.Dy(), size)
+	thumbnail := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), p.pixels, bounds, draw.Src, nil)
+	encoded, err := encodeImage(thumbnail, thumbnailFormat(thumbnail), p.quality)
+	return encoded, err == nil, err
+}
+
+func thumbnailFormat(pixels *image.NRGBA) string {
+	if pixels.Opaque() {
+		return "jpeg"
+	}
+	return "png"
+}
+
+func encodeImage(pixels *image.NRGBA, format string, quality int) (EncodedImage, error) {
+	var out bytes.Buffer
+	encoded := EncodedImage{Width: pixels.Bounds().Dx(), Height: pixels.Bounds().Dy()}
+	if format == "png" {
+		if err := png.Encode(&out, pixels); err != nil {
+			return EncodedImage{}, err
+		}
+		encoded.MimeType, encoded.Ext = "image/png", ".png"
+	} else {
+		if err := jpeg.Encode(&out, pixels, &jpeg.Options{Quality: quality}); err != nil {
+			return EncodedImage{}, err
+		}
+		encoded.MimeType, encoded.Ext = "image/jpeg", ".jpg"
+	}
+	encoded.Data = out.Bytes()
+	return encoded, nil
+}
+
+// fitWithin scales width and height down, keeping the aspect ratio, until the longer
+// side is at most limit.
+func fitWithin(width int, height int, limit int) (int, int) {
+	longest := max(width, height)
+	if limit <= 0 || longest <= limit {
+		return width, height
+	}
+	scale := float64(limit) / float64(longest)
+	return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
+
+// orient applies an EXIF orientation, 1 to 8, so the image displays upright.
+func orient(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from the EXIF segment of a JPEG, 1 when
+// there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			i += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// Image data starts; EXIF always comes before it.
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation finds tag 0x0112 in the first IFD of a TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP and clears their flags in the
+// VP8X header. Data that is not a well-formed WebP is returned as is.
+func stripWebPMetadata(data []byte) []byte {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return data
+	}
+	out := append(make([]byte, 0, len(data)), data[:12]...)
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return data
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) {
+			if i+8+size != len(data) {
+				return data
+			}
+			// The padding byte of the last chunk may be missing.
+			end = len(data)
+		}
+		chunk := data[i:end]
+		switch string(chunk[:4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			header := append([]byte(nil), chunk...)
+			if len(header) > 8 {
+				header[8] &^= 0x08 | 0x04
+			}
+			out = append(out, header...)
+		default:
+			out = append(out, chunk...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
@@ -45,9 +45,10 @@ func ResponseFAILED[TMetaData any](c *gin.Context, metaData TMetaData, err error
 		return
 	} else if errors.Is(err, http_error.PAYMENT_REQUIRED) {
 		c.JSON(402, dto.SuccessResponse[TMetaData]{
-			Status:  "action_required",
-			Data:    metaData,
-			Message: http_error.PAYMENT_REQUIRED.Error(),
+			Status:   "action_required",
+			Data:     metaData,
+			Message:  http_error.PAYMENT_REQUIRED.Error(),
+			MetaData: metaData,
 		})
 		return
 	} else if errors.Is(err, http_error.NOT_FOUND_ERROR) || errors.Is(err, gorm.ErrRecordNotFound) {
@@ -66,7 +67,8 @@ func ResponseFAILED[TMetaData any](c *gin.Context, metaData TMetaData, err error
 			MetaData: metaData,
 		})
 		return
-	} else if errors.Is(err, http_error.FORBIDDEN_ERROR) || errors.Is(err, http_error.INVALID_CODE) {
+	} else if errors.Is(err, http_error.FORBIDDEN_ERROR) || errors.Is(err, http_error.INVALID_CODE) ||
+		errors.Is(err, http_error.INVALID_SIGNED_URL) || errors.Is(err, http_error.FILE_NOT_CLEAN) {
 		c.JSON(403, dto.ErrorResponse{
 			Status:   "error",
 			Error:    err,
@@ -74,10 +76,54 @@ func ResponseFAILED[TMetaData any](c *gin.Context, metaData TMetaData, err error
 			MetaData: metaData,
 		})
 		return
+	} else if errors.Is(err, http_error.INVALID_PAYMENT_TRANSITION) ||
+		errors.Is(err, http_error.INVALID_REFUND_TRANSITION) ||
+		errors.Is(err, http_error.REFUND_NOT_ALLOWED) ||
+		errors.Is(err, http_error.RECONCILIATION_RUNNING) ||
+		errors.Is(err, http_error.VOUCHER_EXHAUSTED) ||
+		errors.Is(err, http_error.SUBSCRIPTION_EXISTS) ||
+		errors.Is(err, http_error.INVALID_SUBSCRIPTION_STATE) ||
+		errors.Is(err, http_error.RENEWAL_RUNNING) {
+		c.JSON(409, dto.ErrorResponse{
+			Status:   "error",
+			Error:    err,
+			Message:  err.Error(),
+			MetaData: metaData,
+		})
+		return
+	} else if errors.Is(err, http_error.PAYMENT_FAILED) {
+		c.JSON(502, dto.ErrorResponse{
+			Status:   "error",
+			Error:    err,
+			Message:  err.Error(),
+			MetaData: metaData,
+		})
+		return
+	} else if errors.Is(err, http_error.OTP_ATTEMPTS_EXCEEDED) {
+		c.JSON(429, dto.ErrorResponse{
+			Status:   "error",
+			Error:    err,
+			Message:  err.Error(),
+			MetaData: metaData,
+		})
+		return
+	} else if errors.Is(err, http_error.INVALID_OTP) || errors.Is(err, http_error.EXPIRED_TOKEN) ||
+		errors.Is(err, http_error.REFUND_AMOUNT_EXCEEDED) ||
+		errors.Is(err, http_error.VOUCHER_NOT_APPLICABLE) {
+		c.JSON(400, dto.ErrorResponse{
+			Status:   "error",
+			Error:    err,
+			Message:  err.Error(),
+			MetaData: metaData,
+		})
+		return
 	} else if errors.Is(err, http_error.EVENT_START_DATE_IN_PAST) ||
 		errors.Is(err, http_error.EVENT_START_DATE_INVALID) ||
 		errors.Is(err, http_error.EVENT_END_DATE_INVALID) ||
-		errors.Is(err, http_error.INVALID_DATE_FORMAT) {
+		errors.Is(err, http_error.INVALID_DATE_FORMAT) ||
+		errors.Is(err, http_error.INVALID_FILE_TYPE) ||
+		errors.Is(err, http_error.INVALID_DATA_PAYLOAD) ||
+		errors.Is(err, http_error.INVALID_UPLOAD_CONTEXT_ERROR) {
 		c.JSON(400, dto.ErrorResponse{
 			Status:   "error",
 			Error:    err,
@@ -85,6 +131,23 @@ func ResponseFAILED[TMetaData any](c *gin.Context, metaData TMetaData, err error
 			MetaData: metaData,
 		})
 		return
+	} else if errors.Is(err, http_error.FILE_TOO_LARGE) || errors.Is(err, http_error.QUOTA_EXCEEDED) ||
+		errors.Is(err, http_error.ARCHIVE_TOO_LARGE) {
+		c.JSON(413, dto.ErrorResponse{
+			Status:   "error",
+			Error:    err,
+			Message:  err.Error(),
+			MetaData: metaData,
+		})
+		return
+	} else if errors.Is(err, http_error.UPLOAD_EXPIRED) {
+		c.JSON(410, dto.ErrorResponse{
+			Status:   "error",
+			Error:    err,
+			Message:  err.Error(),
+			MetaData: metaData,
+		})
+		return
 	} else {
 		c.JSON(405, dto.ErrorResponse{
 			Status:   "error",
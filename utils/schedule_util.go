@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery calls job every interval in the background until ctx is done. A failed run is
+// logged and the schedule carries on.
+func RunEvery(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					log.Printf("[JOB][%s] ❌ %v", name, err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+#!/usr/bin/env python3
+
+def greet(name):
+    return f"hello {name}"
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XLSXWriter streams a single-sheet workbook row by row, so large exports never sit in
+// memory. Strings are written inline, numbers as numbers; Close must be called to finish
+// the file.
+type XLSXWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+}
+
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// NewXLSXWriter writes the workbook parts and opens the sheet for rows.
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		if err := writeZipPart(archive, part.name, part.body); err != nil {
+			return nil, err
+		}
+	}
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + xlsxEscape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := writeZipPart(archive, "xl/workbook.xml", workbook); err != nil {
+		return nil, err
+	}
+
+	part, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(part)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &XLSXWriter{archive: archive, sheet: sheet}, nil
+}
+
+// WriteRow appends a row. Numbers become numeric cells, times are written in RFC 3339,
+// and anything else is written as text.
+func (x *XLSXWriter) WriteRow(cells []interface{}) error {
+	x.sheet.WriteString("<row>")
+	for _, cell := range cells {
+		switch value := cell.(type) {
+		case float64:
+			x.sheet.WriteString("<c><v>" + strconv.FormatFloat(value, 'f', -1, 64) + "</v></c>")
+		case int:
+			x.sheet.WriteString("<c><v>" + strconv.Itoa(value) + "</v></c>")
+		case int64:
+			x.sheet.WriteString("<c><v>" + strconv.FormatInt(value, 10) + "</v></c>")
+		case time.Time:
+			x.writeText(value.Format(time.RFC3339))
+		case string:
+			x.writeText(value)
+		case nil:
+			x.sheet.WriteString("<c/>")
+		default:
+			x.writeText(fmt.Sprint(value))
+		}
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+// Flush pushes buffered rows to the underlying writer.
+func (x *XLSXWriter) Flush() error {
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Flush()
+}
+
+// Close ends the sheet and writes the zip directory.
+func (x *XLSXWriter) Close() error {
+	x.sheet.WriteString("</sheetData></worksheet>")
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+func (x *XLSXWriter) writeText(text string) {
+	x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">` + xlsxEscape(text) + "</t></is></c>")
+}
+
+func writeZipPart(archive *zip.Writer, name string, body string) error {
+	part, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, body)
+	return err
+}
+
+// xlsxEscape escapes text for XML and drops control characters XML cannot carry.
+func xlsxEscape(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, text)
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}